@@ -1,26 +1,86 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"log-service/config"
+	"log-service/internal/health"
 	"log-service/internal/routes"
+	"log-service/internal/tracing"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
 	_ = godotenv.Load()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "log-service")
+	if err != nil {
+		log.Fatal("Tracing setup error: ", err)
+	}
+
 	config.InitMongo()
 
-	r := routes.UserLogRouter()
+	healthTimeout, err := time.ParseDuration(os.Getenv("HEALTH_CHECK_TIMEOUT"))
+	if err != nil || healthTimeout <= 0 {
+		healthTimeout = 2 * time.Second
+	}
+	checker := health.NewChecker(healthTimeout, func(ctx context.Context) error {
+		return config.MongoClient.Ping(ctx, nil)
+	})
+
+	r := routes.UserLogRouter(checker)
 
 	port := os.Getenv("PORT")
 
+	shutdownTimeout, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+	shutdownDelay, err := time.ParseDuration(os.Getenv("SHUTDOWN_DELAY"))
+	if err != nil || shutdownDelay < 0 {
+		shutdownDelay = 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: ":" + port, Handler: r}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
 	fmt.Printf("🚀 Log Service running on port %s\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
 
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		log.Fatal(err)
+	}
+	stop()
+
+	fmt.Println("Shutting down, draining requests")
+	checker.Drain()
+	time.Sleep(shutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("HTTP server did not drain in time: ", err)
+	}
+	if err := config.MongoClient.Disconnect(shutdownCtx); err != nil {
+		log.Println("MongoDB disconnect error: ", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Println("Trace flush error: ", err)
+	}
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"log-service/internal/health"
+	"net/http"
+)
+
+// Healthz only reports that the process is serving requests; a MongoDB
+// outage must not get the service restarted.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": health.StatusOK,
+	})
+}
+
+// Readyz pings MongoDB through checker and answers 503 when it fails or the
+// service is draining. The probe is unauthenticated, so the cause is only
+// logged, never returned.
+func Readyz(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := health.StatusOK
+		draining := false
+		if err := checker.Check(r.Context()); err != nil {
+			status = health.StatusError
+			draining = errors.Is(err, health.ErrDraining)
+			if !draining {
+				log.Println("Readiness check mongo failed: ", err)
+			}
+		}
+
+		report := map[string]any{"status": status}
+		if draining {
+			report["draining"] = true
+		} else {
+			report["checks"] = map[string]map[string]string{"mongo": {"status": status}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if status != health.StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"log-service/internal/health"
+)
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name   string
+		ping   func(ctx context.Context) error
+		drain  bool
+		status int
+		body   string
+	}{
+		{
+			name:   "mongo up",
+			ping:   func(ctx context.Context) error { return nil },
+			status: http.StatusOK,
+			body:   `{"checks":{"mongo":{"status":"ok"}},"status":"ok"}`,
+		},
+		{
+			name:   "mongo down",
+			ping:   func(ctx context.Context) error { return errors.New("server selection error: mongo-0.internal:27017") },
+			status: http.StatusServiceUnavailable,
+			body:   `{"checks":{"mongo":{"status":"error"}},"status":"error"}`,
+		},
+		{
+			name: "mongo hangs",
+			ping: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			status: http.StatusServiceUnavailable,
+			body:   `{"checks":{"mongo":{"status":"error"}},"status":"error"}`,
+		},
+		{
+			name: "draining",
+			ping: func(ctx context.Context) error {
+				t.Error("MongoDB pinged while draining")
+				return nil
+			},
+			drain:  true,
+			status: http.StatusServiceUnavailable,
+			body:   `{"draining":true,"status":"error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(10*time.Millisecond, tt.ping)
+			if tt.drain {
+				checker.Drain()
+			}
+
+			w := httptest.NewRecorder()
+			Readyz(checker)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+			// The cause of a failure is logged, not shown to the caller.
+			if got := strings.TrimSpace(w.Body.String()); got != tt.body {
+				t.Errorf("body %s, want %s", got, tt.body)
+			}
+		})
+	}
+}
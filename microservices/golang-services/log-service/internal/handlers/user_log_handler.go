@@ -17,13 +17,16 @@ func CreateLog(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid payload", http.StatusBadRequest)
 		return
 	}
-	go func() {
-		_, err := config.LogCollection.InsertOne(context.Background(), logData)
-		if err != nil {
-			http.Error(w, "Failed to insert log", http.StatusInternalServerError)
-			return
-		}
-	}()
+
+	// The insert finishes before responding, so a 201 means the log is
+	// stored and a shutdown waits for it instead of losing it.
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, err := config.LogCollection.InsertOne(ctx, logData); err != nil {
+		http.Error(w, "Failed to insert log", http.StatusInternalServerError)
+		return
+	}
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -32,7 +35,7 @@ func CreateLog(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetLogs(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	cursor, err := config.LogCollection.Find(ctx, bson.M{})
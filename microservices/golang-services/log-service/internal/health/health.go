@@ -0,0 +1,48 @@
+// Package health runs the MongoDB check behind the readiness endpoint. It
+// runs on every probe, so the service reports ready as soon as MongoDB
+// recovers without having to restart.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+var ErrDraining = errors.New("shutting down")
+
+type Checker struct {
+	timeout  time.Duration
+	ping     func(ctx context.Context) error
+	draining atomic.Bool
+}
+
+// NewChecker returns a Checker that gives ping at most timeout. ping must
+// return once its ctx is done.
+func NewChecker(timeout time.Duration, ping func(ctx context.Context) error) *Checker {
+	return &Checker{timeout: timeout, ping: ping}
+}
+
+// Drain makes every later check fail, so load balancers stop sending new
+// requests while the in-flight ones finish.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check returns ErrDraining once Drain was called, and otherwise the result
+// of pinging MongoDB.
+func (c *Checker) Check(ctx context.Context) error {
+	if c.draining.Load() {
+		return ErrDraining
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.ping(ctx)
+}
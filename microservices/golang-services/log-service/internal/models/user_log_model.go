@@ -13,5 +13,6 @@ type Log struct {
 	User      *User                  `json:"user"`
 	IPAddress string                 `json:"ip_address"`
 	SessionID *string                `json:"session_id"`
+	RequestID string                 `json:"request_id,omitempty"`
 	Details   map[string]interface{} `json:"details"`
 }
@@ -1,15 +1,24 @@
 package routes
 
 import (
+	"net/http"
+
 	"log-service/internal/handlers"
+	"log-service/internal/health"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
-func UserLogRouter() *mux.Router {
+func UserLogRouter(checker *health.Checker) *mux.Router {
 	r := mux.NewRouter()
+	r.Use(otelmux.Middleware("log-service", otelmux.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+	})))
 	r.HandleFunc("/user/log", handlers.CreateLog).Methods("POST")
 	r.HandleFunc("/user/logs", handlers.GetLogs).Methods("GET")
+	r.HandleFunc("/healthz", handlers.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", handlers.Readyz(checker)).Methods("GET")
 
 	return r
 }
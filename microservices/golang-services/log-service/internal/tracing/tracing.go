@@ -0,0 +1,59 @@
+// Package tracing configures the OpenTelemetry tracer provider and the W3C
+// trace context propagator, so audit events sent by product-service are
+// recorded in the trace of the request that caused them.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/contrib/exporters/autoexport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ShutdownFunc flushes spans that have not been exported yet.
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup installs the global tracer provider and propagator. The exporter is
+// configured by the standard OTEL_* variables, e.g. OTEL_TRACES_EXPORTER
+// (otlp, console or none) and OTEL_EXPORTER_OTLP_ENDPOINT; when
+// OTEL_TRACES_EXPORTER is unset trace context is propagated but no spans are
+// recorded.
+func Setup(ctx context.Context, serviceName string) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	noop := func(context.Context) error { return nil }
+	if os.Getenv("OTEL_TRACES_EXPORTER") == "" {
+		return noop, nil
+	}
+	exporter, err := autoexport.NewSpanExporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if autoexport.IsNoneSpanExporter(exporter) {
+		return noop, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
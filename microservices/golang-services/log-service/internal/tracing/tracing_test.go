@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		exporter     string
+		wantErr      bool
+		wantProvider bool
+	}{
+		{exporter: ""},
+		{exporter: "none"},
+		{exporter: "console", wantProvider: true},
+		{exporter: "zipkin-v1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", tt.exporter)
+			original := otel.GetTracerProvider()
+			t.Cleanup(func() {
+				if otel.GetTracerProvider() != original {
+					otel.SetTracerProvider(original)
+				}
+			})
+
+			shutdown, err := Setup(context.Background(), "log-service")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Setup succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer shutdown(context.Background())
+
+			_, installed := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			if installed != tt.wantProvider {
+				t.Errorf("SDK tracer provider installed = %v, want %v", installed, tt.wantProvider)
+			}
+			if fields := otel.GetTextMapPropagator().Fields(); !slices.Contains(fields, "traceparent") {
+				t.Errorf("propagator fields %v, want traceparent", fields)
+			}
+		})
+	}
+}
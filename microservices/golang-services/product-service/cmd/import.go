@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"product-service/config"
+	"product-service/internal/audit"
+	"product-service/internal/logging"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+	"time"
+)
+
+// importProducts implements the "import" subcommand:
+//
+//	go run ./cmd import -file products.csv [-dry-run] [-upsert] [-actor email]
+//
+// It prints the per-row report as JSON and exits non-zero when any row failed.
+// Configuration is loaded as for the server, whose flags it also accepts.
+func importProducts(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	filePath := fs.String("file", "", "path to the CSV file to import")
+	dryRun := fs.Bool("dry-run", false, "validate the file without writing anything")
+	upsert := fs.Bool("upsert", false, "update products whose SKU already exists")
+	actor := fs.String("actor", "cli", "actor recorded on product revisions")
+	cfg := loadConfig(fs, args)
+
+	if *filePath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// stdout carries the report, so logs go to stderr.
+	logger := logging.New(os.Stderr, cfg.Logging.Format, cfg.Logging.Level)
+	slog.SetDefault(logger)
+
+	file, err := os.Open(*filePath)
+	if err != nil {
+		fatal(logger, "Failed to open import file", "file", *filePath, "error", err)
+	}
+	defer file.Close()
+
+	helpers.InitValidator()
+
+	// The imported products are audited like any other change; the events
+	// still buffered are flushed once the import is done.
+	auditLog := audit.NewHTTPLogger(cfg.Events.LogServiceURL, &http.Client{Timeout: 5 * time.Second}, audit.DefaultOptions(), logger)
+	auditCtx, stopAudit := context.WithCancel(context.Background())
+	auditDone := make(chan struct{})
+	go func() {
+		auditLog.Start(auditCtx)
+		close(auditDone)
+	}()
+
+	gormConfig := config.NewGormPostgres(cfg.Database)
+	productRepo := repository.NewProductRepository(gormConfig)
+	productSvc := service.NewProductService(productRepo, auditLog)
+	changeRequestSvc := service.NewProductChangeRequestService(repository.NewProductChangeRequestRepository(gormConfig), productSvc, auditLog)
+	importSvc := service.NewProductImportService(productSvc, changeRequestSvc, cfg.Products.ReviewMode)
+
+	ctx := audit.WithActor(context.Background(), audit.Actor{ID: *actor})
+	report, err := importSvc.Import(ctx, file, models.ImportOptions{
+		DryRun: *dryRun,
+		Upsert: *upsert,
+	}, *actor)
+	stopAudit()
+	<-auditDone
+	if err != nil {
+		fatal(logger, "Invalid CSV file", "error", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fatal(logger, "Failed to write report", "error", err)
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
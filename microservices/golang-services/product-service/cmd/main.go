@@ -1,35 +1,109 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
 	"product-service/config"
+	"product-service/internal/audit"
+	"product-service/internal/docs"
+	"product-service/internal/graph"
 	"product-service/internal/handlers"
+	"product-service/internal/health"
+	"product-service/internal/jobs"
+	"product-service/internal/logging"
+	"product-service/internal/metrics"
+	"product-service/internal/middleware"
 	"product-service/internal/repository"
 	"product-service/internal/routes"
+	"product-service/internal/rpc"
+	"product-service/internal/scheduler"
 	"product-service/internal/service"
+	"product-service/internal/tracing"
+	"product-service/proto/productpb"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
 )
 
+const serviceName = "product-service"
+
 func main() {
-	server()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			importProducts(os.Args[2:])
+			return
+		case "migrate":
+			migrateCommand(os.Args[2:])
+			return
+		}
+	}
+
+	server(os.Args[1:])
 }
 
-func server() {
-	err := godotenv.Load(".env")
+func server(args []string) {
+	flags := flag.NewFlagSet(serviceName, flag.ExitOnError)
+	printConfig := flags.Bool("print-config", false, "print the effective configuration, secrets redacted, and exit")
+	cfg := loadConfig(flags, args)
+	if *printConfig {
+		cfg.Print(os.Stdout)
+		return
+	}
+
+	logger := logging.New(os.Stdout, cfg.Logging.Format, cfg.Logging.Level)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName, cfg.Tracing.Exporter)
 	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+		fatal(logger, "Failed to set up tracing", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers get their own context: they are stopped only after
+	// the servers have drained, so work queued by in-flight requests is kept.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	startWorker := func(start func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			start(workerCtx)
+		}()
 	}
 
-	redisClient := config.InitRedis()
-	if redisClient == nil {
-		log.Fatal("Failed to connect to Redis")
+	redisClient := config.NewRedis(cfg.Redis)
+	redisClient.AddHook(metrics.RedisHook{})
+	if err := redisotel.InstrumentTracing(redisClient); err != nil {
+		fatal(logger, "Failed to instrument Redis", "error", err)
 	}
 
-	g := gin.Default()
-	g.Use(gin.Recovery())
+	g := gin.New()
+	g.Use(otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+	g.Use(middleware.RequestID(), middleware.RequestLogger(logger), middleware.Metrics(), gin.Recovery(), middleware.ReadSession())
 
 	g.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
@@ -39,24 +113,265 @@ func server() {
 		AllowCredentials: true,
 	}))
 
-	gormConfig := config.NewGormPostgres()
+	if cfg.OpenAPI.Validation {
+		validator, err := middleware.OpenAPIValidator(docs.OpenAPI, middleware.OpenAPIOptions{
+			ValidateResponses: cfg.OpenAPI.ValidateResponses,
+		})
+		if err != nil {
+			fatal(logger, "Failed to load OpenAPI spec", "error", err)
+		}
+		g.Use(validator)
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := autoMigrate(cfg.Database, logger); err != nil {
+			fatal(logger, "Failed to apply migrations", "error", err)
+		}
+	}
+
+	gormConfig := config.NewGormPostgres(cfg.Database)
 	if gormConfig == nil {
-		log.Fatal("Failed to initialize database connection")
+		fatal(logger, "Failed to initialize database connection")
 	}
 
 	db := gormConfig.GetConnection()
 	if db == nil {
-		log.Fatal("Database connection is nil")
+		fatal(logger, "Database connection is nil")
+	}
+	if err := gormConfig.Use(metrics.GormPlugin{}); err != nil {
+		fatal(logger, "Failed to register database metrics", "error", err)
+	}
+	if err := gormConfig.Use(tracing.GormPlugin{}); err != nil {
+		fatal(logger, "Failed to instrument database", "error", err)
+	}
+	startWorker(gormConfig.MonitorReplicas)
+
+	if err := checkDrift(db, logger, cfg.Database.AutoMigrate); err != nil {
+		fatal(logger, "Schema does not match the models", "error", err)
 	}
 
-	productGroup := g.Group("/products")
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal(logger, "Failed to get database handle", "error", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB, "product"); err != nil {
+		fatal(logger, "Failed to register database pool metrics", "error", err)
+	}
+
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Register("postgres", sqlDB.PingContext)
+	checker.Register("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	if cfg.Health.CheckAuth {
+		checker.Register("auth", health.HTTPCheck(&http.Client{}, cfg.Auth.AccessURL))
+	}
+
+	authenticator := middleware.NewAuthenticator(cfg.Auth.AccessURL, redisClient)
+
+	auditLog := audit.NewHTTPLogger(cfg.Events.LogServiceURL, &http.Client{Timeout: 5 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)}, audit.DefaultOptions(), logger)
+	startWorker(auditLog.Start)
+
 	productRepo := repository.NewProductRepository(gormConfig)
-	productSvc := service.NewProductService(productRepo)
-	uploadDir := "./uploads/products"
-	productHdl := handlers.NewproductHandler(productSvc, uploadDir)
-	productRouter := routes.NewProductRouter(productGroup, productHdl)
-	productRouter.Mount()
-	g.Static("/uploads", "./uploads")
-
-	g.Run(":8080")
+	productSvc := service.NewProductService(productRepo, auditLog)
+	changeRequestRepo := repository.NewProductChangeRequestRepository(gormConfig)
+	changeRequestSvc := service.NewProductChangeRequestService(changeRequestRepo, productSvc, auditLog)
+	importSvc := service.NewProductImportService(productSvc, changeRequestSvc, cfg.Products.ReviewMode)
+
+	jobOpts := jobs.DefaultOptions()
+	jobOpts.Concurrency = cfg.Jobs.Concurrency
+	jobOpts.MaxAttempts = cfg.Jobs.MaxAttempts
+	jobQueue := jobs.NewRedisQueue(redisClient, jobOpts, logger)
+	exportSvc := service.NewProductExportService(productRepo)
+	jobs.RegisterProductJobs(jobQueue, productSvc, importSvc, exportSvc, cfg.Storage, logger)
+	startWorker(jobQueue.Start)
+
+	productHdl := handlers.NewproductHandler(productSvc, changeRequestSvc, jobQueue, cfg.Storage, cfg.Products.ReviewMode, logger)
+
+	revisionRepo := repository.NewProductRevisionRepository(gormConfig)
+	revisionSvc := service.NewProductRevisionService(revisionRepo, productSvc, changeRequestSvc, cfg.Products.ReviewMode)
+	revisionHdl := handlers.NewProductRevisionHandler(revisionSvc, logger)
+
+	importHdl := handlers.NewProductImportHandler(importSvc, jobQueue)
+
+	exportHdl := handlers.NewProductExportHandler(exportSvc, jobQueue, logger)
+
+	changeRequestHdl := handlers.NewProductChangeRequestHandler(changeRequestSvc, cfg.Storage, logger)
+
+	jobHdl := handlers.NewJobHandler(jobQueue, cfg.Storage, logger)
+
+	webhookRepo := repository.NewWebhookRepository(gormConfig)
+	webhookSvc := service.NewWebhookService(webhookRepo, &http.Client{Timeout: 10 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)})
+	webhookHdl := handlers.NewWebhookHandler(webhookSvc, logger)
+
+	graphQLHdl := handlers.NewGraphQLHandler(graph.NewExecutor(productSvc, revisionSvc))
+
+	routes.Mount(g, routes.Options{
+		Auth:      middleware.AuthMiddleware(authenticator),
+		UploadDir: cfg.Storage.UploadDir,
+	}, routes.Handlers{
+		Product:       productHdl,
+		Revision:      revisionHdl,
+		Import:        importHdl,
+		Export:        exportHdl,
+		ChangeRequest: changeRequestHdl,
+		Job:           jobHdl,
+		Webhook:       webhookHdl,
+		GraphQL:       graphQLHdl,
+		Docs:          handlers.NewDocsHandler(),
+		Health:        handlers.NewHealthHandler(checker, logger),
+	})
+
+	if cfg.Products.TrashRetentionDays > 0 {
+		retention := time.Duration(cfg.Products.TrashRetentionDays) * 24 * time.Hour
+		// The lease outlives the interval so the leader keeps it between runs.
+		trashLock := scheduler.NewRedisLeaderLock(redisClient, "product-service:trash-purger:leader", 2*time.Hour)
+		trashPurger := scheduler.NewTrashPurger(productSvc, trashLock, retention, time.Hour, cfg.Storage, logger)
+		startWorker(trashPurger.Start)
+	}
+
+	leaderLock := scheduler.NewRedisLeaderLock(redisClient, "product-service:publish-scheduler:leader", 2*time.Minute)
+	publishScheduler := scheduler.NewPublishScheduler(productSvc, leaderLock, time.Minute, logger)
+	startWorker(publishScheduler.Start)
+
+	outboxStream := cfg.Events.OutboxStream
+	outboxRepo := repository.NewOutboxRepository(gormConfig)
+	outboxLock := scheduler.NewRedisLeaderLock(redisClient, "product-service:outbox-relay:leader", 30*time.Second)
+	outboxRetention := time.Duration(cfg.Events.OutboxRetentionDays) * 24 * time.Hour
+	outboxRelay := scheduler.NewOutboxRelay(outboxRepo, redisClient, outboxStream, outboxLock, time.Second, outboxRetention, logger)
+	startWorker(outboxRelay.Start)
+
+	webhookDispatcher := scheduler.NewWebhookDispatcher(webhookSvc, redisClient, outboxStream, logger)
+	startWorker(webhookDispatcher.Start)
+	webhookDeliverer := scheduler.NewWebhookDeliverer(webhookSvc, 5*time.Second, logger)
+	startWorker(webhookDeliverer.Start)
+
+	grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.GRPCPort))
+	if err != nil {
+		fatal(logger, "Failed to listen on gRPC port", "port", cfg.Server.GRPCPort, "error", err)
+	}
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(rpc.AuthInterceptor(authenticator, logger), rpc.ReadSessionInterceptor()),
+	)
+	productpb.RegisterProductServiceServer(grpcServer, rpc.NewProductServer(productSvc, cfg.Products.ReviewMode))
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Error("gRPC server stopped", "error", err)
+		}
+	}()
+
+	metricsRouter := gin.New()
+	metricsRouter.Use(gin.Recovery())
+	routes.MountMetrics(metricsRouter, handlers.NewMetricsHandler())
+	metricsServer := &http.Server{Addr: ":" + strconv.Itoa(cfg.Server.MetricsPort), Handler: metricsRouter}
+
+	httpServer := &http.Server{Addr: ":" + strconv.Itoa(cfg.Server.Port), Handler: g}
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	go func() {
+		serveErr <- metricsServer.ListenAndServe()
+	}()
+	logger.Info("Product service started", "http_addr", httpServer.Addr, "grpc_port", cfg.Server.GRPCPort, "metrics_addr", metricsServer.Addr)
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		fatal(logger, "HTTP server stopped", "error", err)
+	}
+	// A second signal kills the process without waiting for the drain.
+	stop()
+
+	logger.Info("Shutting down", "timeout", cfg.Server.ShutdownTimeout)
+	checker.Drain()
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	var servers sync.WaitGroup
+	servers.Add(3)
+	go func() {
+		defer servers.Done()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("HTTP server did not drain in time", "error", err)
+		}
+	}()
+	go func() {
+		defer servers.Done()
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Metrics server did not drain in time", "error", err)
+		}
+	}()
+	go func() {
+		defer servers.Done()
+		stopGRPC(shutdownCtx, grpcServer)
+	}()
+	servers.Wait()
+
+	stopWorkers()
+	if !waitContext(shutdownCtx, &workers) {
+		logger.Error("Background workers did not stop in time")
+	}
+
+	if err := redisClient.Close(); err != nil {
+		logger.Error("Failed to close Redis client", "error", err)
+	}
+	if err := gormConfig.Close(); err != nil {
+		logger.Error("Failed to close database", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Failed to flush traces", "error", err)
+	}
+	logger.Info("Shutdown complete")
+}
+
+// stopGRPC lets in-flight RPCs finish, cutting them off when ctx expires.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
+// waitContext waits for wg and reports whether it finished before ctx.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// loadConfig loads the configuration from args, the dotenv file and the
+// environment, or exits listing every problem found.
+func loadConfig(flags *flag.FlagSet, args []string) *config.Config {
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return cfg
+}
+
+// fatal logs msg at error level and exits, like log.Fatal.
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"product-service/config"
+	"product-service/internal/logging"
+	"product-service/internal/migrator"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage:
+  migrate up                       apply every pending migration
+  migrate down [-steps n] [-all]   roll back the last n migrations (default 1), or all of them
+  migrate status                   list migrations and check the schema against the models
+  migrate force <version>          mark version as applied after fixing a failed migration
+  migrate create [-dir d] <name>   add an empty migration pair to d (default migration)
+
+Except for create, it also accepts the configuration flags of the server.`
+
+// migrateCommand implements the "migrate" subcommand, which applies the
+// migrations embedded in the binary to DATABASE_URL_CONFIGURATION.
+func migrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	action, args := args[0], args[1:]
+
+	switch action {
+	case "create":
+		createMigration(args)
+		return
+	case "up", "down", "status", "force":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s\n", action, migrateUsage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back (down only)")
+	all := fs.Bool("all", false, "roll back every migration (down only)")
+	cfg := loadConfig(fs, args)
+	if action == "force" && fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	// stdout carries the status report, so logs go to stderr.
+	logger := logging.New(os.Stderr, cfg.Logging.Format, cfg.Logging.Level)
+	slog.SetDefault(logger)
+
+	m, err := migrator.New(cfg.Database.URL, cfg.Database.MigrateLockTimeout)
+	if err != nil {
+		fatal(logger, "Failed to connect to database", "error", err)
+	}
+
+	code := 0
+	switch action {
+	case "up":
+		err = m.Up()
+	case "down":
+		if *all {
+			*steps = 0
+		}
+		err = m.Down(*steps)
+	case "force":
+		var version int
+		version, err = strconv.Atoi(fs.Arg(0))
+		if err == nil {
+			err = m.Force(version)
+		}
+	case "status":
+		code, err = printStatus(m, config.NewGormPostgres(cfg.Database).GetConnection())
+	}
+
+	if closeErr := m.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fatal(logger, "Migration failed", "command", action, "error", err)
+	}
+	if action != "status" {
+		logger.Info("Migration finished", "command", action)
+	}
+	os.Exit(code)
+}
+
+// printStatus writes the state of every migration and any schema drift to
+// stdout. The exit code is 1 when the schema is dirty or has drifted.
+func printStatus(m migrator.Migrator, db *gorm.DB) (int, error) {
+	status, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	state := "clean"
+	if status.Dirty {
+		state = "dirty, fix the failed migration and run migrate force"
+	}
+	fmt.Printf("Version: %d (%s)\n", status.Version, state)
+	for _, migration := range status.Migrations {
+		applied := "pending"
+		if migration.Applied {
+			applied = "applied"
+		}
+		fmt.Printf("  %-8s %06d_%s\n", applied, migration.Version, migration.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	drift, err := migrator.Drift(ctx, db, migrator.Models...)
+	if err != nil {
+		return 0, err
+	}
+	if len(drift) == 0 {
+		fmt.Println("Schema matches the models")
+	} else {
+		fmt.Println("Schema drift:")
+		for _, problem := range drift {
+			fmt.Println("  " + problem)
+		}
+	}
+
+	if status.Dirty || len(drift) > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func createMigration(args []string) {
+	fs := flag.NewFlagSet("migrate create", flag.ExitOnError)
+	dir := fs.String("dir", "migration", "directory holding the migrations")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	up, down, err := migrator.Create(*dir, fs.Arg(0))
+	if err != nil {
+		fatal(slog.Default(), "Failed to create migration", "error", err)
+	}
+	fmt.Println(up)
+	fmt.Println(down)
+}
+
+// autoMigrate applies pending migrations before the service starts serving.
+func autoMigrate(cfg config.DatabaseConfig, logger *slog.Logger) error {
+	m, err := migrator.New(cfg.URL, cfg.MigrateLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	before, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil {
+		return err
+	}
+	after, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	if after.Version != before.Version {
+		logger.Info("Applied migrations", "from", before.Version, "to", after.Version)
+	} else {
+		logger.Info("Database schema is up to date", "version", after.Version)
+	}
+	return nil
+}
+
+// checkDrift logs every difference between the GORM models and the schema,
+// which means the service runs against migrations it was not built for.
+// Without AUTO_MIGRATE that is only a warning, since during a rolling deploy
+// the schema can be a release ahead of or behind the binary. With it, the
+// binary has just applied its own migrations, so drift means they disagree
+// with the models and is returned as an error.
+func checkDrift(db *gorm.DB, logger *slog.Logger, autoMigrated bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	drift, err := migrator.Drift(ctx, db, migrator.Models...)
+	if err != nil {
+		logger.Warn("Failed to check schema drift", "error", err)
+		return nil
+	}
+	for _, problem := range drift {
+		logger.Warn("Schema drift", "problem", problem)
+	}
+	if autoMigrated && len(drift) > 0 {
+		return fmt.Errorf("%d differences between the schema and the models after migrating", len(drift))
+	}
+	return nil
+}
@@ -1,35 +1,166 @@
 package config
 
 import (
-	"os"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 type GormPostgres interface {
+	// GetConnection returns the primary, which takes every write.
 	GetConnection() *gorm.DB
+	// GetReadConnection returns a healthy read replica, or the primary when
+	// there is none or ctx has already written through it.
+	GetReadConnection(ctx context.Context) *gorm.DB
+	// Use registers plugin on the primary and every replica.
+	Use(plugin gorm.Plugin) error
+	// MonitorReplicas pings the replicas until ctx is done, taking the
+	// unreachable ones out of rotation until they answer again.
+	MonitorReplicas(ctx context.Context)
+	Close() error
 }
 
 type gormPostgresImpl struct {
-	master *gorm.DB
+	master        *gorm.DB
+	replicas      []*replica
+	next          atomic.Uint64
+	checkInterval time.Duration
 }
 
-func NewGormPostgres() GormPostgres {
-	return &gormPostgresImpl{
-		master: connect(),
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+func NewGormPostgres(cfg DatabaseConfig) GormPostgres {
+	g := &gormPostgresImpl{
+		master:        connect(cfg.URL),
+		checkInterval: cfg.ReplicaCheckInterval,
+	}
+	if err := ping(g.master); err != nil {
+		slog.Warn("PostgreSQL is unavailable, starting degraded", "error", err)
+	}
+	if err := g.master.Use(writeTracker{}); err != nil {
+		panic(err)
 	}
+
+	for i, dsn := range cfg.ReplicaURLs {
+		r := &replica{name: "replica-" + strconv.Itoa(i+1), db: connect(dsn)}
+		if err := ping(r.db); err != nil {
+			slog.Warn("Read replica is unavailable, reading from the primary", "replica", r.name, "error", err)
+		} else {
+			r.healthy.Store(true)
+		}
+		g.replicas = append(g.replicas, r)
+	}
+	return g
 }
 
-func connect() *gorm.DB {
-	dsn := os.Getenv("DATABASE_URL_CONFIGURATION")
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+func connect(dsn string) *gorm.DB {
+	// Connections are opened on first use, so only an invalid DSN fails
+	// here; an unreachable database leaves the service unready instead.
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableAutomaticPing: true})
 	if err != nil {
 		panic(err)
 	}
 	return db
 }
 
+func ping(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
 func (g *gormPostgresImpl) GetConnection() *gorm.DB {
 	return g.master
 }
+
+func (g *gormPostgresImpl) GetReadConnection(ctx context.Context) *gorm.DB {
+	if len(g.replicas) == 0 || hasWritten(ctx) {
+		return g.master
+	}
+
+	n := uint64(len(g.replicas))
+	start := g.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := g.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.db
+		}
+	}
+	return g.master
+}
+
+func (g *gormPostgresImpl) Use(plugin gorm.Plugin) error {
+	if err := g.master.Use(plugin); err != nil {
+		return err
+	}
+	for _, r := range g.replicas {
+		if err := r.db.Use(plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *gormPostgresImpl) MonitorReplicas(ctx context.Context) {
+	if len(g.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(g.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, r := range g.replicas {
+				r.check()
+			}
+		}
+	}
+}
+
+func (r *replica) check() {
+	err := ping(r.db)
+	healthy := err == nil
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		slog.Info("Read replica is back in rotation", "replica", r.name)
+	} else {
+		slog.Warn("Read replica is unavailable, reading from the primary", "replica", r.name, "error", err)
+	}
+}
+
+func (g *gormPostgresImpl) Close() error {
+	errs := []error{closeDB(g.master)}
+	for _, r := range g.replicas {
+		errs = append(errs, closeDB(r.db))
+	}
+	return errors.Join(errs...)
+}
+
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestPool returns a pool whose connections are never opened; only their
+// identity matters to GetReadConnection.
+func newTestPool(healthy ...bool) *gormPostgresImpl {
+	g := &gormPostgresImpl{master: &gorm.DB{}}
+	for i, ok := range healthy {
+		r := &replica{name: "replica-" + string(rune('1'+i)), db: &gorm.DB{}}
+		r.healthy.Store(ok)
+		g.replicas = append(g.replicas, r)
+	}
+	return g
+}
+
+// name tells which connection of g db is.
+func (g *gormPostgresImpl) name(db *gorm.DB) string {
+	if db == g.master {
+		return "primary"
+	}
+	for _, r := range g.replicas {
+		if db == r.db {
+			return r.name
+		}
+	}
+	return "unknown"
+}
+
+func TestGetReadConnection(t *testing.T) {
+	tests := []struct {
+		name    string
+		healthy []bool
+		want    []string
+	}{
+		{"no replicas", nil, []string{"primary", "primary"}},
+		{"round robin", []bool{true, true}, []string{"replica-2", "replica-1", "replica-2", "replica-1"}},
+		{"skips unhealthy replicas", []bool{true, false, true}, []string{"replica-3", "replica-3", "replica-1", "replica-3"}},
+		{"all replicas unhealthy", []bool{false, false}, []string{"primary", "primary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestPool(tt.healthy...)
+
+			var got []string
+			for range tt.want {
+				got = append(got, g.name(g.GetReadConnection(context.Background())))
+			}
+
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("reads went to %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetReadConnectionFollowsReplicaHealth(t *testing.T) {
+	g := newTestPool(true)
+	ctx := context.Background()
+
+	g.replicas[0].healthy.Store(false)
+	if got := g.name(g.GetReadConnection(ctx)); got != "primary" {
+		t.Errorf("read with the replica down went to %s, want primary", got)
+	}
+
+	g.replicas[0].healthy.Store(true)
+	if got := g.name(g.GetReadConnection(ctx)); got != "replica-1" {
+		t.Errorf("read with the replica back went to %s, want replica-1", got)
+	}
+}
+
+func TestReadSessionSticksToThePrimaryAfterAWrite(t *testing.T) {
+	g := newTestPool(true)
+	session := WithReadSession(context.Background())
+	other := WithReadSession(context.Background())
+
+	if got := g.name(g.GetReadConnection(session)); got != "replica-1" {
+		t.Fatalf("read before writing went to %s, want replica-1", got)
+	}
+
+	markWritten(&gorm.DB{Statement: &gorm.Statement{Context: session}})
+
+	if got := g.name(g.GetReadConnection(session)); got != "primary" {
+		t.Errorf("read after writing went to %s, want primary", got)
+	}
+	if got := g.name(g.GetReadConnection(other)); got != "replica-1" {
+		t.Errorf("read in another session went to %s, want replica-1", got)
+	}
+}
+
+func TestMarkWrittenWithoutSession(t *testing.T) {
+	ctx := context.Background()
+	markWritten(&gorm.DB{Statement: &gorm.Statement{Context: ctx}})
+	markWritten(&gorm.DB{Statement: &gorm.Statement{}})
+
+	if hasWritten(ctx) {
+		t.Error("a context without a read session reports a write")
+	}
+}
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestWriteTrackerMarksWritesOnly(t *testing.T) {
+	// DryRun builds statements without sending them, so no database is needed.
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=none"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(writeTracker{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func(db *gorm.DB) error
+		written bool
+	}{
+		{"query", func(db *gorm.DB) error { return db.Find(&[]widget{}).Error }, false},
+		{"create", func(db *gorm.DB) error { return db.Create(&widget{Name: "a"}).Error }, true},
+		{"update", func(db *gorm.DB) error { return db.Model(&widget{ID: 1}).Update("name", "b").Error }, true},
+		{"delete", func(db *gorm.DB) error { return db.Delete(&widget{ID: 1}).Error }, true},
+		{"raw", func(db *gorm.DB) error { return db.Exec("UPDATE widgets SET name = 'c'").Error }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithReadSession(context.Background())
+			if err := tt.run(db.WithContext(ctx)); err != nil {
+				t.Fatal(err)
+			}
+			if got := hasWritten(ctx); got != tt.written {
+				t.Errorf("hasWritten = %v, want %v", got, tt.written)
+			}
+		})
+	}
+}
+
+func TestReplicaCheckTakesUnreachableReplicaOutOfRotation(t *testing.T) {
+	// Nothing listens on port 1, so the ping is refused straight away.
+	r := &replica{name: "replica-1", db: connect("host=127.0.0.1 port=1 user=none dbname=none sslmode=disable connect_timeout=1")}
+	r.healthy.Store(true)
+	t.Cleanup(func() { closeDB(r.db) })
+
+	r.check()
+
+	if r.healthy.Load() {
+		t.Error("unreachable replica is still in rotation")
+	}
+}
@@ -2,29 +2,27 @@ package config
 
 import (
 	"context"
-	"os"
-	"sync"
+	"log/slog"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-var (
-	Client *redis.Client
-	once   sync.Once
-)
-
-func InitRedis() *redis.Client {
-	once.Do(func() {
-		redisAddr := os.Getenv("REDIS_HOST") + ":" + os.Getenv("REDIS_PORT")
-		Client = redis.NewClient(&redis.Options{
-			Addr:     redisAddr,
-			Password: os.Getenv("REDIS_PASSWORD"),
-			DB:       0,
-		})
-
-		if err := Client.Ping(context.Background()).Err(); err != nil {
-			panic("Failed to connect to Redis: " + err.Error())
-		}
+// NewRedis returns a client for cfg. The client reconnects on its own, so an
+// unreachable Redis only leaves the service unready until it comes back.
+func NewRedis(cfg RedisConfig) *redis.Client {
+	redisAddr := cfg.Host + ":" + strconv.Itoa(cfg.Port)
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: cfg.Password,
+		DB:       0,
 	})
-	return Client
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.Warn("Redis is unavailable, starting degraded", "addr", redisAddr, "error", err)
+	}
+	return client
 }
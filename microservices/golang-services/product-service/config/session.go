@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type sessionKey struct{}
+
+// WithReadSession returns a context whose reads through GetReadConnection
+// go to the primary once anything has been written with it, so a request
+// always sees its own changes even when the replicas lag behind.
+func WithReadSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, new(atomic.Bool))
+}
+
+func hasWritten(ctx context.Context) bool {
+	written, ok := ctx.Value(sessionKey{}).(*atomic.Bool)
+	return ok && written.Load()
+}
+
+// writeTracker marks the read session of every statement that may write to
+// the primary, including the ones inside transactions.
+type writeTracker struct{}
+
+func (writeTracker) Name() string {
+	return "read_session"
+}
+
+func (writeTracker) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("read_session:create", markWritten),
+		cb.Update().Before("gorm:update").Register("read_session:update", markWritten),
+		cb.Delete().Before("gorm:delete").Register("read_session:delete", markWritten),
+		cb.Raw().Before("gorm:raw").Register("read_session:raw", markWritten),
+	)
+}
+
+func markWritten(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	if written, ok := db.Statement.Context.Value(sessionKey{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
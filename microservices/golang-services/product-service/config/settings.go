@@ -0,0 +1,423 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds every setting of product-service. Load fills it from, in
+// increasing order of precedence: defaults, the dotenv file, the environment
+// and command-line flags.
+type Config struct {
+	Server   ServerConfig
+	Database DatabaseConfig
+	Redis    RedisConfig
+	Auth     AuthConfig
+	Storage  StorageConfig
+	Logging  LoggingConfig
+	Tracing  TracingConfig
+	OpenAPI  OpenAPIConfig
+	Health   HealthConfig
+	Products ProductsConfig
+	Jobs     JobsConfig
+	Events   EventsConfig
+}
+
+type ServerConfig struct {
+	Port            int
+	GRPCPort        int
+	MetricsPort     int
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+}
+
+type DatabaseConfig struct {
+	URL                  string
+	ReplicaURLs          []string
+	ReplicaCheckInterval time.Duration
+	AutoMigrate          bool
+	MigrateLockTimeout   time.Duration
+}
+
+type RedisConfig struct {
+	Host     string
+	Port     int
+	Password string
+}
+
+type AuthConfig struct {
+	AccessURL string
+}
+
+type StorageConfig struct {
+	UploadDir string
+	S3Bucket  string
+	S3Folder  string
+}
+
+// ProductUploadDir is where product images are kept when they are not
+// stored in S3. It is served under /uploads/products.
+func (s StorageConfig) ProductUploadDir() string {
+	return s.UploadDir + "/products"
+}
+
+type LoggingConfig struct {
+	Format string
+	Level  string
+}
+
+type TracingConfig struct {
+	Exporter string
+}
+
+type OpenAPIConfig struct {
+	Validation        bool
+	ValidateResponses bool
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration
+	CheckAuth    bool
+}
+
+type ProductsConfig struct {
+	ReviewMode         bool
+	TrashRetentionDays int
+}
+
+type JobsConfig struct {
+	Concurrency int
+	MaxAttempts int
+}
+
+type EventsConfig struct {
+	OutboxStream        string
+	OutboxRetentionDays int
+	LogServiceURL       string
+}
+
+// Default returns the configuration used for every setting left unset.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			GRPCPort:        9090,
+			MetricsPort:     9464,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			ReplicaCheckInterval: 5 * time.Second,
+			MigrateLockTimeout:   time.Minute,
+		},
+		Redis:   RedisConfig{Host: "localhost", Port: 6379},
+		Storage: StorageConfig{UploadDir: "./uploads"},
+		Logging: LoggingConfig{Format: "text", Level: "info"},
+		Tracing: TracingConfig{Exporter: "none"},
+		Health:  HealthConfig{CheckTimeout: 2 * time.Second},
+		Products: ProductsConfig{
+			TrashRetentionDays: 30,
+		},
+		Jobs: JobsConfig{
+			Concurrency: 4,
+			MaxAttempts: 3,
+		},
+		Events: EventsConfig{
+			OutboxStream:        "product-service:events",
+			OutboxRetentionDays: 7,
+		},
+	}
+}
+
+// setting binds one environment variable, and the flag derived from its
+// name, to a field of Config.
+type setting struct {
+	key    string
+	usage  string
+	secret bool
+	value  flag.Value
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "SERVER_PORT", usage: "HTTP port", value: (*intValue)(&c.Server.Port)},
+		{key: "GRPC_PORT", usage: "gRPC port", value: (*intValue)(&c.Server.GRPCPort)},
+		{key: "METRICS_PORT", usage: "port serving /metrics, kept apart from SERVER_PORT", value: (*intValue)(&c.Server.MetricsPort)},
+		{key: "SHUTDOWN_DELAY", usage: "time to keep serving with /readyz failing before draining", value: (*durationValue)(&c.Server.ShutdownDelay)},
+		{key: "SHUTDOWN_TIMEOUT", usage: "time allowed to drain requests and stop workers", value: (*durationValue)(&c.Server.ShutdownTimeout)},
+		{key: "DATABASE_URL_CONFIGURATION", usage: "PostgreSQL DSN", secret: true, value: (*stringValue)(&c.Database.URL)},
+		{key: "DATABASE_REPLICA_URLS", usage: "comma-separated PostgreSQL DSNs of read replicas", secret: true, value: (*listValue)(&c.Database.ReplicaURLs)},
+		{key: "DATABASE_REPLICA_CHECK_INTERVAL", usage: "time between read replica health checks", value: (*durationValue)(&c.Database.ReplicaCheckInterval)},
+		{key: "AUTO_MIGRATE", usage: "apply pending migrations on startup", value: (*boolValue)(&c.Database.AutoMigrate)},
+		{key: "MIGRATE_LOCK_TIMEOUT", usage: "time to wait for another replica's migration to finish", value: (*durationValue)(&c.Database.MigrateLockTimeout)},
+		{key: "REDIS_HOST", usage: "Redis host", value: (*stringValue)(&c.Redis.Host)},
+		{key: "REDIS_PORT", usage: "Redis port", value: (*intValue)(&c.Redis.Port)},
+		{key: "REDIS_PASSWORD", usage: "Redis password", secret: true, value: (*stringValue)(&c.Redis.Password)},
+		{key: "USER_AUTH_ACCESS_URL", usage: "auth service endpoint resolving bearer tokens", value: (*stringValue)(&c.Auth.AccessURL)},
+		{key: "UPLOAD_DIR", usage: "directory for local uploads, served under /uploads", value: (*stringValue)(&c.Storage.UploadDir)},
+		{key: "AWS_S3_BUCKET", usage: "S3 bucket for product images", value: (*stringValue)(&c.Storage.S3Bucket)},
+		{key: "AWS_S3_FOLDER", usage: "key prefix of product images in the bucket", value: (*stringValue)(&c.Storage.S3Folder)},
+		{key: "LOG_FORMAT", usage: "log format: json or text", value: (*stringValue)(&c.Logging.Format)},
+		{key: "LOG_LEVEL", usage: "log level: debug, info, warn or error", value: (*stringValue)(&c.Logging.Level)},
+		{key: "OTEL_TRACES_EXPORTER", usage: "trace exporter: otlp, console or none", value: (*stringValue)(&c.Tracing.Exporter)},
+		{key: "OPENAPI_VALIDATION", usage: "validate requests against the OpenAPI spec", value: (*boolValue)(&c.OpenAPI.Validation)},
+		{key: "OPENAPI_VALIDATE_RESPONSES", usage: "also validate responses (tests and staging only)", value: (*boolValue)(&c.OpenAPI.ValidateResponses)},
+		{key: "HEALTH_CHECK_TIMEOUT", usage: "time allowed for each readiness check", value: (*durationValue)(&c.Health.CheckTimeout)},
+		{key: "READINESS_CHECK_AUTH", usage: "include the auth service in readiness checks", value: (*boolValue)(&c.Health.CheckAuth)},
+		{key: "PRODUCT_REVIEW_MODE", usage: "route product updates through change requests", value: (*boolValue)(&c.Products.ReviewMode)},
+		{key: "TRASH_RETENTION_DAYS", usage: "days before soft-deleted products are purged, 0 disables", value: (*intValue)(&c.Products.TrashRetentionDays)},
+		{key: "JOB_WORKER_CONCURRENCY", usage: "background job workers", value: (*intValue)(&c.Jobs.Concurrency)},
+		{key: "JOB_MAX_ATTEMPTS", usage: "attempts before a job is dead-lettered", value: (*intValue)(&c.Jobs.MaxAttempts)},
+		{key: "OUTBOX_STREAM", usage: "Redis stream product events are published to", value: (*stringValue)(&c.Events.OutboxStream)},
+		{key: "OUTBOX_RETENTION_DAYS", usage: "days published outbox events are kept, 0 keeps them forever", value: (*intValue)(&c.Events.OutboxRetentionDays)},
+		{key: "LOG_SERVICE_URL", usage: "log-service endpoint for audit events, empty disables", value: (*stringValue)(&c.Events.LogServiceURL)},
+	}
+}
+
+// flagName turns SERVER_PORT into server-port.
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// Load registers a flag for every setting, plus --config-file, on flags,
+// parses args and returns the validated configuration. Callers may define
+// their own flags before calling Load.
+//
+// The dotenv file (.env unless --config-file or CONFIG_FILE says otherwise)
+// is loaded into the environment without overriding variables that are
+// already set, so libraries reading the environment directly, such as the
+// AWS and OpenTelemetry SDKs, see it too. A missing default file is ignored.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	configFile := flags.String("config-file", "", "dotenv file to load (default .env, or env CONFIG_FILE)")
+	for _, s := range settings {
+		// The backquoted key names the flag's argument in -help output.
+		usage := fmt.Sprintf("%s (env `%s`)", s.usage, s.key)
+		if _, ok := s.value.(*boolValue); ok {
+			usage = fmt.Sprintf("%s (env %s)", s.usage, s.key)
+		}
+		flags.Var(s.value, flagName(s.key), usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	file := *configFile
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	explicit := file != ""
+	if !explicit {
+		file = ".env"
+	}
+	if err := godotenv.Load(file); err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("loading %s: %w", file, err)
+	}
+
+	// Flags take precedence, so the environment only fills the rest.
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var problems []string
+	for _, s := range settings {
+		if set[flagName(s.key)] {
+			continue
+		}
+		// A variable set to "" clears the setting, overriding its default.
+		if raw, ok := os.LookupEnv(s.key); ok {
+			if err := s.value.Set(raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", s.key, err))
+			}
+		}
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// ValidationError lists every invalid setting found while loading.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	validPort := func(port int) bool { return port > 0 && port <= 65535 }
+	check(validPort(c.Server.Port), "SERVER_PORT", "must be between 1 and 65535")
+	check(validPort(c.Server.GRPCPort), "GRPC_PORT", "must be between 1 and 65535")
+	check(c.Server.Port != c.Server.GRPCPort, "GRPC_PORT", "must differ from SERVER_PORT")
+	check(validPort(c.Server.MetricsPort), "METRICS_PORT", "must be between 1 and 65535")
+	check(c.Server.MetricsPort != c.Server.Port && c.Server.MetricsPort != c.Server.GRPCPort, "METRICS_PORT", "must differ from SERVER_PORT and GRPC_PORT")
+	check(c.Server.ShutdownDelay >= 0, "SHUTDOWN_DELAY", "must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT", "must be positive")
+
+	check(c.Database.URL != "", "DATABASE_URL_CONFIGURATION", "is required")
+	check(c.Database.ReplicaCheckInterval > 0, "DATABASE_REPLICA_CHECK_INTERVAL", "must be positive")
+	check(c.Database.MigrateLockTimeout > 0, "MIGRATE_LOCK_TIMEOUT", "must be positive")
+	check(c.Redis.Host != "", "REDIS_HOST", "is required")
+	check(validPort(c.Redis.Port), "REDIS_PORT", "must be between 1 and 65535")
+
+	check(c.Auth.AccessURL == "" || validURL(c.Auth.AccessURL), "USER_AUTH_ACCESS_URL", "must be an http or https URL")
+	check(c.Auth.AccessURL != "" || !c.Health.CheckAuth, "USER_AUTH_ACCESS_URL", "is required when READINESS_CHECK_AUTH is enabled")
+	check(c.Events.LogServiceURL == "" || validURL(c.Events.LogServiceURL), "LOG_SERVICE_URL", "must be an http or https URL")
+
+	check(c.Storage.UploadDir != "", "UPLOAD_DIR", "is required")
+
+	check(oneOf(c.Logging.Format, "json", "text"), "LOG_FORMAT", "must be one of: json, text")
+	check(oneOf(c.Logging.Level, "debug", "info", "warn", "error"), "LOG_LEVEL", "must be one of: debug, info, warn, error")
+	check(oneOf(c.Tracing.Exporter, "otlp", "console", "stdout", "none"), "OTEL_TRACES_EXPORTER", "must be one of: otlp, console, none")
+	check(!c.OpenAPI.ValidateResponses || c.OpenAPI.Validation, "OPENAPI_VALIDATE_RESPONSES", "requires OPENAPI_VALIDATION")
+
+	check(c.Health.CheckTimeout > 0, "HEALTH_CHECK_TIMEOUT", "must be positive")
+	check(c.Products.TrashRetentionDays >= 0, "TRASH_RETENTION_DAYS", "must not be negative")
+	check(c.Jobs.Concurrency > 0, "JOB_WORKER_CONCURRENCY", "must be positive")
+	check(c.Jobs.MaxAttempts > 0, "JOB_MAX_ATTEMPTS", "must be positive")
+	check(c.Events.OutboxStream != "", "OUTBOX_STREAM", "is required")
+	check(c.Events.OutboxRetentionDays >= 0, "OUTBOX_RETENTION_DAYS", "must not be negative")
+
+	return problems
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes every setting as KEY=value, with secrets redacted, in the
+// format accepted by --config-file.
+func (c *Config) Print(w io.Writer) {
+	for _, s := range c.settings() {
+		value := s.value.String()
+		if list, ok := s.value.(*listValue); ok && s.secret {
+			redacted := make([]string, len(*list))
+			for i, item := range *list {
+				redacted[i] = redact(item)
+			}
+			value = strings.Join(redacted, ",")
+		} else if s.secret && value != "" {
+			value = redact(value)
+		}
+		fmt.Fprintf(w, "%s=%s\n", s.key, value)
+	}
+}
+
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// redact hides passwords inside DSNs and URLs, keeping the rest readable,
+// and hides any other secret entirely.
+func redact(value string) string {
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+			return u.String()
+		}
+	}
+	if dsnPassword.MatchString(value) {
+		return dsnPassword.ReplaceAllString(value, "${1}*****")
+	}
+	return "*****"
+}
+
+// The setting types below accept "" as their zero value, so an empty
+// variable or flag clears a setting instead of failing to parse.
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+// listValue holds comma-separated items; blank items are dropped.
+type listValue []string
+
+func (v *listValue) Set(s string) error {
+	*v = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v = append(*v, item)
+		}
+	}
+	return nil
+}
+func (v *listValue) String() string { return strings.Join(*v, ",") }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	if s == "" {
+		*v = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v = intValue(n)
+	return nil
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	if s == "" {
+		*v = false
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", s)
+	}
+	*v = boolValue(b)
+	return nil
+}
+func (v *boolValue) String() string   { return strconv.FormatBool(bool(*v)) }
+func (v *boolValue) IsBoolFlag() bool { return true }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	if s == "" {
+		*v = 0
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 30s or 1m", s)
+	}
+	*v = durationValue(d)
+	return nil
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
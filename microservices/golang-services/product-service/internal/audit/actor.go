@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+
+	"product-service/internal/logging"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Actor is who an audited change is attributed to. The HTTP and gRPC auth
+// layers attach it to the request context once the caller is known, and
+// jobs restore the one that queued them.
+type Actor struct {
+	ID        string `json:"id"`
+	Email     string `json:"email,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, or the zero Actor for
+// work no user started, such as the schedulers.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// attribute fills in the actor, request ID and span carried by ctx, keeping
+// any value the event already sets.
+func (e *Event) attribute(ctx context.Context) {
+	actor := ActorFromContext(ctx)
+
+	if e.User == nil && actor.ID != "" {
+		e.User = &User{ID: actor.ID}
+		if actor.Email != "" {
+			e.User.Email = &actor.Email
+		}
+	}
+	if e.IPAddress == "" {
+		e.IPAddress = actor.IPAddress
+	}
+	if e.Details == nil {
+		e.Details = map[string]interface{}{}
+	}
+	if _, ok := e.Details["user_agent"]; !ok && actor.UserAgent != "" {
+		e.Details["user_agent"] = actor.UserAgent
+	}
+	if e.RequestID == "" {
+		e.RequestID = logging.RequestID(ctx)
+	}
+	if !e.spanContext.IsValid() {
+		e.spanContext = trace.SpanContextFromContext(ctx)
+	}
+}
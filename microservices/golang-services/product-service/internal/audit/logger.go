@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	EventProductCreated       = "product.created"
+	EventProductUpdated       = "product.updated"
+	EventProductStatusChanged = "product.status_changed"
+	EventProductDeleted       = "product.deleted"
+	EventProductRestored      = "product.restored"
+	EventProductPurged        = "product.purged"
+
+	EventChangeRequestSubmitted = "product.change_request_submitted"
+	EventChangeRequestApproved  = "product.change_request_approved"
+	EventChangeRequestRejected  = "product.change_request_rejected"
+)
+
+// User and Event mirror the log-service models.Log payload accepted by
+// POST /user/log.
+type User struct {
+	ID    string  `json:"id"`
+	Email *string `json:"email,omitempty"`
+}
+
+type Event struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Event     string                 `json:"event"`
+	User      *User                  `json:"user"`
+	IPAddress string                 `json:"ip_address"`
+	SessionID *string                `json:"session_id"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Details   map[string]interface{} `json:"details"`
+
+	// spanContext is the span the event was logged in. The delivery is
+	// traced as its child, so log-service joins the originating trace.
+	spanContext trace.SpanContext
+}
+
+type Logger interface {
+	// Log queues event for delivery and never blocks. The actor, request
+	// ID and span carried by ctx are recorded with it. Events are dropped,
+	// with a warning, when the buffer is full.
+	Log(ctx context.Context, event Event)
+	// Start delivers events until ctx is cancelled, then tries to deliver
+	// the events still buffered within Options.FlushTimeout.
+	Start(ctx context.Context)
+}
+
+type Options struct {
+	BufferSize   int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	FlushTimeout time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		BufferSize:   1000,
+		MaxAttempts:  5,
+		BaseBackoff:  500 * time.Millisecond,
+		FlushTimeout: 5 * time.Second,
+	}
+}
+
+type httpLogger struct {
+	url    string
+	client *http.Client
+	events chan Event
+	opts   Options
+	logger *slog.Logger
+}
+
+// NewHTTPLogger sends audit events to the log-service endpoint at url from a
+// background worker started with Start. An empty url disables auditing.
+func NewHTTPLogger(url string, client *http.Client, opts Options, logger *slog.Logger) Logger {
+	if url == "" {
+		return noopLogger{}
+	}
+
+	return &httpLogger{
+		url:    url,
+		client: client,
+		events: make(chan Event, opts.BufferSize),
+		opts:   opts,
+		logger: logger.With("component", "audit_logger"),
+	}
+}
+
+func (l *httpLogger) Log(ctx context.Context, event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	event.attribute(ctx)
+
+	select {
+	case l.events <- event:
+	default:
+		l.logger.Warn("Audit buffer full, dropping event", "event", event.Event)
+	}
+}
+
+func (l *httpLogger) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			l.flush(ctx)
+			return
+		case event := <-l.events:
+			l.deliver(ctx, event)
+		}
+	}
+}
+
+// flush delivers the buffered events on shutdown, so the requests served
+// while draining are still audited.
+func (l *httpLogger) flush(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.opts.FlushTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case event := <-l.events:
+			l.deliver(ctx, event)
+		default:
+			return
+		}
+
+		if ctx.Err() != nil {
+			if dropped := len(l.events); dropped > 0 {
+				l.logger.ErrorContext(ctx, "Audit flush timed out, dropping events", "count", dropped)
+			}
+			return
+		}
+	}
+}
+
+// deliver retries transport errors and 5xx or 429 responses with exponential
+// backoff. Other responses mean log-service rejected the event, so retrying
+// would not help.
+func (l *httpLogger) deliver(ctx context.Context, event Event) {
+	if event.spanContext.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, event.spanContext)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		l.logger.ErrorContext(ctx, "Failed to encode audit event", "event", event.Event, "error", err)
+		return
+	}
+
+	backoff := l.opts.BaseBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := l.send(ctx, body)
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt >= l.opts.MaxAttempts {
+			l.logger.ErrorContext(ctx, "Dropping audit event", "event", event.Event, "attempts", attempt, "error", err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (l *httpLogger) send(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("log service responded with status %d", resp.StatusCode)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Log(ctx context.Context, event Event) {}
+
+func (noopLogger) Start(ctx context.Context) {}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"product-service/internal/logging"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogAttributesEventToContext(t *testing.T) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+	ctx = logging.WithRequestID(ctx, "req-1")
+	ctx = WithActor(ctx, Actor{ID: "42", Email: "editor@example.com", IPAddress: "10.0.0.1", UserAgent: "curl/8"})
+
+	logger := NewHTTPLogger("http://log-service", http.DefaultClient, DefaultOptions(), logging.Discard()).(*httpLogger)
+	logger.Log(ctx, Event{Event: EventProductDeleted, Details: map[string]interface{}{"product_id": 7}})
+
+	event := <-logger.events
+	if event.User == nil || event.User.ID != "42" || event.User.Email == nil || *event.User.Email != "editor@example.com" {
+		t.Errorf("User = %+v, want 42 / editor@example.com", event.User)
+	}
+	if event.IPAddress != "10.0.0.1" {
+		t.Errorf("IPAddress = %q, want 10.0.0.1", event.IPAddress)
+	}
+	if event.RequestID != "req-1" {
+		t.Errorf("RequestID = %q, want req-1", event.RequestID)
+	}
+	if event.Details["user_agent"] != "curl/8" {
+		t.Errorf("user_agent = %v, want curl/8", event.Details["user_agent"])
+	}
+	if event.spanContext.TraceID() != spanContext.TraceID() {
+		t.Errorf("trace ID = %s, want %s", event.spanContext.TraceID(), spanContext.TraceID())
+	}
+	if event.Timestamp.IsZero() {
+		t.Error("Timestamp not set")
+	}
+}
+
+func TestLogWithoutActorLeavesUserEmpty(t *testing.T) {
+	logger := NewHTTPLogger("http://log-service", http.DefaultClient, DefaultOptions(), logging.Discard()).(*httpLogger)
+	logger.Log(context.Background(), Event{Event: EventProductPurged})
+
+	event := <-logger.events
+	if event.User != nil || event.IPAddress != "" || event.RequestID != "" {
+		t.Errorf("event = %+v, want no attribution", event)
+	}
+}
+
+func TestDeliveryJoinsTheLoggingTrace(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	type received struct {
+		traceparent string
+		body        map[string]interface{}
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- received{r.Header.Get("traceparent"), body}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	logger := NewHTTPLogger(server.URL, client, DefaultOptions(), logging.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go logger.Start(ctx)
+
+	traceID := trace.TraceID{0xab, 0xcd}
+	requestCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	logger.Log(logging.WithRequestID(requestCtx, "req-1"), Event{Event: EventProductCreated})
+
+	select {
+	case r := <-requests:
+		if want := "00-" + traceID.String() + "-"; len(r.traceparent) < len(want) || r.traceparent[:len(want)] != want {
+			t.Errorf("traceparent = %q, want trace %s", r.traceparent, traceID)
+		}
+		if r.body["request_id"] != "req-1" {
+			t.Errorf("request_id = %v, want req-1", r.body["request_id"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
@@ -0,0 +1,26 @@
+// Package docs embeds the OpenAPI description of the HTTP API and the
+// Swagger UI that renders it.
+package docs
+
+import (
+	_ "embed"
+	"io/fs"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// OpenAPI is the OpenAPI 3 document served at /openapi.json. Keep it in step
+// with the routes mounted by routes.Mount; the routes tests fail when a
+// route is missing from it.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// SwaggerInitializer replaces the bundled Swagger UI initializer so the UI
+// loads OpenAPI instead of the demo petstore spec.
+//
+//go:embed swagger-initializer.js
+var SwaggerInitializer []byte
+
+// SwaggerUI holds the Swagger UI distribution files.
+var SwaggerUI fs.FS = swaggerFiles.FS
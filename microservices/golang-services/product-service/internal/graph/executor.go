@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+
+	"product-service/internal/middleware"
+	"product-service/internal/service"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+type Executor interface {
+	Exec(ctx context.Context, claims middleware.Claims, query, operationName string, variables map[string]interface{}) *graphql.Response
+}
+
+type executorImpl struct {
+	schema    *graphql.Schema
+	products  service.ProductService
+	revisions service.ProductRevisionService
+}
+
+// NewExecutor parses the product schema and resolves it against the product
+// and revision services.
+func NewExecutor(products service.ProductService, revisions service.ProductRevisionService) Executor {
+	schema := graphql.MustParseSchema(schemaSDL, &queryResolver{}, graphql.MaxDepth(8))
+	return &executorImpl{schema: schema, products: products, revisions: revisions}
+}
+
+type requestKey struct{}
+
+// request carries what resolvers need for one query: the caller's claims,
+// the services and the per-request loaders.
+type request struct {
+	claims   middleware.Claims
+	products service.ProductService
+	loaders  *loaders
+}
+
+func (e *executorImpl) Exec(ctx context.Context, claims middleware.Claims, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	ctx = context.WithValue(ctx, requestKey{}, &request{
+		claims:   claims,
+		products: e.products,
+		loaders:  newLoaders(e.products, e.revisions),
+	})
+	return e.schema.Exec(ctx, query, operationName, variables)
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// viewAll mirrors GetAllProducts: without view_all_products only visible
+// products can be seen.
+func (r *request) viewAll() bool {
+	return middleware.HasAnyPermission(r.claims, "view_all_products")
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+const loaderWait = 2 * time.Millisecond
+
+type revisionKey struct {
+	ProductID uint
+	Limit     int
+}
+
+// loaders batch the lookups made while resolving one request, so a list of
+// products with nested revisions costs one query per level instead of one per
+// product. They are created per request and cache only within it.
+type loaders struct {
+	products  *dataloader.Loader[uint, *models.Product]
+	revisions *dataloader.Loader[revisionKey, []models.ProductRevision]
+}
+
+func newLoaders(products service.ProductService, revisions service.ProductRevisionService) *loaders {
+	return &loaders{
+		products: dataloader.NewBatchedLoader(func(ctx context.Context, ids []uint) []*dataloader.Result[*models.Product] {
+			results := make([]*dataloader.Result[*models.Product], len(ids))
+
+			found, err := products.GetByIDs(ctx, ids)
+			if err != nil {
+				for i := range results {
+					results[i] = &dataloader.Result[*models.Product]{Error: err}
+				}
+				return results
+			}
+
+			byID := map[uint]*models.Product{}
+			for i := range found {
+				byID[found[i].ID] = &found[i]
+			}
+			for i, id := range ids {
+				results[i] = &dataloader.Result[*models.Product]{Data: byID[id]}
+			}
+			return results
+		}, dataloader.WithWait[uint, *models.Product](loaderWait)),
+
+		revisions: dataloader.NewBatchedLoader(func(ctx context.Context, keys []revisionKey) []*dataloader.Result[[]models.ProductRevision] {
+			results := make([]*dataloader.Result[[]models.ProductRevision], len(keys))
+
+			// Keys asking for different limits are loaded with the largest
+			// one and trimmed afterwards.
+			ids := make([]uint, 0, len(keys))
+			limit := 0
+			for _, key := range keys {
+				ids = append(ids, key.ProductID)
+				if key.Limit > limit {
+					limit = key.Limit
+				}
+			}
+
+			byProduct, err := revisions.GetLatestByProductIDs(ctx, ids, limit)
+			if err != nil {
+				for i := range results {
+					results[i] = &dataloader.Result[[]models.ProductRevision]{Error: err}
+				}
+				return results
+			}
+
+			for i, key := range keys {
+				productRevisions := byProduct[key.ProductID]
+				if len(productRevisions) > key.Limit {
+					productRevisions = productRevisions[:key.Limit]
+				}
+				results[i] = &dataloader.Result[[]models.ProductRevision]{Data: productRevisions}
+			}
+			return results
+		}, dataloader.WithWait[revisionKey, []models.ProductRevision](loaderWait)),
+	}
+}
@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// countingServices serves ten products, each with three revisions, and
+// records every lookup the resolvers make.
+type countingServices struct {
+	service.ProductService
+	service.ProductRevisionService
+
+	mu             sync.Mutex
+	listCalls      int
+	byIDsCalls     [][]uint
+	revisionsCalls [][]uint
+}
+
+func (s *countingServices) products() []models.Product {
+	products := make([]models.Product, 10)
+	for i := range products {
+		products[i] = models.Product{ID: uint(i + 1), Name: "Product", Status: models.StatusActive}
+	}
+	return products
+}
+
+func (s *countingServices) GetAll(ctx context.Context, limit, offset int, search string, status *models.ProductStatus) ([]models.Product, int64, error) {
+	s.mu.Lock()
+	s.listCalls++
+	s.mu.Unlock()
+	return s.products(), 10, nil
+}
+
+func (s *countingServices) GetByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	s.mu.Lock()
+	s.byIDsCalls = append(s.byIDsCalls, append([]uint(nil), ids...))
+	s.mu.Unlock()
+
+	var found []models.Product
+	for _, p := range s.products() {
+		for _, id := range ids {
+			if p.ID == id {
+				found = append(found, p)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (s *countingServices) GetLatestByProductIDs(ctx context.Context, productIDs []uint, limit int) (map[uint][]models.ProductRevision, error) {
+	s.mu.Lock()
+	s.revisionsCalls = append(s.revisionsCalls, append([]uint(nil), productIDs...))
+	s.mu.Unlock()
+
+	byProduct := map[uint][]models.ProductRevision{}
+	for _, id := range productIDs {
+		for r := 0; r < 3 && r < limit; r++ {
+			byProduct[id] = append(byProduct[id], models.ProductRevision{ID: id*10 + uint(r), ProductID: id, Status: models.StatusDraft})
+		}
+	}
+	return byProduct, nil
+}
+
+var admin = middleware.Claims{Permissions: []string{"view_all_products"}}
+
+func exec(t *testing.T, svc *countingServices, query string) map[string]interface{} {
+	t.Helper()
+
+	resp := NewExecutor(svc, svc).Exec(context.Background(), admin, query, "", nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query failed: %v", resp.Errors)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return data
+}
+
+func sorted(ids []uint) []uint {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestNestedRevisionsAreLoadedInOneBatch(t *testing.T) {
+	svc := &countingServices{}
+	data := exec(t, svc, `{ products(perPage: 10) { items { id revisions(limit: 2) { id } } } }`)
+
+	items := data["products"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 10 {
+		t.Fatalf("got %d products, want 10", len(items))
+	}
+	for _, item := range items {
+		if revisions := item.(map[string]interface{})["revisions"].([]interface{}); len(revisions) != 2 {
+			t.Errorf("product %v has %d revisions, want 2", item.(map[string]interface{})["id"], len(revisions))
+		}
+	}
+
+	if svc.listCalls != 1 {
+		t.Errorf("listed products %d times, want 1", svc.listCalls)
+	}
+	if len(svc.revisionsCalls) != 1 || len(svc.revisionsCalls[0]) != 10 {
+		t.Errorf("revision lookups = %v, want one batch of 10 products", svc.revisionsCalls)
+	}
+}
+
+func TestProductLookupsAreLoadedInOneBatch(t *testing.T) {
+	svc := &countingServices{}
+	data := exec(t, svc, `{
+		a: product(id: "1") { id }
+		b: product(id: "2") { id }
+		c: product(id: "99") { id }
+		productsByIds(ids: ["3", "1", "4"]) { id }
+	}`)
+
+	if data["c"] != nil {
+		t.Errorf("missing product resolved to %v, want null", data["c"])
+	}
+	if len(svc.byIDsCalls) != 1 {
+		t.Fatalf("product lookups = %v, want one batch", svc.byIDsCalls)
+	}
+	if got, want := sorted(svc.byIDsCalls[0]), []uint{1, 2, 3, 4, 99}; !reflect.DeepEqual(got, want) {
+		t.Errorf("batched ids = %v, want %v (each id once)", got, want)
+	}
+}
@@ -0,0 +1,235 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	maxPerPage     = 100
+	maxBatchIDs    = 100
+	maxRevisionCap = 50
+)
+
+type queryResolver struct{}
+
+type productsArgs struct {
+	Search  *string
+	Status  *string
+	Page    int32
+	PerPage int32
+}
+
+func (q *queryResolver) Products(ctx context.Context, args productsArgs) (*productPageResolver, error) {
+	req := requestFrom(ctx)
+
+	page := int(args.Page)
+	if page < 1 {
+		page = 1
+	}
+	perPage := int(args.PerPage)
+	if perPage < 1 || perPage > maxPerPage {
+		return nil, fmt.Errorf("perPage must be between 1 and %d", maxPerPage)
+	}
+	offset := (page - 1) * perPage
+
+	search := ""
+	if args.Search != nil {
+		search = *args.Search
+	}
+
+	var products []models.Product
+	var total int64
+	var err error
+
+	if req.viewAll() {
+		var status *models.ProductStatus
+		if args.Status != nil {
+			statusVal := models.ProductStatus(strings.ToLower(*args.Status))
+			status = &statusVal
+		}
+		products, total, err = req.products.GetAll(ctx, perPage, offset, search, status)
+	} else {
+		products, total, err = req.products.GetByStatusActive(ctx, perPage, offset, search)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*productResolver, len(products))
+	for i := range products {
+		// Prime the loader so nested lookups of listed products are free.
+		req.loaders.products.Prime(ctx, products[i].ID, &products[i])
+		items[i] = &productResolver{&products[i]}
+	}
+
+	return &productPageResolver{
+		items:      items,
+		total:      int32(total),
+		page:       int32(page),
+		perPage:    int32(perPage),
+		totalPages: int32((total + int64(perPage) - 1) / int64(perPage)),
+	}, nil
+}
+
+func (q *queryResolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadProduct(ctx, id)
+}
+
+func (q *queryResolver) ProductsByIds(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*productResolver, error) {
+	if len(args.IDs) > maxBatchIDs {
+		return nil, fmt.Errorf("ids must contain at most %d item(s)", maxBatchIDs)
+	}
+
+	ids := make([]uint, len(args.IDs))
+	for i, rawID := range args.IDs {
+		id, err := parseID(rawID)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	req := requestFrom(ctx)
+
+	// Queue every id before waiting so they are fetched in one batch.
+	thunks := make([]func() (*models.Product, error), len(ids))
+	for i, id := range ids {
+		thunks[i] = req.loaders.products.Load(ctx, id)
+	}
+
+	results := make([]*productResolver, len(ids))
+	for i, thunk := range thunks {
+		product, err := visibleProduct(req, thunk)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = product
+	}
+	return results, nil
+}
+
+// loadProduct fetches a product through the request loader, hiding products
+// the caller may not see.
+func loadProduct(ctx context.Context, id uint) (*productResolver, error) {
+	req := requestFrom(ctx)
+	return visibleProduct(req, req.loaders.products.Load(ctx, id))
+}
+
+func visibleProduct(req *request, thunk func() (*models.Product, error)) (*productResolver, error) {
+	product, err := thunk()
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || (!req.viewAll() && !product.IsVisible(time.Now())) {
+		return nil, nil
+	}
+	return &productResolver{product}, nil
+}
+
+func parseID(id graphql.ID) (uint, error) {
+	parsed, err := strconv.ParseUint(string(id), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid product id %q", string(id))
+	}
+	return uint(parsed), nil
+}
+
+func toTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type productPageResolver struct {
+	items      []*productResolver
+	total      int32
+	page       int32
+	perPage    int32
+	totalPages int32
+}
+
+func (p *productPageResolver) Items() []*productResolver { return p.items }
+func (p *productPageResolver) Total() int32              { return p.total }
+func (p *productPageResolver) Page() int32               { return p.page }
+func (p *productPageResolver) PerPage() int32            { return p.perPage }
+func (p *productPageResolver) TotalPages() int32         { return p.totalPages }
+
+type productResolver struct {
+	p *models.Product
+}
+
+func (r *productResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(r.p.ID), 10))
+}
+
+func (r *productResolver) Sku() *string               { return nonEmpty(r.p.SKU) }
+func (r *productResolver) Name() string               { return r.p.Name }
+func (r *productResolver) Description() string        { return r.p.Description }
+func (r *productResolver) Price() float64             { return r.p.Price }
+func (r *productResolver) Quantity() int32            { return int32(r.p.Quantity) }
+func (r *productResolver) Status() string             { return strings.ToUpper(string(r.p.Status)) }
+func (r *productResolver) ImageUrl() *string          { return nonEmpty(r.p.ImageURL) }
+func (r *productResolver) PublishAt() *graphql.Time   { return toTime(r.p.PublishAt) }
+func (r *productResolver) UnpublishAt() *graphql.Time { return toTime(r.p.UnpublishAt) }
+func (r *productResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: r.p.CreatedAt} }
+func (r *productResolver) UpdatedAt() graphql.Time    { return graphql.Time{Time: r.p.UpdatedAt} }
+
+func (r *productResolver) Revisions(ctx context.Context, args struct{ Limit int32 }) (*[]*revisionResolver, error) {
+	req := requestFrom(ctx)
+	if !req.viewAll() {
+		return nil, fmt.Errorf("revisions require the view_all_products permission")
+	}
+
+	limit := int(args.Limit)
+	if limit < 1 || limit > maxRevisionCap {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxRevisionCap)
+	}
+
+	revisions, err := req.loaders.revisions.Load(ctx, revisionKey{ProductID: r.p.ID, Limit: limit})()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*revisionResolver, len(revisions))
+	for i := range revisions {
+		resolvers[i] = &revisionResolver{&revisions[i]}
+	}
+	return &resolvers, nil
+}
+
+type revisionResolver struct {
+	r *models.ProductRevision
+}
+
+func (r *revisionResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(r.r.ID), 10))
+}
+
+func (r *revisionResolver) Name() string            { return r.r.Name }
+func (r *revisionResolver) Description() string     { return r.r.Description }
+func (r *revisionResolver) Price() float64          { return r.r.Price }
+func (r *revisionResolver) Quantity() int32         { return int32(r.r.Quantity) }
+func (r *revisionResolver) Status() string          { return strings.ToUpper(string(r.r.Status)) }
+func (r *revisionResolver) ImageUrl() *string       { return nonEmpty(r.r.ImageURL) }
+func (r *revisionResolver) Actor() *string          { return nonEmpty(r.r.Actor) }
+func (r *revisionResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.r.CreatedAt} }
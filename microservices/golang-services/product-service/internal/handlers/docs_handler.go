@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"product-service/internal/docs"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DocsHandler interface {
+	OpenAPI(ctx *gin.Context)
+	UI(ctx *gin.Context)
+}
+
+type docsHandlerImpl struct {
+	fileServer http.Handler
+}
+
+func NewDocsHandler() *docsHandlerImpl {
+	return &docsHandlerImpl{http.FileServer(http.FS(docs.SwaggerUI))}
+}
+
+func (h *docsHandlerImpl) OpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docs.OpenAPI)
+}
+
+// UI serves the Swagger UI under /docs, pointed at the OpenAPI document.
+func (h *docsHandlerImpl) UI(c *gin.Context) {
+	file := strings.TrimPrefix(c.Param("filepath"), "/")
+	if file == "swagger-initializer.js" {
+		c.Data(http.StatusOK, "text/javascript; charset=utf-8", docs.SwaggerInitializer)
+		return
+	}
+
+	c.Request.URL.Path = "/" + file
+	h.fileServer.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"product-service/internal/graph"
+	"product-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler interface {
+	Query(ctx *gin.Context)
+}
+
+type graphQLHandlerImpl struct {
+	executor graph.Executor
+}
+
+func NewGraphQLHandler(executor graph.Executor) *graphQLHandlerImpl {
+	return &graphQLHandlerImpl{executor}
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query executes a GraphQL request. Responses use the standard GraphQL shape
+// rather than the REST envelope, so existing GraphQL clients work unchanged.
+func (h *graphQLHandlerImpl) Query(c *gin.Context) {
+	var req graphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": []gin.H{{"message": "Request body must be JSON with a query"}},
+		})
+		return
+	}
+
+	claims, _ := middleware.GetClaims(c)
+	resp := h.executor.Exec(c.Request.Context(), claims, req.Query, req.OperationName, req.Variables)
+
+	c.JSON(http.StatusOK, resp)
+}
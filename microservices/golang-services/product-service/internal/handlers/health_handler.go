@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"product-service/internal/health"
+	"product-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler interface {
+	Liveness(ctx *gin.Context)
+	Readiness(ctx *gin.Context)
+}
+
+type healthHandlerImpl struct {
+	checker *health.Checker
+	logger  *slog.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *slog.Logger) *healthHandlerImpl {
+	return &healthHandlerImpl{checker, logger}
+}
+
+// Liveness only reports that the process is serving requests; dependency
+// outages must not get a healthy but degraded instance restarted.
+func (h *healthHandlerImpl) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "OK", nil))
+}
+
+func (h *healthHandlerImpl) Readiness(c *gin.Context) {
+	ctx := c.Request.Context()
+	report := h.checker.Check(ctx)
+	for name, result := range report.Checks {
+		if result.Err != nil {
+			h.logger.WarnContext(ctx, "Readiness check failed", "check", name, "latency", result.Latency, "error", result.Err)
+		}
+	}
+	if !report.OK() {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse(http.StatusServiceUnavailable, "Service not ready", report))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Ready", report))
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"product-service/config"
+	"product-service/internal/jobs"
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler interface {
+	GetJob(ctx *gin.Context)
+	GetDeadJobs(ctx *gin.Context)
+	RetryJob(ctx *gin.Context)
+	DownloadJobFile(ctx *gin.Context)
+}
+
+type jobHandlerImpl struct {
+	queue   jobs.Queue
+	storage config.StorageConfig
+	logger  *slog.Logger
+}
+
+// NewJobHandler builds the job handler. Files produced by jobs are read
+// from the S3 bucket in storage.
+func NewJobHandler(queue jobs.Queue, storage config.StorageConfig, logger *slog.Logger) *jobHandlerImpl {
+	return &jobHandlerImpl{queue, storage, logger}
+}
+
+func (h *jobHandlerImpl) GetJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	job, err := h.queue.Get(ctx, c.Param("id"))
+	if err != nil {
+		if err.Error() == "job not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get job", err.Error()))
+		return
+	}
+
+	if job.CreatedBy != middleware.GetUserEmail(c) && !middleware.HasPermission(c, "manage_jobs") {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job not found", nil))
+		return
+	}
+
+	job.Payload = nil
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Successfully Get Job", job))
+}
+
+func (h *jobHandlerImpl) GetDeadJobs(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	pagination, limit, offset := helpers.GetPagination(c, 15)
+
+	deadJobs, total, err := h.queue.DeadLetters(ctx, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get dead jobs", err.Error()))
+		return
+	}
+
+	for i := range deadJobs {
+		deadJobs[i].Payload = nil
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Status:      http.StatusOK,
+		Message:     "Successfully Get Dead Jobs",
+		Data:        deadJobs,
+		Total:       total,
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		TotalPages:  totalPages,
+		Error:       false,
+	})
+}
+
+func (h *jobHandlerImpl) RetryJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	job, err := h.queue.Retry(ctx, c.Param("id"))
+	if err != nil {
+		if err.Error() == "job not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job not found", nil))
+			return
+		}
+
+		if err.Error() == "job is not dead" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Only dead jobs can be retried", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to retry job", err.Error()))
+		return
+	}
+
+	job.Payload = nil
+	c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Job queued for retry", job))
+}
+
+// DownloadJobFile serves the file a job produced, such as an export, to the
+// user who created the job.
+func (h *jobHandlerImpl) DownloadJobFile(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	job, err := h.queue.Get(ctx, c.Param("id"))
+	if err != nil {
+		if err.Error() == "job not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get job", err.Error()))
+		return
+	}
+
+	if job.CreatedBy != middleware.GetUserEmail(c) && !middleware.HasPermission(c, "manage_jobs") {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job not found", nil))
+		return
+	}
+
+	var result jobs.ExportProductsResult
+	if job.Type != jobs.JobTypeExportProducts || job.Status != models.JobSucceeded ||
+		json.Unmarshal(job.Result, &result) != nil || result.ObjectKey == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job has no file", nil))
+		return
+	}
+
+	uploader, err := helpers.NewS3Uploader(h.storage.S3Bucket, h.storage.S3Folder, h.logger, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to initialize S3 client", err.Error()))
+		return
+	}
+
+	body, size, err := uploader.OpenObject(ctx, result.ObjectKey)
+	if err != nil {
+		if err.Error() == "object not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Job file has expired", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get job file", err.Error()))
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, size, result.ContentType, body, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, result.FileName),
+	})
+}
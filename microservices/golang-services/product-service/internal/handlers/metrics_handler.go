@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"product-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsHandler interface {
+	Metrics(ctx *gin.Context)
+}
+
+type metricsHandlerImpl struct {
+	handler http.Handler
+}
+
+func NewMetricsHandler() *metricsHandlerImpl {
+	return &metricsHandlerImpl{metrics.Handler()}
+}
+
+func (h *metricsHandlerImpl) Metrics(c *gin.Context) {
+	h.handler.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"product-service/config"
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductChangeRequestHandler interface {
+	GetChangeRequests(ctx *gin.Context)
+	GetChangeRequest(ctx *gin.Context)
+	ApproveChangeRequest(ctx *gin.Context)
+	RejectChangeRequest(ctx *gin.Context)
+}
+
+type productChangeRequestHandlerImpl struct {
+	service service.ProductChangeRequestService
+	storage config.StorageConfig
+	logger  *slog.Logger
+}
+
+func NewProductChangeRequestHandler(service service.ProductChangeRequestService, storage config.StorageConfig, logger *slog.Logger) *productChangeRequestHandlerImpl {
+	return &productChangeRequestHandlerImpl{service, storage, logger}
+}
+
+func (h *productChangeRequestHandlerImpl) GetChangeRequests(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	pagination, limit, offset := helpers.GetPagination(c, 15)
+
+	var status *models.ChangeRequestStatus
+	if statusStr := c.Query("status"); statusStr != "" {
+		statusVal := models.ChangeRequestStatus(statusStr)
+		if statusVal != models.ChangeRequestPending && statusVal != models.ChangeRequestApproved && statusVal != models.ChangeRequestRejected {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid status filter", "Status must be pending, approved or rejected"))
+			return
+		}
+		status = &statusVal
+	}
+
+	var productID uint64
+	if productIDStr := c.Query("product_id"); productIDStr != "" {
+		var err error
+		productID, err = strconv.ParseUint(productIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+			return
+		}
+	}
+
+	requests, total, err := h.service.GetAll(ctx, limit, offset, status, uint(productID))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to get change requests", "error", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get change requests", err.Error()))
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Status:      http.StatusOK,
+		Message:     "Successfully Get Change Requests",
+		Data:        requests,
+		Total:       total,
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		TotalPages:  totalPages,
+		Error:       false,
+	})
+}
+
+func (h *productChangeRequestHandlerImpl) GetChangeRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid change request ID", "Change request ID must be a number"))
+		return
+	}
+
+	request, err := h.service.GetByID(ctx, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Change request not found", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Successfully Get Change Request", request))
+}
+
+func (h *productChangeRequestHandlerImpl) ApproveChangeRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid change request ID", "Change request ID must be a number"))
+		return
+	}
+
+	var input models.ApproveChangeRequestInput
+	if err := c.ShouldBind(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	request, product, replacedImageURL, err := h.service.Approve(ctx, uint(id), middleware.GetUserEmail(c), input.Comment)
+	if err != nil {
+		if err.Error() == "change request is not pending" {
+			c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Change request has already been reviewed", nil))
+			return
+		}
+
+		if err.Error() == "product changed since the request was submitted" {
+			c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Product has changed since the request was submitted", "Reject this request and submit the change again"))
+			return
+		}
+
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Change request or product not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to approve change request", err.Error()))
+		return
+	}
+
+	h.deleteImage(c, replacedImageURL)
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Change request approved successfully", gin.H{
+		"change_request": request,
+		"product":        product,
+	}))
+}
+
+func (h *productChangeRequestHandlerImpl) RejectChangeRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid change request ID", "Change request ID must be a number"))
+		return
+	}
+
+	var input models.RejectChangeRequestInput
+	if err := c.ShouldBind(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	request, discardedImageURL, err := h.service.Reject(ctx, uint(id), middleware.GetUserEmail(c), input.Comment)
+	if err != nil {
+		if err.Error() == "change request is not pending" {
+			c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Change request has already been reviewed", nil))
+			return
+		}
+
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Change request not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to reject change request", err.Error()))
+		return
+	}
+
+	h.deleteImage(c, discardedImageURL)
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Change request rejected successfully", request))
+}
+
+func (h *productChangeRequestHandlerImpl) deleteImage(c *gin.Context, imageURL string) {
+	if imageURL == "" {
+		return
+	}
+
+	s3Uploader, err := helpers.NewS3Uploader(
+		h.storage.S3Bucket,
+		h.storage.S3Folder,
+		h.logger,
+		nil,
+	)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to initialize S3 uploader", "error", err)
+		return
+	}
+
+	if err := s3Uploader.DeleteStoredFile(c.Request.Context(), imageURL, h.storage.ProductUploadDir()); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to delete image", "image_url", imageURL, "error", err)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"product-service/internal/jobs"
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductExportHandler interface {
+	ExportProducts(ctx *gin.Context)
+}
+
+type productExportHandlerImpl struct {
+	service service.ProductExportService
+	queue   jobs.Queue
+	logger  *slog.Logger
+}
+
+func NewProductExportHandler(service service.ProductExportService, queue jobs.Queue, logger *slog.Logger) *productExportHandlerImpl {
+	return &productExportHandlerImpl{service, queue, logger}
+}
+
+func (h *productExportHandlerImpl) ExportProducts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+	contentType, ok := service.ExportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid export format", "Format must be one of csv, ndjson, xlsx"))
+		return
+	}
+
+	search := c.DefaultQuery("search", "")
+	statusStr := c.Query("status")
+
+	var status *models.ProductStatus
+	if statusStr != "" {
+		statusVal := models.ProductStatus(statusStr)
+		if !statusVal.IsValid() {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid status filter", "Status must be one of draft, pending_review, active, inactive, archived"))
+			return
+		}
+		status = &statusVal
+	}
+
+	if c.Query("async") == "true" {
+		job, err := h.queue.Enqueue(ctx, jobs.JobTypeExportProducts, jobs.ExportProductsPayload{
+			Format: format,
+			Search: search,
+			Status: status,
+		}, middleware.GetUserEmail(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to queue export", err.Error()))
+			return
+		}
+
+		job.Payload = nil
+		c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Export queued", job))
+		return
+	}
+
+	fileName := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent once streaming starts, so failures can only
+	// be logged and the truncated body left for the client to detect.
+	if err := h.service.Export(ctx, c.Writer, format, search, status); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to export products", "format", format, "error", err)
+	}
+}
@@ -1,12 +1,18 @@
 package handlers
 
 import (
-	"log"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 
+	"product-service/config"
+	"product-service/internal/audit"
+	"product-service/internal/jobs"
+	"product-service/internal/metrics"
 	"product-service/internal/middleware"
 	"product-service/internal/models"
 	"product-service/internal/service"
@@ -21,38 +27,40 @@ type ProductHandler interface {
 	UpdateProductStatus(ctx *gin.Context)
 	DeleteProduct(ctx *gin.Context)
 	UpdateProduct(ctx *gin.Context)
+	PatchProduct(ctx *gin.Context)
+	BulkUpdateProducts(ctx *gin.Context)
+	BulkUpdateProductStatus(ctx *gin.Context)
+	ReprocessProductImages(ctx *gin.Context)
+	GetTrashedProducts(ctx *gin.Context)
+	RestoreProduct(ctx *gin.Context)
+	PurgeProduct(ctx *gin.Context)
 }
 
 type productHandlerImpl struct {
-	service   service.ProductService
-	uploadDir string
+	service        service.ProductService
+	changeRequests service.ProductChangeRequestService
+	queue          jobs.Queue
+	storage        config.StorageConfig
+	reviewMode     bool
+	logger         *slog.Logger
 }
 
-func NewproductHandler(service service.ProductService, uploadDir string) *productHandlerImpl {
+// NewproductHandler builds the product handler. With reviewMode enabled,
+// UpdateProduct submits change requests for approval instead of saving.
+func NewproductHandler(service service.ProductService, changeRequests service.ProductChangeRequestService, queue jobs.Queue, storage config.StorageConfig, reviewMode bool, logger *slog.Logger) *productHandlerImpl {
 	helpers.InitValidator()
-	return &productHandlerImpl{service, uploadDir}
+	return &productHandlerImpl{service, changeRequests, queue, storage, reviewMode, logger}
 }
 
 func (h *productHandlerImpl) GetAllProducts(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	claimsRaw, exists := c.Get("claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse(http.StatusUnauthorized, "Unauthorized", "Missing claims"))
-		return
-	}
-
-	claims, ok := claimsRaw.(struct {
-		middleware.UserClaims
-		Permissions []string
-	})
+	claims, ok := middleware.GetClaims(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse(http.StatusUnauthorized, "Unauthorized", "Invalid claims format"))
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse(http.StatusUnauthorized, "Unauthorized", "Missing claims"))
 		return
 	}
 
-	// log.Printf("User permissions: %v", claims.Permissions)
-
 	pageStr := c.DefaultQuery("page", "1")
 	search := c.DefaultQuery("search", "")
 	statusStr := c.Query("status")
@@ -70,11 +78,11 @@ func (h *productHandlerImpl) GetAllProducts(c *gin.Context) {
 		err      error
 	)
 
-	var status *int
+	var status *models.ProductStatus
 	if statusStr != "" {
-		statusVal, err := strconv.Atoi(statusStr)
-		if err != nil || (statusVal != 0 && statusVal != 1) {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid status filter", "Status must be 0 or 1"))
+		statusVal := models.ProductStatus(statusStr)
+		if !statusVal.IsValid() {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid status filter", "Status must be one of draft, pending_review, active, inactive, archived"))
 			return
 		}
 		status = &statusVal
@@ -90,7 +98,7 @@ func (h *productHandlerImpl) GetAllProducts(c *gin.Context) {
 	}
 
 	if err != nil {
-		log.Printf("Error getting products: %v", err)
+		h.logger.ErrorContext(ctx, "Failed to get products", "error", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get products", err.Error()))
 		return
 	}
@@ -118,26 +126,42 @@ func (h *productHandlerImpl) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	input.PublishAt = helpers.NilIfZeroTime(input.PublishAt)
+	input.UnpublishAt = helpers.NilIfZeroTime(input.UnpublishAt)
+	if validationErrors := models.ValidatePublishWindow(input.PublishAt, input.UnpublishAt); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
 	product := models.Product{
+		SKU:         input.SKU,
 		Name:        input.Name,
 		Description: input.Description,
 		Price:       input.Price,
 		Quantity:    input.Quantity,
-		Status:      1,
+		Status:      models.StatusActive,
+		PublishAt:   input.PublishAt,
+		UnpublishAt: input.UnpublishAt,
+	}
+
+	if input.Status != "" {
+		product.Status = models.ProductStatus(input.Status)
 	}
 
 	file, err := c.FormFile("image")
 	if err == nil {
 		s3Uploader, err := helpers.NewS3Uploader(
-			os.Getenv("AWS_S3_BUCKET"),
-			os.Getenv("AWS_S3_FOLDER"),
+			h.storage.S3Bucket,
+			h.storage.S3Folder,
+			h.logger,
+			metrics.RecordS3Upload,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to initialize S3 uploader", err.Error()))
 			return
 		}
 
-		imageURL, err := s3Uploader.UploadWithFallback(ctx, file, h.uploadDir)
+		imageURL, err := s3Uploader.UploadWithFallback(ctx, file, h.storage.ProductUploadDir())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to upload image", err.Error()))
 			return
@@ -147,6 +171,20 @@ func (h *productHandlerImpl) CreateProduct(c *gin.Context) {
 	}
 
 	if err := h.service.Create(ctx, &product); err != nil {
+		if err.Error() == "product sku already exists" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", map[string][]string{
+				"SKU": {"The SKU has already been taken."},
+			}))
+			return
+		}
+
+		if err.Error() == "product sku belongs to a trashed product" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", map[string][]string{
+				"SKU": {"The SKU belongs to a product in the trash; restore or purge it first."},
+			}))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to create product", err.Error()))
 		return
 	}
@@ -163,15 +201,33 @@ func (h *productHandlerImpl) UpdateProductStatus(c *gin.Context) {
 		return
 	}
 
-	product, err := h.service.GetByID(ctx, uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", err.Error()))
+	var input models.UpdateProductStatusInput
+	if err := c.ShouldBind(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
 		return
 	}
 
-	product.Status = 1 - product.Status
+	product, _, err := h.service.TransitionStatus(ctx, uint(id), models.ProductStatus(input.Status), middleware.GetUserEmail(c))
+	if err != nil {
+		var transitionErr *models.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, "Status transition not allowed", map[string][]string{
+				"Status": {"The Status cannot change from " + string(transitionErr.From) + " to " + string(transitionErr.To) + "."},
+			}))
+			return
+		}
+
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", nil))
+			return
+		}
+
+		if err.Error() == "product changed concurrently" {
+			c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Product was changed by another request", "Retry the status change"))
+			return
+		}
 
-	if err := h.service.Update(ctx, uint(id), product); err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update product status", err.Error()))
 		return
 	}
@@ -195,7 +251,14 @@ func (h *productHandlerImpl) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.service.GetByID(ctx, uint(id))
+	input.PublishAt = helpers.NilIfZeroTime(input.PublishAt)
+	input.UnpublishAt = helpers.NilIfZeroTime(input.UnpublishAt)
+	if validationErrors := models.ValidatePublishWindow(input.PublishAt, input.UnpublishAt); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	product, err := h.service.GetByIDForUpdate(ctx, uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", err.Error()))
 		return
@@ -205,21 +268,33 @@ func (h *productHandlerImpl) UpdateProduct(c *gin.Context) {
 	product.Description = input.Description
 	product.Price = input.Price
 	product.Quantity = input.Quantity
+	product.PublishAt = input.PublishAt
+	product.UnpublishAt = input.UnpublishAt
+
+	h.saveProductUpdate(c, product)
+}
+
+// saveProductUpdate uploads a replacement image when one is attached, then
+// either saves product or, in review mode, submits it as a change request.
+func (h *productHandlerImpl) saveProductUpdate(c *gin.Context, product *models.Product) {
+	ctx := c.Request.Context()
 
 	oldImageURL := product.ImageURL
 
 	file, err := c.FormFile("image")
 	if err == nil {
 		s3Uploader, err := helpers.NewS3Uploader(
-			os.Getenv("AWS_S3_BUCKET"),
-			os.Getenv("AWS_S3_FOLDER"),
+			h.storage.S3Bucket,
+			h.storage.S3Folder,
+			h.logger,
+			metrics.RecordS3Upload,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to initialize S3 uploader", err.Error()))
 			return
 		}
 
-		newImageURL, err := s3Uploader.UploadWithFallback(ctx, file, h.uploadDir)
+		newImageURL, err := s3Uploader.UploadWithFallback(ctx, file, h.storage.ProductUploadDir())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to upload image", err.Error()))
 			return
@@ -227,22 +302,38 @@ func (h *productHandlerImpl) UpdateProduct(c *gin.Context) {
 
 		product.ImageURL = newImageURL
 
-		if oldImageURL != "" {
+		if oldImageURL != "" && !h.reviewMode {
 			if helpers.IsS3URL(oldImageURL) {
 				if err := s3Uploader.DeleteFileFromS3(ctx, oldImageURL); err != nil {
-					log.Printf("Failed to delete old image from S3: %v", err)
+					h.logger.WarnContext(ctx, "Failed to delete old image from S3", "image_url", oldImageURL, "error", err)
 				}
 			} else {
 				oldFileName := helpers.GetFileNameFromURL(oldImageURL)
-				oldFilePath := filepath.Join(h.uploadDir, oldFileName)
+				oldFilePath := filepath.Join(h.storage.ProductUploadDir(), oldFileName)
 				if err := os.Remove(oldFilePath); err != nil && !os.IsNotExist(err) {
-					log.Printf("Failed to delete old local image file: %v", err)
+					h.logger.WarnContext(ctx, "Failed to delete old local image file", "path", oldFilePath, "error", err)
 				}
 			}
 		}
 	}
 
-	if err := h.service.Update(ctx, uint(id), product); err != nil {
+	if h.reviewMode {
+		changeRequest := models.NewProductChangeRequest(product, middleware.GetUserEmail(c))
+		if err := h.changeRequests.Submit(ctx, changeRequest); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to submit product change", err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Product change submitted for review", changeRequest))
+		return
+	}
+
+	if err := h.service.Update(ctx, product.ID, product, middleware.GetUserEmail(c)); err != nil {
+		if err.Error() == "product changed concurrently" {
+			c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Product was changed by another request", "Reload the product and apply the update again"))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update product", err.Error()))
 		return
 	}
@@ -250,6 +341,107 @@ func (h *productHandlerImpl) UpdateProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Product updated successfully", product))
 }
 
+func (h *productHandlerImpl) BulkUpdateProducts(c *gin.Context) {
+	var input models.BulkUpdateProductsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	if input.Patch.IsEmpty() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", map[string][]string{
+			"Patch": {"The Patch must change at least one of price, quantity_delta or status."},
+		}))
+		return
+	}
+
+	h.bulkUpdate(c, input.IDs, input.Filter, &input.Patch)
+}
+
+func (h *productHandlerImpl) BulkUpdateProductStatus(c *gin.Context) {
+	var input models.BulkUpdateStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	h.bulkUpdate(c, input.IDs, input.Filter, &models.BulkProductPatch{Status: &input.Status})
+}
+
+func (h *productHandlerImpl) bulkUpdate(c *gin.Context, ids []uint, filter *models.BulkProductFilter, patch *models.BulkProductPatch) {
+	ctx := c.Request.Context()
+
+	// Change requests cover one product each, so bulk edits cannot be reviewed.
+	if h.reviewMode {
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Bulk updates are disabled in review mode", "Update the products individually so each change is reviewed"))
+		return
+	}
+
+	if validationErrors := models.ValidateBulkSelection(ids, filter); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	if c.Query("async") == "true" {
+		job, err := h.queue.Enqueue(ctx, jobs.JobTypeBulkUpdateProducts, jobs.BulkUpdateProductsPayload{
+			IDs:    ids,
+			Filter: filter,
+			Patch:  *patch,
+			Actor:  middleware.GetUserEmail(c),
+			Origin: audit.ActorFromContext(ctx),
+		}, middleware.GetUserEmail(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to queue bulk update", err.Error()))
+			return
+		}
+
+		job.Payload = nil
+		c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Bulk update queued", job))
+		return
+	}
+
+	report, err := h.service.BulkUpdate(ctx, ids, filter, patch, middleware.GetUserEmail(c))
+	if err != nil {
+		if err.Error() == "bulk selection exceeds limit" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Too many products selected", "A bulk update can change at most 1000 products"))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to bulk update products", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Products updated successfully", report))
+}
+
+// ReprocessProductImages queues a job moving the images that fell back to
+// local storage to S3, for the given products or all of them.
+func (h *productHandlerImpl) ReprocessProductImages(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input models.ReprocessImagesInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	job, err := h.queue.Enqueue(ctx, jobs.JobTypeReprocessProductImages, jobs.ReprocessProductImagesPayload{
+		IDs:    input.IDs,
+		Actor:  middleware.GetUserEmail(c),
+		Origin: audit.ActorFromContext(ctx),
+	}, middleware.GetUserEmail(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to queue image reprocessing", err.Error()))
+		return
+	}
+
+	job.Payload = nil
+	c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Image reprocessing queued", job))
+}
+
 func (h *productHandlerImpl) DeleteProduct(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -277,3 +469,99 @@ func (h *productHandlerImpl) DeleteProduct(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Product deleted successfully", nil))
 }
+
+func (h *productHandlerImpl) GetTrashedProducts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	pagination, limit, offset := helpers.GetPagination(c, 15)
+	search := c.DefaultQuery("search", "")
+
+	products, total, err := h.service.GetTrashed(ctx, limit, offset, search)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to get trashed products", "error", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get trashed products", err.Error()))
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Status:      http.StatusOK,
+		Message:     "Successfully Get Trashed Products",
+		Data:        products,
+		Total:       total,
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		TotalPages:  totalPages,
+		Error:       false,
+	})
+}
+
+func (h *productHandlerImpl) RestoreProduct(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+		return
+	}
+
+	product, err := h.service.Restore(ctx, uint(id))
+	if err != nil {
+		if err.Error() == "product is not deleted" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Product is not deleted", nil))
+			return
+		}
+
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to restore product", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Product restored successfully", product))
+}
+
+func (h *productHandlerImpl) PurgeProduct(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+		return
+	}
+
+	product, err := h.service.Purge(ctx, uint(id))
+	if err != nil {
+		if err.Error() == "product is not deleted" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Product must be deleted before it can be purged", nil))
+			return
+		}
+
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to purge product", err.Error()))
+		return
+	}
+
+	if product.ImageURL != "" {
+		s3Uploader, err := helpers.NewS3Uploader(
+			h.storage.S3Bucket,
+			h.storage.S3Folder,
+			h.logger,
+			nil,
+		)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "Failed to initialize S3 uploader", "product_id", product.ID, "error", err)
+		} else if err := s3Uploader.DeleteStoredFile(ctx, product.ImageURL, h.storage.ProductUploadDir()); err != nil {
+			h.logger.WarnContext(ctx, "Failed to delete image of purged product", "product_id", product.ID, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Product purged successfully", nil))
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"product-service/config"
+	"product-service/internal/jobs"
+	"product-service/internal/logging"
+	"product-service/internal/models"
+	"product-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeChangeRequestService keeps the change requests submitted to it.
+type fakeChangeRequestService struct {
+	service.ProductChangeRequestService
+
+	submitted []*models.ProductChangeRequest
+}
+
+func (s *fakeChangeRequestService) Submit(ctx context.Context, request *models.ProductChangeRequest) error {
+	request.ID = uint(len(s.submitted) + 1)
+	request.Status = models.ChangeRequestPending
+	s.submitted = append(s.submitted, request)
+	return nil
+}
+
+func TestUpdateProductInReviewMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeProductService{product: &models.Product{ID: 1, Name: "Lamp", Description: "Brass", Price: 10, Quantity: 2, Status: models.StatusActive}}
+	changeRequests := &fakeChangeRequestService{}
+	router := gin.New()
+	router.PUT("/products/update/:id", NewproductHandler(svc, changeRequests, nil, config.StorageConfig{}, true, logging.Discard()).UpdateProduct)
+
+	body := "name=Desk+lamp&description=Brass&price=12&quantity=2"
+	req := httptest.NewRequest(http.MethodPut, "/products/update/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body)
+	}
+	if svc.updates != 0 {
+		t.Error("product saved without review")
+	}
+	if len(changeRequests.submitted) != 1 {
+		t.Fatalf("submitted %d change requests, want 1", len(changeRequests.submitted))
+	}
+
+	var response struct {
+		Data models.ProductChangeRequest `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.ProductID != 1 || response.Data.Name != "Desk lamp" || response.Data.Price != 12 || response.Data.Status != models.ChangeRequestPending {
+		t.Errorf("response data = %+v, want the pending change request", response.Data)
+	}
+}
+
+// bulkProductService records the bulk updates it runs.
+type bulkProductService struct {
+	service.ProductService
+
+	calls int
+}
+
+func (s *bulkProductService) BulkUpdate(ctx context.Context, ids []uint, filter *models.BulkProductFilter, patch *models.BulkProductPatch, actor string) (*models.BulkUpdateReport, error) {
+	s.calls++
+	items := []models.BulkItemResult{}
+	for _, id := range ids {
+		items = append(items, models.BulkItemResult{ID: id, Outcome: models.BulkOutcomeUpdated})
+	}
+	return &models.BulkUpdateReport{Total: len(items), Updated: len(items), Items: items}, nil
+}
+
+// fakeQueue keeps the jobs enqueued on it.
+type fakeQueue struct {
+	jobs.Queue
+
+	enqueued []models.Job
+}
+
+func (q *fakeQueue) Enqueue(ctx context.Context, jobType string, payload interface{}, createdBy string) (*models.Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := models.Job{ID: "job-1", Type: jobType, Payload: raw, Status: models.JobQueued, CreatedBy: createdBy}
+	q.enqueued = append(q.enqueued, job)
+	return &job, nil
+}
+
+func TestBulkUpdateProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		reviewMode bool
+		query      string
+		body       string
+		status     int
+		updated    bool
+		queued     bool
+	}{
+		{"sync", false, "", `{"ids":[1,2],"patch":{"quantity_delta":-1}}`, http.StatusOK, true, false},
+		{"async", false, "?async=true", `{"filter":{"status":"draft"},"patch":{"price":{"mode":"percentage","value":10}}}`, http.StatusAccepted, false, true},
+		{"review mode", true, "", `{"ids":[1],"patch":{"quantity_delta":1}}`, http.StatusConflict, false, false},
+		{"review mode async", true, "?async=true", `{"ids":[1],"patch":{"quantity_delta":1}}`, http.StatusConflict, false, false},
+		{"ids and filter", false, "", `{"ids":[1],"filter":{"status":"draft"},"patch":{"quantity_delta":1}}`, http.StatusBadRequest, false, false},
+		{"no selection", false, "?async=true", `{"patch":{"quantity_delta":1}}`, http.StatusBadRequest, false, false},
+		{"empty patch", false, "", `{"ids":[1],"patch":{}}`, http.StatusBadRequest, false, false},
+		{"invalid price mode", false, "", `{"ids":[1],"patch":{"price":{"mode":"double","value":2}}}`, http.StatusBadRequest, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &bulkProductService{}
+			queue := &fakeQueue{}
+			router := gin.New()
+			router.PUT("/products/bulk/update", NewproductHandler(svc, nil, queue, config.StorageConfig{}, tt.reviewMode, logging.Discard()).BulkUpdateProducts)
+
+			req := httptest.NewRequest(http.MethodPut, "/products/bulk/update"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if updated := svc.calls > 0; updated != tt.updated {
+				t.Errorf("updated synchronously = %v, want %v", updated, tt.updated)
+			}
+			if queued := len(queue.enqueued) > 0; queued != tt.queued {
+				t.Errorf("queued = %v, want %v", queued, tt.queued)
+			}
+
+			if tt.queued {
+				var payload jobs.BulkUpdateProductsPayload
+				if err := json.Unmarshal(queue.enqueued[0].Payload, &payload); err != nil {
+					t.Fatal(err)
+				}
+				if queue.enqueued[0].Type != jobs.JobTypeBulkUpdateProducts || payload.Filter == nil || payload.Filter.Status != "draft" || payload.Patch.Price == nil {
+					t.Errorf("queued %s with %+v", queue.enqueued[0].Type, payload)
+				}
+
+				var response struct {
+					Data models.Job `json:"data"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+					t.Fatal(err)
+				}
+				if response.Data.ID != "job-1" || response.Data.Payload != nil {
+					t.Errorf("response job = %+v, want job-1 without its payload", response.Data)
+				}
+			}
+		})
+	}
+}
+
+// transitionProductService moves its product along the lifecycle.
+type transitionProductService struct {
+	service.ProductService
+
+	product *models.Product
+	err     error
+}
+
+func (s *transitionProductService) TransitionStatus(ctx context.Context, id uint, target models.ProductStatus, actor string) (*models.Product, models.ProductStatus, error) {
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	if s.product.ID != id {
+		return nil, "", gorm.ErrRecordNotFound
+	}
+
+	previous := s.product.Status
+	if !previous.CanTransitionTo(target) {
+		return nil, previous, &models.InvalidTransitionError{From: previous, To: target}
+	}
+	s.product.Status = target
+	return s.product, previous, nil
+}
+
+func TestUpdateProductStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		err    error
+		status int
+		want   models.ProductStatus
+	}{
+		{"allowed", "/products/update-status/1", `{"status":"inactive"}`, nil, http.StatusOK, models.StatusInactive},
+		{"same state", "/products/update-status/1", `{"status":"active"}`, nil, http.StatusOK, models.StatusActive},
+		{"disallowed", "/products/update-status/1", `{"status":"draft"}`, nil, http.StatusUnprocessableEntity, models.StatusActive},
+		{"unknown status", "/products/update-status/1", `{"status":"deleted"}`, nil, http.StatusBadRequest, models.StatusActive},
+		{"not found", "/products/update-status/2", `{"status":"inactive"}`, nil, http.StatusNotFound, models.StatusActive},
+		{"changed concurrently", "/products/update-status/1", `{"status":"inactive"}`, errors.New("product changed concurrently"), http.StatusConflict, models.StatusActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &transitionProductService{product: &models.Product{ID: 1, Name: "Lamp", Status: models.StatusActive}, err: tt.err}
+			router := gin.New()
+			router.PUT("/products/update-status/:id", newTestProductHandler(svc).UpdateProductStatus)
+
+			req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if svc.product.Status != tt.want {
+				t.Errorf("product is %s, want %s", svc.product.Status, tt.want)
+			}
+
+			if tt.status == http.StatusUnprocessableEntity {
+				var body errorBody
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if got := body.Errors["Status"]; len(got) != 1 || got[0] != "The Status cannot change from active to draft." {
+					t.Errorf("errors = %v, want the disallowed transition", body.Errors)
+				}
+			}
+		})
+	}
+}
+
+// purgeProductService purges its product once it has been deleted.
+type purgeProductService struct {
+	service.ProductService
+
+	product *models.Product
+	purged  bool
+}
+
+func (s *purgeProductService) Purge(ctx context.Context, id uint) (*models.Product, error) {
+	if s.product.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if !s.product.DeletedAt.Valid {
+		return nil, errors.New("product is not deleted")
+	}
+	s.purged = true
+	return s.product, nil
+}
+
+func TestPurgeProduct(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		path    string
+		deleted bool
+		status  int
+		removed bool
+	}{
+		{"deleted", "/products/purge/1", true, http.StatusOK, true},
+		{"not deleted", "/products/purge/1", false, http.StatusBadRequest, false},
+		{"not found", "/products/purge/2", true, http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := config.StorageConfig{UploadDir: t.TempDir()}
+			if err := os.MkdirAll(storage.ProductUploadDir(), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			image := filepath.Join(storage.ProductUploadDir(), "lamp.png")
+			if err := os.WriteFile(image, []byte("png"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			product := &models.Product{ID: 1, Name: "Lamp", ImageURL: "/uploads/products/lamp.png"}
+			if tt.deleted {
+				product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			}
+			svc := &purgeProductService{product: product}
+			router := gin.New()
+			router.DELETE("/products/purge/:id", NewproductHandler(svc, nil, nil, storage, false, logging.Discard()).PurgeProduct)
+
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if svc.purged != (tt.status == http.StatusOK) {
+				t.Errorf("purged = %v", svc.purged)
+			}
+			_, err := os.Stat(image)
+			if removed := os.IsNotExist(err); removed != tt.removed {
+				t.Errorf("image removed = %v, want %v", removed, tt.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"product-service/internal/audit"
+	"product-service/internal/jobs"
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductImportHandler interface {
+	ImportProducts(ctx *gin.Context)
+}
+
+type productImportHandlerImpl struct {
+	service service.ProductImportService
+	queue   jobs.Queue
+}
+
+func NewProductImportHandler(service service.ProductImportService, queue jobs.Queue) *productImportHandlerImpl {
+	return &productImportHandlerImpl{service, queue}
+}
+
+func (h *productImportHandlerImpl) ImportProducts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var opts models.ImportOptions
+	if err := c.ShouldBind(&opts); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	if opts.Upsert && !middleware.HasPermission(c, "update_products") {
+		c.JSON(http.StatusForbidden, models.ErrorResponse(http.StatusForbidden, "Permission denied", "Upsert requires the update_products permission"))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", map[string][]string{
+			"File": {"The File field is required."},
+		}))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to open import file", err.Error()))
+		return
+	}
+	defer file.Close()
+
+	if c.Query("async") == "true" {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to read import file", err.Error()))
+			return
+		}
+
+		job, err := h.queue.Enqueue(ctx, jobs.JobTypeImportProducts, jobs.ImportProductsPayload{
+			CSV:     content,
+			Options: opts,
+			Actor:   middleware.GetUserEmail(c),
+			Origin:  audit.ActorFromContext(ctx),
+		}, middleware.GetUserEmail(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to queue import", err.Error()))
+			return
+		}
+
+		job.Payload = nil
+		c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Import queued", job))
+		return
+	}
+
+	report, err := h.service.Import(ctx, file, opts, middleware.GetUserEmail(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid CSV file", err.Error()))
+		return
+	}
+
+	message := "Products imported successfully"
+	if opts.DryRun {
+		message = "Dry run completed"
+	}
+	if report.Failed > 0 {
+		message += " with row errors"
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, message, report))
+}
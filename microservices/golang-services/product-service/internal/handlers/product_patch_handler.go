@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"product-service/internal/models"
+	"product-service/pkg/helpers"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+)
+
+// patchableFields maps the JSON members a PATCH may touch to the field names
+// used in validation errors.
+var patchableFields = map[string]string{
+	"name":         "Name",
+	"description":  "Description",
+	"price":        "Price",
+	"quantity":     "Quantity",
+	"publish_at":   "PublishAt",
+	"unpublish_at": "UnpublishAt",
+}
+
+// PatchProduct applies a partial update. The body may be a JSON Merge Patch
+// (application/merge-patch+json or application/json), a JSON Patch
+// (application/json-patch+json), or form data carrying only the fields to
+// change plus an optional replacement image. The fields the patch changes
+// are checked with the same rules as UpdateProduct; the ones it leaves alone
+// are kept as stored.
+func (h *productHandlerImpl) PatchProduct(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+		return
+	}
+
+	product, err := h.service.GetByIDForUpdate(ctx, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", err.Error()))
+		return
+	}
+
+	original, err := json.Marshal(models.PatchProductInput{
+		Name:        &product.Name,
+		Description: &product.Description,
+		Price:       &product.Price,
+		Quantity:    &product.Quantity,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to patch product", err.Error()))
+		return
+	}
+
+	var patched []byte
+	switch c.ContentType() {
+	case "application/json-patch+json":
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid request body", err.Error()))
+			return
+		}
+
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid JSON Patch", err.Error()))
+			return
+		}
+
+		patched, err = patch.Apply(original)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, "Failed to apply JSON Patch", err.Error()))
+			return
+		}
+	case "application/merge-patch+json", "application/json":
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid request body", err.Error()))
+			return
+		}
+
+		patched, err = jsonpatch.MergePatch(original, body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid merge patch", err.Error()))
+			return
+		}
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		body, validationErrors := formMergePatch(c)
+		if validationErrors != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+			return
+		}
+
+		patched, err = jsonpatch.MergePatch(original, body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid form data", err.Error()))
+			return
+		}
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse(http.StatusUnsupportedMediaType, "Unsupported content type", "Use application/merge-patch+json, application/json-patch+json or multipart/form-data"))
+		return
+	}
+
+	var input models.PatchProductInput
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", patchDecodeErrors(err)))
+		return
+	}
+
+	fields, err := patchedFields(original, patched)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to patch product", err.Error()))
+		return
+	}
+
+	if len(fields) > 0 {
+		if err := helpers.ValidateStructPartial(&input, fields...); err != nil {
+			validationErrors := helpers.ParseValidationErrors(err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+			return
+		}
+	}
+
+	if validationErrors := models.ValidatePublishWindow(input.PublishAt, input.UnpublishAt); validationErrors != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	// Only a field the patch removed can be nil, and validation rejects that.
+	product.Name = *input.Name
+	product.Description = *input.Description
+	product.Price = *input.Price
+	product.Quantity = *input.Quantity
+	product.PublishAt = input.PublishAt
+	product.UnpublishAt = input.UnpublishAt
+
+	h.saveProductUpdate(c, product)
+}
+
+// patchedFields lists the validation names of the members whose value
+// differs between the original and the patched document, including the
+// ones the patch added or removed.
+func patchedFields(original, patched []byte) ([]string, error) {
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, err
+	}
+
+	fields := []string{}
+	for key, field := range patchableFields {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// formMergePatch turns the submitted form fields into a merge patch document.
+// An empty publish_at or unpublish_at clears the schedule.
+func formMergePatch(c *gin.Context) ([]byte, map[string][]string) {
+	if _, err := c.MultipartForm(); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, map[string][]string{"error": {err.Error()}}
+	}
+
+	errs := map[string][]string{}
+	doc := map[string]interface{}{}
+
+	for key, values := range c.Request.PostForm {
+		field, ok := patchableFields[key]
+		if !ok {
+			errs[key] = []string{"The " + key + " field cannot be patched."}
+			continue
+		}
+
+		value := values[0]
+		switch key {
+		case "price":
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs[field] = []string{"The " + field + " must be a number."}
+				continue
+			}
+			doc[key] = price
+		case "quantity":
+			quantity, err := strconv.Atoi(value)
+			if err != nil {
+				errs[field] = []string{"The " + field + " must be an integer."}
+				continue
+			}
+			doc[key] = quantity
+		case "publish_at", "unpublish_at":
+			if value == "" {
+				doc[key] = nil
+				continue
+			}
+			doc[key] = value
+		default:
+			doc[key] = value
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, map[string][]string{"error": {err.Error()}}
+	}
+	return body, nil
+}
+
+func patchDecodeErrors(err error) map[string][]string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field, ok := patchableFields[typeErr.Field]
+		if !ok {
+			field = typeErr.Field
+		}
+		expected := "a " + typeErr.Type.String()
+		switch typeErr.Type.Kind() {
+		case reflect.Float32, reflect.Float64:
+			expected = "a number"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			expected = "an integer"
+		case reflect.String:
+			expected = "a string"
+		}
+		return map[string][]string{field: {"The " + field + " must be " + expected + "."}}
+	}
+
+	if msg := err.Error(); strings.HasPrefix(msg, "json: unknown field ") {
+		key := strings.Trim(strings.TrimPrefix(msg, "json: unknown field "), `"`)
+		return map[string][]string{key: {"The " + key + " field cannot be patched."}}
+	}
+
+	return helpers.ParseValidationErrors(err)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"product-service/config"
+	"product-service/internal/logging"
+	"product-service/internal/models"
+	"product-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeProductService holds a single product and keeps the last update.
+type fakeProductService struct {
+	service.ProductService
+
+	product *models.Product
+	updates int
+}
+
+func (s *fakeProductService) GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	if s.product == nil || s.product.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *s.product
+	return &copied, nil
+}
+
+func (s *fakeProductService) Update(ctx context.Context, id uint, product *models.Product, actor string) error {
+	s.updates++
+	copied := *product
+	s.product = &copied
+	return nil
+}
+
+func newTestProductHandler(svc service.ProductService) *productHandlerImpl {
+	return NewproductHandler(svc, nil, nil, config.StorageConfig{}, false, logging.Discard())
+}
+
+type errorBody struct {
+	Message string              `json:"message"`
+	Errors  map[string][]string `json:"error"`
+}
+
+func TestPatchProduct(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		want        func(p *models.Product) bool
+		errors      []string
+	}{
+		{
+			name:        "merge patch",
+			contentType: "application/merge-patch+json",
+			body:        `{"name":"Desk lamp","price":12.5}`,
+			status:      http.StatusOK,
+			want:        func(p *models.Product) bool { return p.Name == "Desk lamp" && p.Price == 12.5 },
+		},
+		{
+			name:        "plain json is a merge patch",
+			contentType: "application/json",
+			body:        `{"name":"Desk lamp"}`,
+			status:      http.StatusOK,
+			want:        func(p *models.Product) bool { return p.Name == "Desk lamp" },
+		},
+		{
+			name:        "merge patch selling out",
+			contentType: "application/merge-patch+json",
+			body:        `{"quantity":0}`,
+			status:      http.StatusOK,
+			want:        func(p *models.Product) bool { return p.Quantity == 0 },
+		},
+		{
+			name:        "json patch",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"replace","path":"/quantity","value":3},{"op":"test","path":"/name","value":"Lamp"}]`,
+			status:      http.StatusOK,
+			want:        func(p *models.Product) bool { return p.Quantity == 3 },
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "price=20&quantity=0&publish_at=",
+			status:      http.StatusOK,
+			want:        func(p *models.Product) bool { return p.Price == 20 && p.Quantity == 0 },
+		},
+		{
+			name:        "changed field is validated",
+			contentType: "application/merge-patch+json",
+			body:        `{"name":"ab","quantity":-1}`,
+			status:      http.StatusBadRequest,
+			errors:      []string{"Name", "Quantity"},
+		},
+		{
+			name:        "removing a required field",
+			contentType: "application/merge-patch+json",
+			body:        `{"price":null}`,
+			status:      http.StatusBadRequest,
+			errors:      []string{"Price"},
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/merge-patch+json",
+			body:        `{"sku":"LAMP-1"}`,
+			status:      http.StatusBadRequest,
+			errors:      []string{"sku"},
+		},
+		{
+			name:        "unknown form field",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "status=active",
+			status:      http.StatusBadRequest,
+			errors:      []string{"status"},
+		},
+		{
+			name:        "wrong type",
+			contentType: "application/merge-patch+json",
+			body:        `{"quantity":"many"}`,
+			status:      http.StatusBadRequest,
+			errors:      []string{"Quantity"},
+		},
+		{
+			name:        "invalid op",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"rename","path":"/name","value":"Desk lamp"}]`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "failed test op",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"test","path":"/name","value":"Chair"},{"op":"replace","path":"/name","value":"Desk lamp"}]`,
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "malformed json patch",
+			contentType: "application/json-patch+json",
+			body:        `{"op":"replace"}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/plain",
+			body:        "name=Desk lamp",
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Stored out of stock and without a description, neither of
+			// which UpdateProduct would accept.
+			svc := &fakeProductService{product: &models.Product{ID: 1, Name: "Lamp", Price: 10, Status: models.StatusActive}}
+			router := gin.New()
+			router.PATCH("/products/:id", newTestProductHandler(svc).PatchProduct)
+
+			req := httptest.NewRequest(http.MethodPatch, "/products/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			if tt.status != http.StatusOK {
+				if svc.updates != 0 {
+					t.Error("rejected patch was saved")
+				}
+				if len(tt.errors) == 0 {
+					return
+				}
+				var body errorBody
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				for _, field := range tt.errors {
+					if _, ok := body.Errors[field]; !ok {
+						t.Errorf("no error for %s in %v", field, body.Errors)
+					}
+				}
+				return
+			}
+
+			if svc.updates != 1 {
+				t.Fatalf("saved %d times, want once", svc.updates)
+			}
+			if !tt.want(svc.product) {
+				t.Errorf("saved %+v", svc.product)
+			}
+		})
+	}
+}
+
+func TestPatchProductNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/products/:id", newTestProductHandler(&fakeProductService{}).PatchProduct)
+
+	req := httptest.NewRequest(http.MethodPatch, "/products/2", strings.NewReader(`{"name":"Desk lamp"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
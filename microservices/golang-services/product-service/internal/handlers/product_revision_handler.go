@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductRevisionHandler interface {
+	GetProductRevisions(ctx *gin.Context)
+	DiffProductRevisions(ctx *gin.Context)
+	RollbackProductRevision(ctx *gin.Context)
+}
+
+type productRevisionHandlerImpl struct {
+	service service.ProductRevisionService
+	logger  *slog.Logger
+}
+
+func NewProductRevisionHandler(service service.ProductRevisionService, logger *slog.Logger) *productRevisionHandlerImpl {
+	return &productRevisionHandlerImpl{service, logger}
+}
+
+func (h *productRevisionHandlerImpl) GetProductRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+		return
+	}
+
+	pagination, limit, offset := helpers.GetPagination(c, 15)
+
+	revisions, total, err := h.service.GetByProductID(ctx, uint(id), limit, offset)
+	if err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found", nil))
+			return
+		}
+
+		h.logger.ErrorContext(ctx, "Failed to get product revisions", "product_id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get product revisions", err.Error()))
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Status:      http.StatusOK,
+		Message:     "Successfully Get Product Revisions",
+		Data:        revisions,
+		Total:       total,
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		TotalPages:  totalPages,
+		Error:       false,
+	})
+}
+
+func (h *productRevisionHandlerImpl) DiffProductRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+		return
+	}
+
+	fromID, err := strconv.ParseUint(c.Query("from"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid revision ID", "The from revision ID must be a number"))
+		return
+	}
+
+	var toID uint64
+	if toStr := c.Query("to"); toStr != "" && toStr != "current" {
+		toID, err = strconv.ParseUint(toStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid revision ID", "The to revision ID must be a number or current"))
+			return
+		}
+	}
+
+	changes, err := h.service.Diff(ctx, uint(id), uint(fromID), uint(toID))
+	if err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Revision not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to diff product revisions", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Successfully Diff Product Revisions", changes))
+}
+
+func (h *productRevisionHandlerImpl) RollbackProductRevision(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid product ID", "Product ID must be a number"))
+		return
+	}
+
+	revisionID, err := strconv.ParseUint(c.Param("revision_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid revision ID", "Revision ID must be a number"))
+		return
+	}
+
+	product, changeRequest, err := h.service.Rollback(ctx, uint(id), uint(revisionID), middleware.GetUserEmail(c))
+	if err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Revision not found", nil))
+			return
+		}
+
+		if err.Error() == "product changed concurrently" {
+			c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Product was changed by another request", "Retry the rollback"))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to rollback product", err.Error()))
+		return
+	}
+
+	if changeRequest != nil {
+		c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Product rollback submitted for review", changeRequest))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Product rolled back successfully", product))
+}
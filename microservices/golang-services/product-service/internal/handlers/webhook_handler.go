@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler interface {
+	GetWebhooks(ctx *gin.Context)
+	GetWebhook(ctx *gin.Context)
+	CreateWebhook(ctx *gin.Context)
+	UpdateWebhook(ctx *gin.Context)
+	DeleteWebhook(ctx *gin.Context)
+	GetWebhookDeliveries(ctx *gin.Context)
+	RedeliverWebhook(ctx *gin.Context)
+}
+
+type webhookHandlerImpl struct {
+	service service.WebhookService
+	logger  *slog.Logger
+}
+
+func NewWebhookHandler(service service.WebhookService, logger *slog.Logger) *webhookHandlerImpl {
+	return &webhookHandlerImpl{service, logger}
+}
+
+func (h *webhookHandlerImpl) GetWebhooks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	pagination, limit, offset := helpers.GetPagination(c, 15)
+
+	subscriptions, total, err := h.service.GetAll(ctx, limit, offset)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to get webhooks", "error", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get webhooks", err.Error()))
+		return
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Status:      http.StatusOK,
+		Message:     "Successfully Get Webhooks",
+		Data:        subscriptions,
+		Total:       total,
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		TotalPages:  totalPages,
+		Error:       false,
+	})
+}
+
+func (h *webhookHandlerImpl) GetWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid webhook ID", "Webhook ID must be a number"))
+		return
+	}
+
+	subscription, err := h.service.GetByID(ctx, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Webhook not found", err.Error()))
+		return
+	}
+
+	subscription.Secret = ""
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Successfully Get Webhook", subscription))
+}
+
+// CreateWebhook returns the signing secret once; later reads omit it.
+func (h *webhookHandlerImpl) CreateWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input models.WebhookSubscriptionInput
+	if err := c.ShouldBind(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	subscription, err := h.service.Create(ctx, &input, middleware.GetUserEmail(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to create webhook", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(http.StatusCreated, "Webhook created successfully", subscription))
+}
+
+func (h *webhookHandlerImpl) UpdateWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid webhook ID", "Webhook ID must be a number"))
+		return
+	}
+
+	var input models.WebhookSubscriptionInput
+	if err := c.ShouldBind(&input); err != nil {
+		validationErrors := helpers.ParseValidationErrors(err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", validationErrors))
+		return
+	}
+
+	subscription, err := h.service.Update(ctx, uint(id), &input)
+	if err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Webhook not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update webhook", err.Error()))
+		return
+	}
+
+	subscription.Secret = ""
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Webhook updated successfully", subscription))
+}
+
+func (h *webhookHandlerImpl) DeleteWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid webhook ID", "Webhook ID must be a number"))
+		return
+	}
+
+	if err := h.service.Delete(ctx, uint(id)); err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Webhook not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to delete webhook", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Webhook deleted successfully", nil))
+}
+
+func (h *webhookHandlerImpl) GetWebhookDeliveries(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid webhook ID", "Webhook ID must be a number"))
+		return
+	}
+
+	pagination, limit, offset := helpers.GetPagination(c, 15)
+
+	deliveries, total, err := h.service.GetDeliveries(ctx, uint(id), limit, offset)
+	if err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Webhook not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get webhook deliveries", err.Error()))
+		return
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Status:      http.StatusOK,
+		Message:     "Successfully Get Webhook Deliveries",
+		Data:        deliveries,
+		Total:       total,
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		TotalPages:  totalPages,
+		Error:       false,
+	})
+}
+
+func (h *webhookHandlerImpl) RedeliverWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid delivery ID", "Delivery ID must be a number"))
+		return
+	}
+
+	delivery, err := h.service.Redeliver(ctx, uint(id))
+	if err != nil {
+		if err.Error() == "record not found" || err.Error() == "gorm: record not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Webhook delivery not found", nil))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to redeliver webhook", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Webhook delivery queued", delivery))
+}
@@ -0,0 +1,134 @@
+// Package health runs the dependency checks behind the readiness endpoint.
+// Checks run on every probe, so the service reports ready as soon as its
+// dependencies recover without having to restart.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// CheckFunc reports whether a dependency is usable. It must return once ctx
+// is done.
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of one check. Only Status is serialized: the
+// readiness endpoint is unauthenticated, so errors are for the logs.
+type Result struct {
+	Status  string        `json:"status"`
+	Err     error         `json:"-"`
+	Latency time.Duration `json:"-"`
+}
+
+type Report struct {
+	Status   string            `json:"status"`
+	Draining bool              `json:"draining,omitempty"`
+	Checks   map[string]Result `json:"checks"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type Checker struct {
+	timeout  time.Duration
+	draining atomic.Bool
+	mu       sync.RWMutex
+	checks   map[string]CheckFunc
+}
+
+// NewChecker returns a Checker that gives each check at most timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  map[string]CheckFunc{},
+	}
+}
+
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Drain makes every later report fail, so load balancers stop sending new
+// requests while the in-flight ones finish.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check runs every registered check concurrently. The report is OK only when
+// all of them pass and the service is not draining.
+func (c *Checker) Check(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusError, Draining: true, Checks: map[string]Result{}}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusError
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := Result{Status: StatusOK, Err: err, Latency: time.Since(start)}
+	if err != nil {
+		result.Status = StatusError
+	}
+	return result
+}
+
+// HTTPCheck passes when url answers with any status below 500. Services
+// that require credentials still prove they are up by rejecting the probe.
+func HTTPCheck(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckReportsEveryDependency(t *testing.T) {
+	checker := NewChecker(20 * time.Millisecond)
+	checker.Register("postgres", func(ctx context.Context) error { return nil })
+	checker.Register("redis", func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.3.7:6379: connection refused")
+	})
+	checker.Register("auth", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := checker.Check(context.Background())
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Check took %v; checks should run concurrently within the timeout", elapsed)
+	}
+	if report.OK() {
+		t.Error("report is OK with failing checks")
+	}
+	want := map[string]string{"postgres": StatusOK, "redis": StatusError, "auth": StatusError}
+	for name, status := range want {
+		if got := report.Checks[name].Status; got != status {
+			t.Errorf("%s: status %q, want %q", name, got, status)
+		}
+	}
+	if !errors.Is(report.Checks["auth"].Err, context.DeadlineExceeded) {
+		t.Errorf("auth: error %v, want the deadline", report.Checks["auth"].Err)
+	}
+}
+
+func TestReportHidesErrors(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("postgres", func(ctx context.Context) error {
+		return errors.New(`failed to connect to host=db.internal user=products: password authentication failed`)
+	})
+
+	body, err := json.Marshal(checker.Check(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"status":"error","checks":{"postgres":{"status":"error"}}}`; string(body) != want {
+		t.Errorf("report %s, want %s", body, want)
+	}
+}
+
+func TestDrainFailsWithoutRunningChecks(t *testing.T) {
+	checker := NewChecker(time.Second)
+	runs := 0
+	checker.Register("postgres", func(ctx context.Context) error {
+		runs++
+		return nil
+	})
+	if report := checker.Check(context.Background()); !report.OK() {
+		t.Fatalf("report before Drain is not OK: %+v", report)
+	}
+
+	checker.Drain()
+	report := checker.Check(context.Background())
+
+	if runs != 1 {
+		t.Errorf("check ran %d times, want only before Drain", runs)
+	}
+	if report.OK() || !report.Draining {
+		t.Errorf("report after Drain: %+v, want a failing draining report", report)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		err := HTTPCheck(server.Client(), server.URL)(context.Background())
+		server.Close()
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("status %d: error %v, want error %v", tt.status, err, tt.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), "503") {
+			t.Errorf("status %d: error %q does not name the status", tt.status, err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"product-service/config"
+	"product-service/internal/audit"
+	"product-service/internal/metrics"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+	"strings"
+)
+
+const (
+	JobTypeImportProducts         = "products.import"
+	JobTypeBulkUpdateProducts     = "products.bulk_update"
+	JobTypeExportProducts         = "products.export"
+	JobTypeReprocessProductImages = "products.reprocess_images"
+)
+
+// The payloads of jobs that change products carry the Origin of the request
+// that queued them, so the changes are audited as that user's.
+type ImportProductsPayload struct {
+	CSV     []byte               `json:"csv"`
+	Options models.ImportOptions `json:"options"`
+	Actor   string               `json:"actor"`
+	Origin  audit.Actor          `json:"origin"`
+}
+
+type BulkUpdateProductsPayload struct {
+	IDs    []uint                    `json:"ids,omitempty"`
+	Filter *models.BulkProductFilter `json:"filter,omitempty"`
+	Patch  models.BulkProductPatch   `json:"patch"`
+	Actor  string                    `json:"actor"`
+	Origin audit.Actor               `json:"origin"`
+}
+
+type ExportProductsPayload struct {
+	Format string                `json:"format"`
+	Search string                `json:"search,omitempty"`
+	Status *models.ProductStatus `json:"status,omitempty"`
+}
+
+// ExportProductsResult describes the export file. It is stored in S3 under
+// ObjectKey and downloaded from GET /jobs/:id/download.
+type ExportProductsResult struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	ObjectKey   string `json:"object_key"`
+	DownloadURL string `json:"download_url"`
+}
+
+type ReprocessProductImagesPayload struct {
+	IDs    []uint      `json:"ids,omitempty"`
+	Actor  string      `json:"actor"`
+	Origin audit.Actor `json:"origin"`
+}
+
+// ReprocessProductImagesResult lists the outcome of moving locally stored
+// images to S3. Missing are products whose local file no longer exists;
+// Skipped are products whose image changed while the job ran.
+type ReprocessProductImagesResult struct {
+	Total   int    `json:"total"`
+	Moved   int    `json:"moved"`
+	Missing []uint `json:"missing"`
+	Skipped []uint `json:"skipped"`
+}
+
+func RegisterProductJobs(queue Queue, product service.ProductService, importer service.ProductImportService, exporter service.ProductExportService, storage config.StorageConfig, logger *slog.Logger) {
+	queue.Register(JobTypeImportProducts, func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		var payload ImportProductsPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, Permanent(err)
+		}
+
+		reader := &progressReader{
+			r:        bytes.NewReader(payload.CSV),
+			total:    len(payload.CSV),
+			progress: progress,
+		}
+
+		ctx = audit.WithActor(ctx, payload.Origin)
+		report, err := importer.Import(ctx, reader, payload.Options, payload.Actor)
+		if err != nil {
+			// The file itself is malformed; rows before the error may
+			// already be imported, so running it again would duplicate them.
+			return nil, Permanent(err)
+		}
+		return report, nil
+	})
+
+	queue.Register(JobTypeBulkUpdateProducts, func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		var payload BulkUpdateProductsPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, Permanent(err)
+		}
+
+		progress(0, "Updating products")
+		ctx = audit.WithActor(ctx, payload.Origin)
+		report, err := product.BulkUpdate(ctx, payload.IDs, payload.Filter, &payload.Patch, payload.Actor)
+		if err != nil {
+			return nil, classify(err)
+		}
+		return report, nil
+	})
+
+	queue.Register(JobTypeExportProducts, func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		var payload ExportProductsPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, Permanent(err)
+		}
+
+		progress(0, "Exporting products")
+		return exportProducts(ctx, exporter, storage, logger, job, payload)
+	})
+
+	queue.Register(JobTypeReprocessProductImages, func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		var payload ReprocessProductImagesPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, Permanent(err)
+		}
+		ctx = audit.WithActor(ctx, payload.Origin)
+		return reprocessImages(ctx, product, storage, logger, payload, progress)
+	})
+}
+
+// exportProducts writes the export to a temporary file, so memory use stays
+// flat however many products there are, and uploads it to S3.
+func exportProducts(ctx context.Context, exporter service.ProductExportService, storage config.StorageConfig, logger *slog.Logger, job *models.Job, payload ExportProductsPayload) (*ExportProductsResult, error) {
+	file, err := os.CreateTemp("", "products-export-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	if err := exporter.Export(ctx, file, payload.Format, payload.Search, payload.Status); err != nil {
+		return nil, classify(err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	uploader, err := helpers.NewS3Uploader(storage.S3Bucket, storage.S3Folder, logger, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("products-%s.%s", job.CreatedAt.Format("20060102-150405"), payload.Format)
+	contentType := service.ExportContentTypes[payload.Format]
+	key, err := uploader.UploadObject(ctx, "exports/"+job.ID+"/"+fileName, file, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportProductsResult{
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		ObjectKey:   key,
+		DownloadURL: "/jobs/" + job.ID + "/download",
+	}, nil
+}
+
+// reprocessImages uploads the images that fell back to local storage to S3
+// and points their products at the uploaded copy. A failed upload returns
+// the error so the job is retried; products already moved are not selected
+// again.
+func reprocessImages(ctx context.Context, product service.ProductService, storage config.StorageConfig, logger *slog.Logger, payload ReprocessProductImagesPayload, progress ProgressFunc) (*ReprocessProductImagesResult, error) {
+	products, err := product.GetWithLocalImages(ctx, payload.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	uploader, err := helpers.NewS3Uploader(storage.S3Bucket, storage.S3Folder, logger, metrics.RecordS3Upload)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReprocessProductImagesResult{Total: len(products), Missing: []uint{}, Skipped: []uint{}}
+	for i := range products {
+		p := &products[i]
+
+		url, err := uploader.UploadLocalFile(ctx, p.ImageURL, storage.ProductUploadDir())
+		if os.IsNotExist(err) {
+			result.Missing = append(result.Missing, p.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := product.ReplaceImageURL(ctx, p.ID, p.ImageURL, url, payload.Actor); err != nil {
+			if err.Error() != "product image changed" && err.Error() != "record not found" {
+				return nil, err
+			}
+			if err := uploader.DeleteFileFromS3(ctx, url); err != nil {
+				logger.WarnContext(ctx, "Failed to delete unused S3 image", "image_url", url, "error", err)
+			}
+			result.Skipped = append(result.Skipped, p.ID)
+			continue
+		}
+
+		if err := uploader.DeleteStoredFile(ctx, p.ImageURL, storage.ProductUploadDir()); err != nil {
+			logger.WarnContext(ctx, "Failed to delete local image", "image_url", p.ImageURL, "error", err)
+		}
+		result.Moved++
+		progress((i+1)*100/len(products), "Moving images to S3")
+	}
+
+	return result, nil
+}
+
+// classify marks the errors that running a job again cannot fix as
+// permanent, so the job is dead-lettered at once instead of retried.
+func classify(err error) error {
+	switch err.Error() {
+	case "record not found", "gorm: record not found", "bulk selection exceeds limit":
+		return Permanent(err)
+	}
+	if strings.HasPrefix(err.Error(), "unsupported export format") {
+		return Permanent(err)
+	}
+	return err
+}
+
+// progressReader reports how much of the input has been consumed, in steps
+// of at least 5%.
+type progressReader struct {
+	r        io.Reader
+	read     int
+	total    int
+	last     int
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += n
+	if p.total > 0 {
+		percent := p.read * 100 / p.total
+		if percent >= p.last+5 && percent < 100 {
+			p.last = percent
+			p.progress(percent, "Importing rows")
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,521 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"product-service/internal/models"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HandlerFunc runs one job. The returned result is stored on the job as JSON.
+// Returning an error schedules a retry until the job runs out of attempts.
+type HandlerFunc func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error)
+
+// PermanentError marks a job failure that retrying cannot fix; the job goes
+// straight to the dead-letter list.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// ProgressFunc reports how far a running job is, as a percentage.
+type ProgressFunc func(percent int, message string)
+
+type Queue interface {
+	Register(jobType string, handler HandlerFunc)
+	Enqueue(ctx context.Context, jobType string, payload interface{}, createdBy string) (*models.Job, error)
+	Get(ctx context.Context, id string) (*models.Job, error)
+	DeadLetters(ctx context.Context, limit, offset int) ([]models.Job, int64, error)
+	Retry(ctx context.Context, id string) (*models.Job, error)
+	Start(ctx context.Context)
+}
+
+type Options struct {
+	Concurrency int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Retention   time.Duration
+	StaleAfter  time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Concurrency: 4,
+		MaxAttempts: 3,
+		BaseBackoff: 5 * time.Second,
+		MaxBackoff:  10 * time.Minute,
+		Retention:   7 * 24 * time.Hour,
+		StaleAfter:  5 * time.Minute,
+	}
+}
+
+const (
+	jobKeyPrefix  = "product-service:jobs:job:"
+	queueKey      = "product-service:jobs:queue"
+	processingKey = "product-service:jobs:processing"
+	heartbeatKey  = "product-service:jobs:heartbeats"
+	delayedKey    = "product-service:jobs:delayed"
+	deadKey       = "product-service:jobs:dead"
+)
+
+type redisQueue struct {
+	client   *redis.Client
+	opts     Options
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewRedisQueue(client *redis.Client, opts Options, logger *slog.Logger) Queue {
+	return newRedisQueue(client, opts, logger)
+}
+
+func newRedisQueue(client *redis.Client, opts Options, logger *slog.Logger) *redisQueue {
+	return &redisQueue{
+		client:   client,
+		opts:     opts,
+		handlers: map[string]HandlerFunc{},
+		logger:   logger.With("component", "job_queue"),
+		now:      time.Now,
+	}
+}
+
+func (q *redisQueue) Register(jobType string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+func (q *redisQueue) Enqueue(ctx context.Context, jobType string, payload interface{}, createdBy string) (*models.Job, error) {
+	q.mu.RLock()
+	_, ok := q.handlers[jobType]
+	q.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown job type: %s", jobType)
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.now()
+	job := &models.Job{
+		ID:          id,
+		Type:        jobType,
+		Payload:     raw,
+		Status:      models.JobQueued,
+		MaxAttempts: q.opts.MaxAttempts,
+		CreatedBy:   createdBy,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := q.save(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := q.client.LPush(ctx, queueKey, job.ID).Err(); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (q *redisQueue) Get(ctx context.Context, id string) (*models.Job, error) {
+	raw, err := q.client.Get(ctx, jobKeyPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(raw, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (q *redisQueue) DeadLetters(ctx context.Context, limit, offset int) ([]models.Job, int64, error) {
+	total, err := q.client.LLen(ctx, deadKey).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids, err := q.client.LRange(ctx, deadKey, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make([]models.Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := q.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, total, nil
+}
+
+// Retry moves a dead job back onto the queue with a fresh set of attempts.
+func (q *redisQueue) Retry(ctx context.Context, id string) (*models.Job, error) {
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobDead {
+		return nil, fmt.Errorf("job is not dead")
+	}
+
+	removed, err := q.client.LRem(ctx, deadKey, 1, id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		return nil, fmt.Errorf("job is not dead")
+	}
+
+	job.Status = models.JobQueued
+	job.Attempts = 0
+	job.Progress = 0
+	job.ProgressMessage = ""
+	job.Error = ""
+	job.FinishedAt = nil
+	job.RunAt = q.now()
+	if err := q.save(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := q.client.LPush(ctx, queueKey, job.ID).Err(); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Start runs the worker pool and the scheduler that promotes delayed retries
+// and requeues jobs whose worker stopped heartbeating. It blocks until ctx is
+// cancelled and the running jobs have returned.
+func (q *redisQueue) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < q.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.schedule(ctx)
+	}()
+
+	wg.Wait()
+}
+
+func (q *redisQueue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		id, err := q.claim(ctx, 5*time.Second)
+		if err == redis.Nil || ctx.Err() != nil {
+			continue
+		}
+		if err != nil {
+			q.logger.ErrorContext(ctx, "Failed to fetch job", "error", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		q.process(ctx, id)
+	}
+}
+
+// claim moves the next job onto the processing list, waiting up to timeout
+// for one, and stamps its first heartbeat.
+func (q *redisQueue) claim(ctx context.Context, timeout time.Duration) (string, error) {
+	id, err := q.client.BLMove(ctx, queueKey, processingKey, "RIGHT", "LEFT", timeout).Result()
+	if err != nil {
+		return "", err
+	}
+	if err := q.heartbeat(ctx, id); err != nil {
+		q.logger.WarnContext(ctx, "Failed to heartbeat job", "job_id", id, "error", err)
+	}
+	return id, nil
+}
+
+// heartbeat records that the worker running job id is still alive.
+func (q *redisQueue) heartbeat(ctx context.Context, id string) error {
+	return q.client.HSet(ctx, heartbeatKey, id, q.now().UnixMilli()).Err()
+}
+
+func (q *redisQueue) process(ctx context.Context, id string) {
+	// A job that has started is allowed to finish even when the queue is
+	// shutting down; only fetching new jobs stops.
+	ctx = context.WithoutCancel(ctx)
+	defer q.release(ctx, id)
+
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		q.logger.ErrorContext(ctx, "Failed to load job", "job_id", id, "error", err)
+		return
+	}
+
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		job.Attempts = job.MaxAttempts
+		q.fail(ctx, job, fmt.Errorf("unknown job type: %s", job.Type))
+		return
+	}
+
+	job.Status = models.JobRunning
+	job.Attempts++
+	job.Error = ""
+	if err := q.save(ctx, job); err != nil {
+		q.logger.ErrorContext(ctx, "Failed to mark job running", "job_id", id, "error", err)
+	}
+
+	var progressMu sync.Mutex
+	progress := func(percent int, message string) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		job.Progress = min(max(percent, 0), 100)
+		job.ProgressMessage = message
+		if err := q.save(ctx, job); err != nil {
+			q.logger.WarnContext(ctx, "Failed to report job progress", "job_id", id, "error", err)
+		}
+	}
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(q.opts.StaleAfter / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatDone:
+				return
+			case <-ticker.C:
+				if err := q.heartbeat(ctx, id); err != nil {
+					q.logger.WarnContext(ctx, "Failed to heartbeat job", "job_id", id, "error", err)
+				}
+			}
+		}
+	}()
+
+	result, err := runHandler(ctx, handler, job, progress)
+	close(heartbeatDone)
+
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
+	if err != nil {
+		q.fail(ctx, job, err)
+		return
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		q.fail(ctx, job, err)
+		return
+	}
+
+	now := q.now()
+	job.Status = models.JobSucceeded
+	job.Progress = 100
+	job.Result = raw
+	job.FinishedAt = &now
+	if err := q.save(ctx, job); err != nil {
+		q.logger.ErrorContext(ctx, "Failed to store job result", "job_id", id, "error", err)
+	}
+}
+
+func runHandler(ctx context.Context, handler HandlerFunc, job *models.Job, progress ProgressFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job, progress)
+}
+
+// fail schedules a retry with exponential backoff, or moves the job to the
+// dead-letter list once it has used all of its attempts.
+func (q *redisQueue) fail(ctx context.Context, job *models.Job, jobErr error) {
+	job.Error = jobErr.Error()
+
+	var permanent *PermanentError
+	if job.Attempts < job.MaxAttempts && !errors.As(jobErr, &permanent) {
+		backoff := q.opts.BaseBackoff << (job.Attempts - 1)
+		if backoff > q.opts.MaxBackoff || backoff <= 0 {
+			backoff = q.opts.MaxBackoff
+		}
+
+		job.Status = models.JobRetrying
+		job.RunAt = q.now().Add(backoff)
+		if err := q.save(ctx, job); err != nil {
+			q.logger.ErrorContext(ctx, "Failed to save job", "job_id", job.ID, "error", err)
+		}
+		if err := q.client.ZAdd(ctx, delayedKey, redis.Z{Score: float64(job.RunAt.Unix()), Member: job.ID}).Err(); err != nil {
+			q.logger.ErrorContext(ctx, "Failed to schedule job retry", "job_id", job.ID, "error", err)
+		}
+		q.logger.WarnContext(ctx, "Job failed, retrying", "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "max_attempts", job.MaxAttempts, "backoff", backoff, "error", jobErr)
+		return
+	}
+
+	now := q.now()
+	job.Status = models.JobDead
+	job.FinishedAt = &now
+	if err := q.save(ctx, job); err != nil {
+		q.logger.ErrorContext(ctx, "Failed to save job", "job_id", job.ID, "error", err)
+	}
+	if err := q.client.LPush(ctx, deadKey, job.ID).Err(); err != nil {
+		q.logger.ErrorContext(ctx, "Failed to dead-letter job", "job_id", job.ID, "error", err)
+	}
+	q.logger.ErrorContext(ctx, "Job moved to dead-letter list", "job_id", job.ID, "job_type", job.Type, "attempts", job.Attempts, "error", jobErr)
+}
+
+func (q *redisQueue) schedule(ctx context.Context) {
+	promote := time.NewTicker(time.Second)
+	defer promote.Stop()
+	recoverStale := time.NewTicker(q.opts.StaleAfter / 2)
+	defer recoverStale.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-promote.C:
+			q.promoteDelayed(ctx)
+		case <-recoverStale.C:
+			q.requeueStale(ctx)
+		}
+	}
+}
+
+func (q *redisQueue) promoteDelayed(ctx context.Context) {
+	ids, err := q.client.ZRangeByScore(ctx, delayedKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(q.now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		q.logger.ErrorContext(ctx, "Failed to read delayed jobs", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		removed, err := q.client.ZRem(ctx, delayedKey, id).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		if err := q.client.LPush(ctx, queueKey, id).Err(); err != nil {
+			q.logger.ErrorContext(ctx, "Failed to requeue job", "job_id", id, "error", err)
+		}
+	}
+}
+
+// requeueStale takes back the jobs left on the processing list by a worker
+// that stopped heartbeating, e.g. because it crashed. A job that had started
+// used up an attempt, so it is retried with backoff or dead-lettered like a
+// failed one; a job that died before starting is queued again as it was.
+func (q *redisQueue) requeueStale(ctx context.Context) {
+	ids, err := q.client.LRange(ctx, processingKey, 0, -1).Result()
+	if err != nil {
+		q.logger.ErrorContext(ctx, "Failed to read processing jobs", "error", err)
+		return
+	}
+
+	for _, id := range ids {
+		beat, err := q.client.HGet(ctx, heartbeatKey, id).Int64()
+		if err == redis.Nil {
+			// Claimed before its heartbeat was stamped; it has a full
+			// StaleAfter from now to show up.
+			q.client.HSetNX(ctx, heartbeatKey, id, q.now().UnixMilli())
+			continue
+		}
+		if err != nil || q.now().Sub(time.UnixMilli(beat)) < q.opts.StaleAfter {
+			continue
+		}
+
+		removed, err := q.client.LRem(ctx, processingKey, 1, id).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		q.client.HDel(ctx, heartbeatKey, id)
+
+		job, err := q.Get(ctx, id)
+		if err != nil {
+			q.logger.ErrorContext(ctx, "Failed to load stale job", "job_id", id, "error", err)
+			continue
+		}
+
+		if job.Status == models.JobRunning {
+			q.fail(ctx, job, fmt.Errorf("worker stopped heartbeating"))
+			continue
+		}
+
+		q.logger.WarnContext(ctx, "Requeueing stale job", "job_id", job.ID, "job_type", job.Type)
+		job.Status = models.JobQueued
+		if err := q.save(ctx, job); err != nil {
+			q.logger.ErrorContext(ctx, "Failed to save job", "job_id", job.ID, "error", err)
+		}
+		if err := q.client.LPush(ctx, queueKey, id).Err(); err != nil {
+			q.logger.ErrorContext(ctx, "Failed to requeue job", "job_id", id, "error", err)
+		}
+	}
+}
+
+// release takes a job its worker is done with off the processing list.
+func (q *redisQueue) release(ctx context.Context, id string) {
+	q.client.LRem(ctx, processingKey, 1, id)
+	q.client.HDel(ctx, heartbeatKey, id)
+}
+
+func (q *redisQueue) save(ctx context.Context, job *models.Job) error {
+	job.UpdatedAt = q.now()
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.client.Set(ctx, jobKeyPrefix+job.ID, raw, q.opts.Retention).Err()
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
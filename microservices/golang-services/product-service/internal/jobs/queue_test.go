@@ -0,0 +1,463 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"product-service/internal/logging"
+	"product-service/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testJobType = "test.job"
+
+// testQueue is a queue on miniredis whose clock only moves when told to.
+type testQueue struct {
+	*redisQueue
+	redis   *miniredis.Miniredis
+	current time.Time
+}
+
+func newTestQueue(t *testing.T, opts Options) *testQueue {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	q := &testQueue{
+		redisQueue: newRedisQueue(client, opts, logging.Discard()),
+		redis:      mr,
+		current:    time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	q.now = func() time.Time { return q.current }
+	return q
+}
+
+func testOptions() Options {
+	return Options{
+		Concurrency: 1,
+		MaxAttempts: 3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+		Retention:   time.Hour,
+		StaleAfter:  time.Minute,
+	}
+}
+
+func (q *testQueue) advance(d time.Duration) {
+	q.current = q.current.Add(d)
+}
+
+func (q *testQueue) list(t *testing.T, key string) []string {
+	t.Helper()
+	if !q.redis.Exists(key) {
+		return nil
+	}
+	ids, err := q.redis.List(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func (q *testQueue) job(t *testing.T, id string) *models.Job {
+	t.Helper()
+	job, err := q.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// runNext claims the next job and runs it to completion.
+func (q *testQueue) runNext(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	id, err := q.claim(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("claiming a job: %v", err)
+	}
+	q.process(ctx, id)
+	return id
+}
+
+func (q *testQueue) register(handler HandlerFunc) {
+	q.Register(testJobType, handler)
+}
+
+func TestEnqueue(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, nil
+	})
+
+	if _, err := q.Enqueue(ctx, "unknown", nil, "admin@example.com"); err == nil {
+		t.Error("enqueued a job of an unregistered type")
+	}
+
+	job, err := q.Enqueue(ctx, testJobType, map[string]int{"n": 1}, "admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := q.job(t, job.ID)
+	if stored.Status != models.JobQueued || stored.Attempts != 0 || stored.MaxAttempts != 3 {
+		t.Errorf("stored job is %s with %d/%d attempts", stored.Status, stored.Attempts, stored.MaxAttempts)
+	}
+	if string(stored.Payload) != `{"n":1}` || stored.CreatedBy != "admin@example.com" {
+		t.Errorf("stored payload %s by %s", stored.Payload, stored.CreatedBy)
+	}
+	if got := q.list(t, queueKey); len(got) != 1 || got[0] != job.ID {
+		t.Errorf("queue holds %v, want [%s]", got, job.ID)
+	}
+	if ttl := q.redis.TTL(jobKeyPrefix + job.ID); ttl != time.Hour {
+		t.Errorf("job is kept for %s, want the retention of 1h", ttl)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, nil
+	})
+
+	first, _ := q.Enqueue(ctx, testJobType, nil, "")
+	second, _ := q.Enqueue(ctx, testJobType, nil, "")
+
+	id, err := q.claim(ctx, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != first.ID {
+		t.Errorf("claimed %s, want the oldest job %s", id, first.ID)
+	}
+	if got := q.list(t, processingKey); len(got) != 1 || got[0] != first.ID {
+		t.Errorf("processing holds %v, want [%s]", got, first.ID)
+	}
+	if got := q.list(t, queueKey); len(got) != 1 || got[0] != second.ID {
+		t.Errorf("queue holds %v, want [%s]", got, second.ID)
+	}
+	if beat := q.redis.HGet(heartbeatKey, first.ID); beat == "" {
+		t.Error("claimed job has no heartbeat")
+	}
+
+	q.process(ctx, id)
+	if got := q.list(t, processingKey); len(got) != 0 {
+		t.Errorf("processing still holds %v after the job finished", got)
+	}
+	if q.redis.HGet(heartbeatKey, first.ID) != "" {
+		t.Error("finished job kept its heartbeat")
+	}
+}
+
+func TestClaimEmptyQueue(t *testing.T) {
+	q := newTestQueue(t, testOptions())
+
+	if _, err := q.claim(context.Background(), 100*time.Millisecond); err != redis.Nil {
+		t.Errorf("claim on an empty queue returned %v, want redis.Nil", err)
+	}
+}
+
+func TestProcessReportsProgressAndResult(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+
+	var seen []models.Job
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		seen = append(seen, *q.job(t, job.ID))
+		progress(40, "Halfway")
+		seen = append(seen, *q.job(t, job.ID))
+		progress(150, "Almost")
+		seen = append(seen, *q.job(t, job.ID))
+		return map[string]int{"rows": 2}, nil
+	})
+
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+	q.runNext(t)
+
+	if seen[0].Status != models.JobRunning || seen[0].Attempts != 1 {
+		t.Errorf("running job is %s at attempt %d", seen[0].Status, seen[0].Attempts)
+	}
+	if seen[1].Progress != 40 || seen[1].ProgressMessage != "Halfway" {
+		t.Errorf("progress = %d %q, want 40 Halfway", seen[1].Progress, seen[1].ProgressMessage)
+	}
+	if seen[2].Progress != 100 {
+		t.Errorf("progress = %d, want it capped at 100", seen[2].Progress)
+	}
+
+	done := q.job(t, job.ID)
+	if done.Status != models.JobSucceeded || done.FinishedAt == nil {
+		t.Errorf("finished job is %s, finished at %v", done.Status, done.FinishedAt)
+	}
+	if string(done.Result) != `{"rows":2}` {
+		t.Errorf("result = %s", done.Result)
+	}
+}
+
+func TestFailedJobBacksOffExponentially(t *testing.T) {
+	ctx := context.Background()
+	opts := testOptions()
+	opts.MaxAttempts = 5
+	opts.MaxBackoff = 4 * time.Second
+	q := newTestQueue(t, opts)
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, errors.New("database is down")
+	})
+
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+
+	for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		q.runNext(t)
+
+		failed := q.job(t, job.ID)
+		if failed.Status != models.JobRetrying || failed.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d the job is %s with %d attempts", attempt+1, failed.Status, failed.Attempts)
+		}
+		if failed.Error != "database is down" {
+			t.Errorf("error = %q", failed.Error)
+		}
+		if got := failed.RunAt.Sub(q.current); got != backoff {
+			t.Errorf("attempt %d backs off %s, want %s", attempt+1, got, backoff)
+		}
+
+		// Not due yet.
+		q.promoteDelayed(ctx)
+		if got := q.list(t, queueKey); len(got) != 0 {
+			t.Fatalf("retry queued %s early", backoff)
+		}
+
+		q.advance(backoff)
+		q.promoteDelayed(ctx)
+		if got := q.list(t, queueKey); len(got) != 1 || got[0] != job.ID {
+			t.Fatalf("queue holds %v after the backoff, want [%s]", got, job.ID)
+		}
+		if members, _ := q.redis.ZMembers(delayedKey); len(members) != 0 {
+			t.Fatalf("delayed set still holds %v", members)
+		}
+	}
+
+	q.runNext(t)
+
+	dead := q.job(t, job.ID)
+	if dead.Status != models.JobDead || dead.Attempts != 5 || dead.FinishedAt == nil {
+		t.Errorf("after the last attempt the job is %s with %d attempts", dead.Status, dead.Attempts)
+	}
+	if got := q.list(t, deadKey); len(got) != 1 || got[0] != job.ID {
+		t.Errorf("dead list holds %v, want [%s]", got, job.ID)
+	}
+}
+
+func TestPermanentErrorGoesToDeadList(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, Permanent(errors.New("malformed file"))
+	})
+
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+	q.runNext(t)
+
+	dead := q.job(t, job.ID)
+	if dead.Status != models.JobDead || dead.Attempts != 1 || dead.Error != "malformed file" {
+		t.Errorf("job is %s after %d attempts with error %q", dead.Status, dead.Attempts, dead.Error)
+	}
+	if members, _ := q.redis.ZMembers(delayedKey); len(members) != 0 {
+		t.Errorf("permanent failure was scheduled for a retry")
+	}
+
+	letters, total, err := q.DeadLetters(ctx, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(letters) != 1 || letters[0].ID != job.ID {
+		t.Errorf("dead letters = %d %v", total, letters)
+	}
+
+	retried, err := q.Retry(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried.Status != models.JobQueued || retried.Attempts != 0 || retried.Error != "" {
+		t.Errorf("retried job is %s with %d attempts and error %q", retried.Status, retried.Attempts, retried.Error)
+	}
+	if got := q.list(t, deadKey); len(got) != 0 {
+		t.Errorf("dead list still holds %v", got)
+	}
+	if _, err := q.Retry(ctx, job.ID); err == nil || err.Error() != "job is not dead" {
+		t.Errorf("retrying a queued job returned %v", err)
+	}
+}
+
+func TestPanickingJobIsRetried(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		panic("nil map")
+	})
+
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+	q.runNext(t)
+
+	failed := q.job(t, job.ID)
+	if failed.Status != models.JobRetrying || failed.Error != "job panicked: nil map" {
+		t.Errorf("job is %s with error %q", failed.Status, failed.Error)
+	}
+}
+
+// crash claims the next job and leaves it as a worker that died while
+// running it would.
+func (q *testQueue) crash(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	id, err := q.claim(ctx, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := q.job(t, id)
+	job.Status = models.JobRunning
+	job.Attempts++
+	if err := q.save(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRequeueStaleCountsAnAttempt(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, nil
+	})
+
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		q.crash(t)
+
+		q.advance(time.Minute - time.Second)
+		q.requeueStale(ctx)
+		if got := q.list(t, processingKey); len(got) != 1 {
+			t.Fatalf("attempt %d was taken back while still heartbeating", attempt)
+		}
+
+		q.advance(time.Second)
+		q.requeueStale(ctx)
+		if got := q.list(t, processingKey); len(got) != 0 {
+			t.Fatalf("stale attempt %d is still processing", attempt)
+		}
+		if q.redis.HGet(heartbeatKey, job.ID) != "" {
+			t.Errorf("stale attempt %d kept its heartbeat", attempt)
+		}
+
+		stale := q.job(t, job.ID)
+		if stale.Attempts != attempt || stale.Error != "worker stopped heartbeating" {
+			t.Errorf("after stale attempt %d the job has %d attempts and error %q", attempt, stale.Attempts, stale.Error)
+		}
+		if attempt == 3 {
+			break
+		}
+		if stale.Status != models.JobRetrying {
+			t.Fatalf("after stale attempt %d the job is %s, want retrying", attempt, stale.Status)
+		}
+
+		q.advance(time.Minute)
+		q.promoteDelayed(ctx)
+	}
+
+	if dead := q.job(t, job.ID); dead.Status != models.JobDead {
+		t.Errorf("job that crashed its worker every time is %s, want dead", dead.Status)
+	}
+	if got := q.list(t, deadKey); len(got) != 1 {
+		t.Errorf("dead list holds %v", got)
+	}
+}
+
+func TestRequeueStaleJobThatNeverStarted(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, nil
+	})
+
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+	if _, err := q.claim(ctx, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	q.advance(time.Minute)
+	q.requeueStale(ctx)
+
+	requeued := q.job(t, job.ID)
+	if requeued.Status != models.JobQueued || requeued.Attempts != 0 {
+		t.Errorf("job is %s with %d attempts, want queued with none", requeued.Status, requeued.Attempts)
+	}
+	if got := q.list(t, queueKey); len(got) != 1 || got[0] != job.ID {
+		t.Errorf("queue holds %v, want [%s]", got, job.ID)
+	}
+}
+
+func TestRequeueStaleSparesFreshClaims(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, nil
+	})
+
+	// Waited in the backlog for longer than StaleAfter before it was
+	// claimed, which must not count against it.
+	backlogged, _ := q.Enqueue(ctx, testJobType, nil, "")
+	q.advance(10 * time.Minute)
+	q.crash(t)
+
+	// Claimed by a worker that has not stamped its heartbeat yet.
+	unstamped, _ := q.Enqueue(ctx, testJobType, nil, "")
+	if err := q.client.LMove(ctx, queueKey, processingKey, "RIGHT", "LEFT").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	q.requeueStale(ctx)
+	if got := q.list(t, processingKey); len(got) != 2 {
+		t.Fatalf("processing holds %v, want both jobs", got)
+	}
+	if q.redis.HGet(heartbeatKey, unstamped.ID) == "" {
+		t.Fatal("sweep did not start the clock on the unstamped job")
+	}
+
+	q.advance(time.Minute)
+	q.requeueStale(ctx)
+	if got := q.list(t, processingKey); len(got) != 0 {
+		t.Errorf("processing still holds %v once both went quiet", got)
+	}
+	if job := q.job(t, backlogged.ID); job.Status != models.JobRetrying {
+		t.Errorf("backlogged job is %s, want retrying", job.Status)
+	}
+	if job := q.job(t, unstamped.ID); job.Status != models.JobQueued {
+		t.Errorf("unstamped job is %s, want queued", job.Status)
+	}
+}
+
+func TestRegisteredHandlerMissing(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t, testOptions())
+	q.register(func(ctx context.Context, job *models.Job, progress ProgressFunc) (interface{}, error) {
+		return nil, nil
+	})
+	job, _ := q.Enqueue(ctx, testJobType, nil, "")
+
+	// A worker of an older release that does not know the job type.
+	delete(q.handlers, testJobType)
+	q.runNext(t)
+
+	dead := q.job(t, job.ID)
+	if dead.Status != models.JobDead {
+		t.Errorf("job of an unknown type is %s, want dead", dead.Status)
+	}
+	if dead.Error != "unknown job type: test.job" {
+		t.Errorf("error = %q", dead.Error)
+	}
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log line %q is not JSON: %v", buf, err)
+	}
+	return record
+}
+
+func TestRecordsCarryRequestAndTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "json", "info").With("component", "test")
+
+	span := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"), span)
+
+	logger.InfoContext(ctx, "Handled")
+	record := decodeRecord(t, &buf)
+
+	want := map[string]string{
+		"component":  "test",
+		"request_id": "req-1",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %s", key, record[key], value)
+		}
+	}
+}
+
+func TestRecordsWithoutContextOmitIDs(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, "json", "info").InfoContext(context.Background(), "Started")
+	record := decodeRecord(t, &buf)
+
+	for _, key := range []string{"request_id", "trace_id", "span_id"} {
+		if _, ok := record[key]; ok {
+			t.Errorf("record carries %s without one in its context", key)
+		}
+	}
+}
+
+func TestNewHonoursLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "json", "warn")
+
+	logger.Info("Dropped")
+	if buf.Len() != 0 {
+		t.Errorf("info line logged at warn level: %s", buf.String())
+	}
+	logger.Warn("Kept")
+	if buf.Len() == 0 {
+		t.Error("warn line dropped at warn level")
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"4bf92f3577b34da6", true},
+		{"gateway-1.req:42_a", true},
+		{"", false},
+		{"req\nlevel=ERROR msg=forged", false},
+		{"req id", false},
+		{string(bytes.Repeat([]byte("a"), 129)), false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidRequestID(tt.id); got != tt.valid {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", tt.id, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// openDryRun returns a GORM handle with GormPlugin registered that builds
+// statements without sending them, so no database is needed.
+func openDryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=none"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestGormPluginRecordsOperations(t *testing.T) {
+	db := openDryRun(t)
+
+	tests := []struct {
+		operation string
+		run       func(db *gorm.DB) error
+	}{
+		{"create", func(db *gorm.DB) error { return db.Create(&widget{Name: "a"}).Error }},
+		{"query", func(db *gorm.DB) error { return db.Find(&[]widget{}).Error }},
+		{"update", func(db *gorm.DB) error { return db.Model(&widget{ID: 1}).Update("name", "b").Error }},
+		{"delete", func(db *gorm.DB) error { return db.Delete(&widget{ID: 1}).Error }},
+		{"raw", func(db *gorm.DB) error { return db.Exec("UPDATE widgets SET name = 'c'").Error }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			table := "widgets"
+			if tt.operation == "raw" {
+				table = "unknown"
+			}
+			before := sampleCount(t, DBQueryDuration, tt.operation, table, "ok")
+
+			if err := tt.run(db); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := sampleCount(t, DBQueryDuration, tt.operation, table, "ok") - before; got != 1 {
+				t.Errorf("%s/%s observations grew by %d, want 1", tt.operation, table, got)
+			}
+		})
+	}
+}
+
+func TestGormPluginRecordsResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		result string
+	}{
+		{"failure", errors.New("connection reset"), "error"},
+		{"not found", gorm.ErrRecordNotFound, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openDryRun(t)
+			// Fail the query the way the driver would, before the plugin observes it.
+			err := db.Callback().Query().Before("metrics:after_query").Register("test:fail", func(db *gorm.DB) {
+				db.AddError(tt.err)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := sampleCount(t, DBQueryDuration, "query", "widgets", tt.result)
+
+			db.First(&widget{})
+
+			if got := sampleCount(t, DBQueryDuration, "query", "widgets", tt.result) - before; got != 1 {
+				t.Errorf("query/widgets/%s observations grew by %d, want 1", tt.result, got)
+			}
+		})
+	}
+}
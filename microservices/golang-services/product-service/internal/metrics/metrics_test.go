@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sampleCount returns how many observations the histogram with the given
+// labels has recorded.
+func sampleCount(t *testing.T, vec *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := vec.WithLabelValues(labels...).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestRecordS3Upload(t *testing.T) {
+	var m dto.Metric
+	counter := S3Uploads.WithLabelValues("fallback")
+	if err := counter.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	before := m.GetCounter().GetValue()
+
+	RecordS3Upload("fallback")
+
+	if err := counter.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetCounter().GetValue() - before; got != 1 {
+		t.Errorf("fallback uploads grew by %v, want 1", got)
+	}
+}
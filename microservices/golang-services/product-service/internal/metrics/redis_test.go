@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisHookRecordsCommands(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		err    error
+		result string
+	}{
+		{"success", nil, "ok"},
+		{"missing key", redis.Nil, "ok"},
+		{"failure", errors.New("connection refused"), "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := sampleCount(t, RedisCommandDuration, "get", tt.result)
+
+			process := RedisHook{}.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+				return tt.err
+			})
+			if err := process(ctx, redis.NewStringCmd(ctx, "get", "key")); err != tt.err {
+				t.Errorf("hook returned %v, want %v", err, tt.err)
+			}
+
+			if got := sampleCount(t, RedisCommandDuration, "get", tt.result) - before; got != 1 {
+				t.Errorf("get/%s observations grew by %d, want 1", tt.result, got)
+			}
+		})
+	}
+}
+
+func TestRedisHookRecordsPipelinesOnce(t *testing.T) {
+	ctx := context.Background()
+	before := sampleCount(t, RedisCommandDuration, "pipeline", "ok")
+
+	calls := 0
+	process := RedisHook{}.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+		calls++
+		return nil
+	})
+	cmds := []redis.Cmder{redis.NewStringCmd(ctx, "get", "a"), redis.NewStringCmd(ctx, "get", "b")}
+	if err := process(ctx, cmds); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Errorf("pipeline ran %d times, want 1", calls)
+	}
+	if got := sampleCount(t, RedisCommandDuration, "pipeline", "ok") - before; got != 1 {
+		t.Errorf("pipeline observations grew by %d, want 1", got)
+	}
+}
@@ -4,107 +4,190 @@ import (
 	"context"
 	"encoding/json"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"product-service/config"
+	"product-service/internal/audit"
+	"product-service/internal/logging"
+	"product-service/internal/metrics"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type UserClaims struct {
+	ID    string `json:"id"`
 	Email string `json:"email"`
 	Role  string `json:"role"`
 }
 
-func AuthMiddleware() gin.HandlerFunc {
+// Claims is the principal stored under "claims" by AuthMiddleware; read it
+// with GetClaims.
+type Claims struct {
+	UserClaims
+	Permissions []string
+}
+
+// AuthError is an authentication failure with the HTTP status it maps to.
+type AuthError struct {
+	Status  int
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+// Authenticator resolves a bearer authorization header into the user and the
+// permissions of their role. It is shared by the HTTP middleware and the gRPC
+// interceptor.
+type Authenticator interface {
+	Authenticate(ctx context.Context, authHeader string) (Claims, *AuthError)
+}
+
+type authenticatorImpl struct {
+	accessURL string
+	redis     *redis.Client
+	client    *http.Client
+}
+
+// NewAuthenticator returns an Authenticator that asks the user service at
+// accessURL who the token belongs to and reads the permissions of their
+// role from the role cache in Redis.
+func NewAuthenticator(accessURL string, redisClient *redis.Client) Authenticator {
+	return &authenticatorImpl{
+		accessURL: accessURL,
+		redis:     redisClient,
+		// Traces calls to the auth service and passes the trace context on.
+		client: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}
+}
+
+func AuthMiddleware(auth Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.Method == "OPTIONS" {
 			c.Next()
 			return
 		}
 
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid token"})
+		claims, err := auth.Authenticate(c.Request.Context(), c.GetHeader("Authorization"))
+		if err != nil {
+			c.AbortWithStatusJSON(err.Status, gin.H{"error": err.Message})
 			return
 		}
 
-		accessURL := os.Getenv("USER_AUTH_ACCESS_URL")
-		if accessURL == "" {
-			log.Println("[AuthMiddleware] USER_AUTH_ACCESS_URL not configured")
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "USER_AUTH_ACCESS_URL not configured"})
-			return
-		}
+		c.Set("claims", claims)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			ID:        claims.ID,
+			Email:     claims.Email,
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+		c.Next()
+	}
+}
 
-		req, err := http.NewRequestWithContext(context.Background(), "GET", accessURL, nil)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create auth request"})
-			return
-		}
-		req.Header.Set("Authorization", authHeader)
+func (a *authenticatorImpl) Authenticate(ctx context.Context, authHeader string) (Claims, *AuthError) {
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return authFailure("missing_token", http.StatusUnauthorized, "Missing or invalid token")
+	}
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("[AuthMiddleware] Auth request error: %v\n", err)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-		defer resp.Body.Close()
+	logger := logging.FromContext(ctx).With("component", "auth")
 
-		if resp.StatusCode != http.StatusOK {
-			log.Printf("[AuthMiddleware] Auth service responded with status: %d\n", resp.StatusCode)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
+	if a.accessURL == "" {
+		logger.ErrorContext(ctx, "USER_AUTH_ACCESS_URL not configured")
+		return authFailure("not_configured", http.StatusInternalServerError, "USER_AUTH_ACCESS_URL not configured")
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("[AuthMiddleware] Failed to read auth response: %v\n", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to read auth response"})
-			return
-		}
+	req, err := http.NewRequestWithContext(ctx, "GET", a.accessURL, nil)
+	if err != nil {
+		return authFailure("request_error", http.StatusInternalServerError, "Failed to create auth request")
+	}
+	req.Header.Set("Authorization", authHeader)
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
 
-		var respData struct {
-			User UserClaims `json:"user"`
-		}
+	start := time.Now()
+	resp, err := a.client.Do(req)
+	if err != nil {
+		metrics.AuthRequestDuration.WithLabelValues("error").Observe(time.Since(start).Seconds())
+		logger.WarnContext(ctx, "Auth request failed", "error", err)
+		return authFailure("request_error", http.StatusUnauthorized, "Unauthorized")
+	}
+	defer resp.Body.Close()
 
-		if err := json.Unmarshal(body, &respData); err != nil {
-			log.Printf("[AuthMiddleware] Invalid auth response: %v\n", err)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid auth response"})
-			return
-		}
+	if resp.StatusCode != http.StatusOK {
+		metrics.AuthRequestDuration.WithLabelValues("rejected").Observe(time.Since(start).Seconds())
+		logger.WarnContext(ctx, "Auth service rejected token", "status", resp.StatusCode)
+		return authFailure("rejected", http.StatusUnauthorized, "Unauthorized")
+	}
 
-		ctx := context.Background()
-		redisKey := "laravel_database_role:" + respData.User.Role
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		metrics.AuthRequestDuration.WithLabelValues("error").Observe(time.Since(start).Seconds())
+		logger.ErrorContext(ctx, "Failed to read auth response", "error", err)
+		return authFailure("request_error", http.StatusInternalServerError, "Failed to read auth response")
+	}
+	metrics.AuthRequestDuration.WithLabelValues("ok").Observe(time.Since(start).Seconds())
 
-		log.Printf("[AuthMiddleware] Fetching permissions from Redis key: %s\n", redisKey)
+	var respData struct {
+		User UserClaims `json:"user"`
+	}
 
-		permJson, err := config.Client.Get(ctx, redisKey).Result()
-		if err != nil {
-			log.Printf("[AuthMiddleware] Redis get error: %v\n", err)
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Role not found or Redis error"})
-			return
-		}
+	if err := json.Unmarshal(body, &respData); err != nil {
+		logger.WarnContext(ctx, "Invalid auth response", "error", err)
+		return authFailure("invalid_response", http.StatusUnauthorized, "Invalid auth response")
+	}
 
-		var permissions []string
-		if err := json.Unmarshal([]byte(permJson), &permissions); err != nil {
-			log.Printf("[AuthMiddleware] Failed to parse permissions JSON: %v\n", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse permissions from Redis"})
-			return
-		}
+	redisKey := "laravel_database_role:" + respData.User.Role
 
-		claims := struct {
-			UserClaims
-			Permissions []string
-		}{
-			UserClaims:  respData.User,
-			Permissions: permissions,
-		}
+	logger.DebugContext(ctx, "Fetching role permissions", "key", redisKey)
 
-		c.Set("claims", claims)
-		c.Next()
+	permJson, err := a.redis.Get(ctx, redisKey).Result()
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to load role permissions", "key", redisKey, "error", err)
+		return authFailure("role_not_found", http.StatusForbidden, "Role not found or Redis error")
+	}
+
+	var permissions []string
+	if err := json.Unmarshal([]byte(permJson), &permissions); err != nil {
+		logger.ErrorContext(ctx, "Failed to parse role permissions", "key", redisKey, "error", err)
+		return authFailure("invalid_permissions", http.StatusInternalServerError, "Failed to parse permissions from Redis")
+	}
+
+	return Claims{
+		UserClaims:  respData.User,
+		Permissions: permissions,
+	}, nil
+}
+
+// authFailure counts a failed authentication under reason and returns the
+// error reported to the caller.
+func authFailure(reason string, status int, message string) (Claims, *AuthError) {
+	metrics.AuthFailures.WithLabelValues(reason).Inc()
+	return Claims{}, &AuthError{status, message}
+}
+
+// GetClaims returns the principal stored by AuthMiddleware, if any. The
+// other claims helpers are built on it.
+func GetClaims(c *gin.Context) (Claims, bool) {
+	claimsRaw, exists := c.Get("claims")
+	if !exists {
+		return Claims{}, false
 	}
+
+	claims, ok := claimsRaw.(Claims)
+	return claims, ok
+}
+
+func GetUserEmail(c *gin.Context) string {
+	claims, _ := GetClaims(c)
+	return claims.Email
+}
+
+func GetUserID(c *gin.Context) string {
+	claims, _ := GetClaims(c)
+	return claims.ID
 }
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"product-service/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLoggerOmitsSensitiveFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	g := gin.New()
+	g.Use(RequestID(), RequestLogger(logging.New(&buf, "json", "info")))
+	g.POST("/products/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/products/7?access_token=query-secret", strings.NewReader(`{"password":"body-secret"}`))
+	req.Header.Set("Authorization", "Bearer header-secret")
+	req.Header.Set("Cookie", "session=cookie-secret")
+	req.Header.Set(RequestIDHeader, "req-42")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+
+	line := buf.String()
+	for _, secret := range []string{"query-secret", "body-secret", "header-secret", "cookie-secret"} {
+		if strings.Contains(line, secret) {
+			t.Errorf("request log leaks %s: %s", secret, line)
+		}
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"request_id": "req-42",
+		"route":      "/products/:id",
+		"path":       "/products/7",
+		"status":     float64(http.StatusNoContent),
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "req-42" {
+		t.Errorf("response %s = %q, want req-42", RequestIDHeader, got)
+	}
+}
+
+func TestRequestIDReplacesMalformedIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	g := gin.New()
+	g.Use(RequestID(), RequestLogger(logging.New(&buf, "json", "info")))
+	g.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, "forged id")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(RequestIDHeader)
+	if id == "" || id == "forged id" || !logging.ValidRequestID(id) {
+		t.Fatalf("response %s = %q, want a generated ID", RequestIDHeader, id)
+	}
+	if !strings.Contains(buf.String(), `"request_id":"`+id+`"`) {
+		t.Errorf("log line does not carry the generated ID %s: %s", id, buf.String())
+	}
+}
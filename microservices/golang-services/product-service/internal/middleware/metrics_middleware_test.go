@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"product-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func requestCount(t *testing.T, method, route, status string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	observer := metrics.HTTPRequestDuration.WithLabelValues(method, route, status)
+	if err := observer.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMetricsLabelsRequestsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(Metrics())
+	g.GET("/widgets/:id", func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		path   string
+		route  string
+		status string
+	}{
+		{"/widgets/1", "/widgets/:id", "200"},
+		{"/widgets/2", "/widgets/:id", "200"},
+		{"/widgets/0", "/widgets/:id", "404"},
+		{"/wp-login.php", "unmatched", "404"},
+	}
+
+	want := map[[2]string]uint64{}
+	before := map[[2]string]uint64{}
+	for _, tt := range tests {
+		key := [2]string{tt.route, tt.status}
+		if _, ok := before[key]; !ok {
+			before[key] = requestCount(t, http.MethodGet, tt.route, tt.status)
+		}
+		want[key]++
+	}
+
+	for _, tt := range tests {
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+	}
+
+	for key, n := range want {
+		if got := requestCount(t, http.MethodGet, key[0], key[1]) - before[key]; got != n {
+			t.Errorf("GET %s %s: %d observations, want %d", key[0], key[1], got, n)
+		}
+	}
+}
@@ -0,0 +1,524 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"product-service/internal/models"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+type OpenAPIOptions struct {
+	// ValidateResponses also checks every response against the spec and
+	// replaces mismatching ones with a 500. Responses are buffered to do
+	// so, which makes it suitable for tests and staging only.
+	ValidateResponses bool
+}
+
+var (
+	ginPathParam        = regexp.MustCompile(`[:*](\w+)`)
+	unsupportedProperty = regexp.MustCompile(`property "([^"]+)" is unsupported`)
+)
+
+// fieldInitialisms keeps field names in validation errors aligned with the
+// Go struct fields that ParseValidationErrors reports.
+var fieldInitialisms = map[string]string{
+	"id":  "ID",
+	"ids": "IDs",
+	"sku": "SKU",
+	"url": "URL",
+}
+
+func init() {
+	openapi3filter.RegisterBodyDecoder("application/x-www-form-urlencoded", urlencodedBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("multipart/form-data", multipartBodyDecoder)
+}
+
+// OpenAPIValidator checks path and query parameters and request bodies
+// against the operation spec documents for the matched route. Invalid
+// requests are rejected with the same "Validation failed" response and
+// field map as binding errors. Routes missing from the spec pass through,
+// and authentication is left to AuthMiddleware.
+func OpenAPIValidator(spec []byte, opts OpenAPIOptions) (gin.HandlerFunc, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	operations := map[string]*routers.Route{}
+	for path, item := range doc.Paths.Map() {
+		for method, operation := range item.Operations() {
+			operations[method+" "+path] = &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			}
+		}
+	}
+
+	filterOpts := &openapi3filter.Options{
+		MultiError:            true,
+		SkipSettingDefaults:   true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		route, ok := operations[c.Request.Method+" "+ginPathParam.ReplaceAllString(c.FullPath(), "{$1}")]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		pathParams := map[string]string{}
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    filterOpts,
+		}
+
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			if isUnsupportedContentType(err) {
+				c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, models.ErrorResponse(http.StatusUnsupportedMediaType, "Unsupported content type", "Use one of: "+strings.Join(supportedContentTypes(route), ", ")))
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Validation failed", requestValidationErrors(err)))
+			return
+		}
+
+		if !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		err := openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.status,
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(writer.body.Bytes())),
+			Options:                filterOpts,
+		})
+		if err != nil {
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Response does not match the API specification", responseValidationErrors(err)))
+			return
+		}
+
+		c.Writer.WriteHeader(writer.status)
+		c.Writer.Write(writer.body.Bytes())
+	}, nil
+}
+
+// urlencodedBodyDecoder decodes form fields by the type of their property.
+// It replaces the stock decoder, which reports absent fields as null and
+// drops values it cannot parse. Unparsable values are kept as strings so
+// the schema reports them. Empty values are left out, as form binding
+// treats them as unset.
+func urlencodedBodyDecoder(body io.Reader, _ http.Header, schema *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	obj := map[string]any{}
+	for name, raw := range values {
+		prop := schema.Value.Properties[name]
+		if prop != nil && prop.Value != nil && prop.Value.Type.Is(openapi3.TypeArray) {
+			items := make([]any, 0, len(raw))
+			for _, value := range raw {
+				items = append(items, formValue(value, prop.Value.Items))
+			}
+			obj[name] = items
+			continue
+		}
+
+		if raw[0] != "" {
+			obj[name] = formValue(raw[0], prop)
+		}
+	}
+
+	return obj, nil
+}
+
+// multipartBodyDecoder leaves out empty fields for the same reason.
+func multipartBodyDecoder(body io.Reader, header http.Header, schema *openapi3.SchemaRef, encFn openapi3filter.EncodingFn) (any, error) {
+	value, err := openapi3filter.MultipartBodyDecoder(body, header, schema, encFn)
+	if obj, ok := value.(map[string]any); ok {
+		for name, field := range obj {
+			if field == nil || field == "" {
+				delete(obj, name)
+			}
+		}
+	}
+	return value, err
+}
+
+func formValue(value string, schema *openapi3.SchemaRef) any {
+	if schema == nil || schema.Value == nil {
+		return value
+	}
+
+	switch {
+	case schema.Value.Type.Is(openapi3.TypeInteger):
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case schema.Value.Type.Is(openapi3.TypeNumber):
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case schema.Value.Type.Is(openapi3.TypeBoolean):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func isUnsupportedContentType(err error) bool {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) || requestErr.RequestBody == nil {
+		return false
+	}
+	return requestErr.Err == nil && strings.HasPrefix(requestErr.Reason, "header Content-Type has unexpected value")
+}
+
+func supportedContentTypes(route *routers.Route) []string {
+	if route.Operation.RequestBody == nil || route.Operation.RequestBody.Value == nil {
+		return nil
+	}
+	types := make([]string, 0, len(route.Operation.RequestBody.Value.Content))
+	for contentType := range route.Operation.RequestBody.Value.Content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// requestValidationErrors flattens the errors of a rejected request into
+// messages per field.
+func requestValidationErrors(err error) map[string][]string {
+	res := map[string][]string{}
+
+	var add func(err error)
+	add = func(err error) {
+		// errors.As would match a MultiError by its first element and a
+		// RequestError by its cause, so split the list by type first.
+		if multi, ok := err.(openapi3.MultiError); ok {
+			for _, e := range multi {
+				add(e)
+			}
+			return
+		}
+
+		var requestErr *openapi3filter.RequestError
+		if !errors.As(err, &requestErr) {
+			addMessage(res, "error", err.Error())
+			return
+		}
+
+		if requestErr.Parameter != nil {
+			field := fieldName(requestErr.Parameter.Name)
+			var schema *openapi3.Schema
+			if requestErr.Parameter.Schema != nil {
+				schema = requestErr.Parameter.Schema.Value
+			}
+			addCause(res, field, schema, requestErr.Err)
+			return
+		}
+
+		if errors.Is(requestErr.Err, openapi3filter.ErrInvalidRequired) {
+			addMessage(res, "error", "The request body is required.")
+			return
+		}
+
+		if requestErr.Err == nil {
+			addMessage(res, "error", requestErr.Reason)
+			return
+		}
+
+		addCause(res, "", nil, requestErr.Err)
+	}
+	add(err)
+
+	return res
+}
+
+// addCause records why the value of field, or of the request body when
+// field is empty, was rejected.
+func addCause(res map[string][]string, field string, schema *openapi3.Schema, err error) {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		for _, e := range multi {
+			addCause(res, field, schema, e)
+		}
+		return
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if match := unsupportedProperty.FindStringSubmatch(schemaErr.Reason); match != nil {
+			addMessage(res, match[1], "The "+match[1]+" field is not allowed.")
+			return
+		}
+
+		name := field
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			name = fieldName(lastProperty(pointer, field))
+		}
+		if name == "" {
+			name = "error"
+		}
+		addMessage(res, name, schemaErrorMessage(name, schemaErr))
+		return
+	}
+
+	var parseErr *openapi3filter.ParseError
+	if errors.As(err, &parseErr) {
+		if path := parseErr.Path(); len(path) > 0 {
+			if key, ok := path[len(path)-1].(string); ok {
+				field = fieldName(key)
+			}
+		}
+		if field == "" {
+			addMessage(res, "error", parseErr.Error())
+			return
+		}
+		if schema != nil && schema.Type != nil && len(schema.Type.Slice()) == 1 {
+			addMessage(res, field, "The "+field+" must be "+typeName(schema.Type.Slice()[0])+".")
+			return
+		}
+		// Form parts carry the expected type in the innermost reason,
+		// e.g. "an invalid number".
+		for cause := parseErr; cause != nil; cause, _ = cause.Cause.(*openapi3filter.ParseError) {
+			if schemaType, ok := strings.CutPrefix(cause.Reason, "an invalid "); ok {
+				addMessage(res, field, "The "+field+" must be "+typeName(schemaType)+".")
+				return
+			}
+		}
+		addMessage(res, field, "The "+field+" is invalid.")
+		return
+	}
+
+	if field == "" {
+		field = "error"
+	}
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		addMessage(res, field, "The "+field+" field is required.")
+		return
+	}
+	addMessage(res, field, "The "+field+" is invalid.")
+}
+
+// addMessage appends msg to the errors of field unless it is already there,
+// as one broken rule can be reported by several schema keywords.
+func addMessage(res map[string][]string, field, msg string) {
+	for _, existing := range res[field] {
+		if existing == msg {
+			return
+		}
+	}
+	res[field] = append(res[field], msg)
+}
+
+func schemaErrorMessage(field string, err *openapi3.SchemaError) string {
+	schema := err.Schema
+	switch err.SchemaField {
+	case "required":
+		return "The " + field + " field is required."
+	case "type":
+		if schema.Type != nil && len(schema.Type.Slice()) == 1 {
+			return "The " + field + " must be " + typeName(schema.Type.Slice()[0]) + "."
+		}
+	case "nullable":
+		return "The " + field + " must not be null."
+	case "enum":
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			values = append(values, fmt.Sprint(value))
+		}
+		return "The " + field + " must be one of: " + strings.Join(values, ", ") + "."
+	case "minLength":
+		return fmt.Sprintf("The %s must be at least %d characters.", field, schema.MinLength)
+	case "maxLength":
+		return fmt.Sprintf("The %s must be at most %d characters.", field, *schema.MaxLength)
+	case "minItems":
+		return fmt.Sprintf("The %s must contain at least %d item(s).", field, schema.MinItems)
+	case "maxItems":
+		return fmt.Sprintf("The %s must contain at most %d item(s).", field, *schema.MaxItems)
+	case "minimum", "exclusiveMinimum":
+		if schema.ExclusiveMin {
+			return fmt.Sprintf("The %s must be greater than %v.", field, *schema.Min)
+		}
+		return fmt.Sprintf("The %s must be greater than or equal to %v.", field, *schema.Min)
+	case "maximum", "exclusiveMaximum":
+		if schema.ExclusiveMax {
+			return fmt.Sprintf("The %s must be less than %v.", field, *schema.Max)
+		}
+		return fmt.Sprintf("The %s must be less than or equal to %v.", field, *schema.Max)
+	case "format":
+		switch schema.Format {
+		case "uri":
+			return "The " + field + " must be a valid URL."
+		case "date-time":
+			return "The " + field + " must be a valid RFC 3339 date-time."
+		}
+		return "The " + field + " format is invalid."
+	case "pattern":
+		return "The " + field + " format is invalid."
+	}
+	return "The " + field + " is invalid."
+}
+
+// responseValidationErrors lists mismatches of a response by JSON path, to
+// point at the part of the handler or spec that drifted.
+func responseValidationErrors(err error) map[string][]string {
+	res := map[string][]string{}
+
+	var add func(err error)
+	add = func(err error) {
+		if multi, ok := err.(openapi3.MultiError); ok {
+			for _, e := range multi {
+				add(e)
+			}
+			return
+		}
+
+		var schemaErr *openapi3.SchemaError
+		if errors.As(err, &schemaErr) {
+			path := "body"
+			if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+				path = strings.Join(pointer, ".")
+			}
+			res[path] = append(res[path], schemaErr.Reason)
+			return
+		}
+
+		var responseErr *openapi3filter.ResponseError
+		if errors.As(err, &responseErr) && responseErr.Err == nil {
+			res["error"] = append(res["error"], responseErr.Reason)
+			return
+		}
+
+		res["error"] = append(res["error"], err.Error())
+	}
+	add(err)
+
+	return res
+}
+
+// lastProperty returns the innermost object property in pointer, skipping
+// array indexes, or fallback when there is none.
+func lastProperty(pointer []string, fallback string) string {
+	for i := len(pointer) - 1; i >= 0; i-- {
+		if strings.Trim(pointer[i], "0123456789") != "" {
+			return pointer[i]
+		}
+	}
+	return fallback
+}
+
+// fieldName converts a JSON or query name such as publish_at into the Go
+// field name PublishAt.
+func fieldName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if initialism, ok := fieldInitialisms[part]; ok {
+			parts[i] = initialism
+		} else if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func typeName(schemaType string) string {
+	switch schemaType {
+	case openapi3.TypeInteger:
+		return "an integer"
+	case openapi3.TypeArray:
+		return "an array"
+	case openapi3.TypeObject:
+		return "an object"
+	default:
+		return "a " + schemaType
+	}
+}
+
+// bufferedResponseWriter holds the response back until it has been
+// validated.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+	wrote  bool
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	w.wrote = true
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.wrote = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.wrote = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	if !w.wrote {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.wrote
+}
+
+func (w *bufferedResponseWriter) Flush() {}
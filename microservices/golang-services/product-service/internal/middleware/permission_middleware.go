@@ -7,59 +7,39 @@ import (
 )
 
 func RequirePermission(permission string) gin.HandlerFunc {
+	return RequireAnyPermission(permission)
+}
+
+func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claimsRaw, exists := c.Get("claims")
-		if !exists {
+		claims, ok := GetClaims(c)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "No claims found"})
 			return
 		}
 
-		claims, ok := claimsRaw.(struct {
-			UserClaims
-			Permissions []string
-		})
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid claims type"})
+		if !HasAnyPermission(claims, permissions...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 			return
 		}
 
-		for _, p := range claims.Permissions {
-			if p == permission {
-				c.Next()
-				return
-			}
-		}
-
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		c.Next()
 	}
 }
 
-func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		claimsRaw, exists := c.Get("claims")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "No claims found"})
-			return
-		}
-
-		claims, ok := claimsRaw.(struct {
-			UserClaims
-			Permissions []string
-		})
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid claims type"})
-			return
-		}
+func HasPermission(c *gin.Context, permission string) bool {
+	claims, ok := GetClaims(c)
+	return ok && HasAnyPermission(claims, permission)
+}
 
-		for _, needed := range permissions {
-			for _, owned := range claims.Permissions {
-				if needed == owned {
-					c.Next()
-					return
-				}
+// HasAnyPermission reports whether claims grant at least one of permissions.
+func HasAnyPermission(claims Claims, permissions ...string) bool {
+	for _, needed := range permissions {
+		for _, owned := range claims.Permissions {
+			if needed == owned {
+				return true
 			}
 		}
-
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
 	}
+	return false
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func withClaims(claims *Claims) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if claims != nil {
+		c.Set("claims", *claims)
+	}
+	return c, w
+}
+
+func TestClaimsHelpers(t *testing.T) {
+	claims := &Claims{
+		UserClaims:  UserClaims{ID: "7", Email: "editor@example.com"},
+		Permissions: []string{"update_products"},
+	}
+
+	c, _ := withClaims(claims)
+	if got := GetUserID(c); got != "7" {
+		t.Errorf("GetUserID = %q, want 7", got)
+	}
+	if got := GetUserEmail(c); got != "editor@example.com" {
+		t.Errorf("GetUserEmail = %q, want editor@example.com", got)
+	}
+	if !HasPermission(c, "update_products") || HasPermission(c, "delete_products") {
+		t.Error("HasPermission does not match the claimed permissions")
+	}
+
+	c, _ = withClaims(nil)
+	if GetUserID(c) != "" || GetUserEmail(c) != "" || HasPermission(c, "update_products") {
+		t.Error("helpers report a principal without claims")
+	}
+}
+
+func TestRequireAnyPermission(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims *Claims
+		status int
+	}{
+		{name: "no claims", claims: nil, status: http.StatusForbidden},
+		{name: "missing permission", claims: &Claims{Permissions: []string{"view_active_products"}}, status: http.StatusForbidden},
+		{name: "one of the permissions", claims: &Claims{Permissions: []string{"view_all_products"}}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		c, w := withClaims(tt.claims)
+		RequireAnyPermission("view_all_products", "export_products")(c)
+		if !c.IsAborted() {
+			c.Status(http.StatusOK)
+		}
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"product-service/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadSession gives each request its own read session, so its reads move
+// from the replicas to the primary as soon as it has written anything.
+func ReadSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(config.WithReadSession(c.Request.Context()))
+		c.Next()
+	}
+}
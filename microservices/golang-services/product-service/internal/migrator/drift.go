@@ -0,0 +1,84 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"product-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Models are the GORM models stored in PostgreSQL.
+var Models = []any{
+	&models.Product{},
+	&models.ProductRevision{},
+	&models.ProductChangeRequest{},
+	&models.OutboxEvent{},
+	&models.WebhookSubscription{},
+	&models.WebhookDelivery{},
+}
+
+// Drift compares the tables and columns of the current schema with the GORM
+// models and describes every difference: a missing table, a field without a
+// column, or a column no field maps. An empty result means they agree.
+func Drift(ctx context.Context, db *gorm.DB, models ...any) ([]string, error) {
+	var rows []struct {
+		TableName  string
+		ColumnName string
+	}
+	err := db.WithContext(ctx).Raw(`
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = CURRENT_SCHEMA()`).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	schema := map[string]map[string]bool{}
+	for _, row := range rows {
+		if schema[row.TableName] == nil {
+			schema[row.TableName] = map[string]bool{}
+		}
+		schema[row.TableName][row.ColumnName] = true
+	}
+
+	var drift []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+
+		columns, ok := schema[table]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("table %s for %s does not exist", table, stmt.Schema.Name))
+			continue
+		}
+
+		mapped := map[string]bool{}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			mapped[field.DBName] = true
+			if !columns[field.DBName] {
+				drift = append(drift, fmt.Sprintf("column %s.%s for %s.%s does not exist", table, field.DBName, stmt.Schema.Name, field.Name))
+			}
+		}
+
+		var unmapped []string
+		for column := range columns {
+			if !mapped[column] {
+				unmapped = append(unmapped, column)
+			}
+		}
+		sort.Strings(unmapped)
+		for _, column := range unmapped {
+			drift = append(drift, fmt.Sprintf("column %s.%s is not mapped by %s", table, column, stmt.Schema.Name))
+		}
+	}
+	return drift, nil
+}
@@ -0,0 +1,207 @@
+// Package migrator applies the SQL migrations embedded in the binary and
+// checks that the resulting schema still matches the GORM models.
+package migrator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"product-service/migration"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator runs the embedded migrations against one database. Every
+// operation holds a PostgreSQL advisory lock, so replicas migrating at the
+// same time take turns instead of racing; the ones that get the lock last
+// find nothing left to apply.
+type Migrator interface {
+	// Up applies every pending migration.
+	Up() error
+	// Down rolls back the last steps migrations, or all of them when steps
+	// is not positive.
+	Down(steps int) error
+	// Force records version as applied and clean without running anything,
+	// to recover from a migration that failed halfway.
+	Force(version int) error
+	Status() (Status, error)
+	Close() error
+}
+
+// Status is the schema version of the database and the state of every
+// embedded migration.
+type Status struct {
+	Version    uint
+	Dirty      bool
+	Migrations []Migration
+}
+
+// Pending counts the migrations that are not applied yet.
+func (s Status) Pending() int {
+	pending := 0
+	for _, m := range s.Migrations {
+		if !m.Applied {
+			pending++
+		}
+	}
+	return pending
+}
+
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+type migratorImpl struct {
+	m *migrate.Migrate
+}
+
+// New connects to the database at dsn with a connection of its own, which
+// Close releases. lockTimeout bounds the wait for another replica's
+// migration to finish.
+func New(dsn string, lockTimeout time.Duration) (Migrator, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := pgx.WithInstance(db, &pgx.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	files, err := iofs.New(migration.Files, ".")
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", files, "pgx", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m.LockTimeout = lockTimeout
+
+	return &migratorImpl{m: m}, nil
+}
+
+func (r *migratorImpl) Up() error {
+	return ignoreNoChange(r.m.Up())
+}
+
+func (r *migratorImpl) Down(steps int) error {
+	if steps <= 0 {
+		return ignoreNoChange(r.m.Down())
+	}
+	return ignoreNoChange(r.m.Steps(-steps))
+}
+
+func (r *migratorImpl) Force(version int) error {
+	return r.m.Force(version)
+}
+
+func (r *migratorImpl) Status() (Status, error) {
+	version, dirty, err := r.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+
+	migrations, err := Embedded()
+	if err != nil {
+		return Status{}, err
+	}
+	return newStatus(migrations, version, dirty), nil
+}
+
+// newStatus marks the migrations up to version as applied.
+func newStatus(migrations []Migration, version uint, dirty bool) Status {
+	for i := range migrations {
+		// A dirty version failed halfway, so it does not count as applied.
+		migrations[i].Applied = migrations[i].Version < version || (migrations[i].Version == version && !dirty)
+	}
+	return Status{Version: version, Dirty: dirty, Migrations: migrations}
+}
+
+func (r *migratorImpl) Close() error {
+	sourceErr, dbErr := r.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Embedded lists the migrations compiled into the binary, oldest first.
+func Embedded() ([]Migration, error) {
+	return list(migration.Files)
+}
+
+func list(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		m, err := source.DefaultParse(entry.Name())
+		if err != nil || m.Direction != source.Up {
+			continue
+		}
+		migrations = append(migrations, Migration{Version: m.Version, Name: m.Identifier})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create writes an empty up and down migration named name to dir, numbered
+// after the newest migration already there, and returns their paths. The
+// new files are embedded on the next build.
+func Create(dir, name string) (up, down string, err error) {
+	if !migrationName.MatchString(name) {
+		return "", "", fmt.Errorf("migration name %q must be lowercase letters, digits and underscores", name)
+	}
+
+	existing, err := list(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version uint = 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	up, down = base+".up.sql", base+".down.sql"
+	for _, path := range []string{up, down} {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return "", "", err
+		}
+		if err := file.Close(); err != nil {
+			return "", "", err
+		}
+	}
+	return up, down, nil
+}
@@ -0,0 +1,151 @@
+package migrator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"product-service/migration"
+)
+
+func TestListReadsUpMigrationsInOrder(t *testing.T) {
+	files := fstest.MapFS{
+		"000010_add_webhooks.up.sql":      {},
+		"000010_add_webhooks.down.sql":    {},
+		"000002_add_revisions.up.sql":     {},
+		"000002_add_revisions.down.sql":   {},
+		"000001_create_products.up.sql":   {},
+		"000001_create_products.down.sql": {},
+		"README.md":                       {},
+		"notes.sql":                       {},
+	}
+
+	got, err := list(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "create_products"},
+		{Version: 2, Name: "add_revisions"},
+		{Version: 10, Name: "add_webhooks"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %+v, want %+v", got, want)
+	}
+}
+
+func TestEmbeddedMigrationsAreConsecutiveAndReversible(t *testing.T) {
+	migrations, err := Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != uint(i+1) {
+			t.Errorf("migration %d has version %d; versions must be consecutive", i, m.Version)
+		}
+		down := fmt.Sprintf("%06d_%s.down.sql", m.Version, m.Name)
+		if _, err := fs.Stat(migration.Files, down); err != nil {
+			t.Errorf("%s is missing", down)
+		}
+	}
+}
+
+func TestCreateNumbersAfterTheNewestMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"000001_create_products.up.sql", "000001_create_products.down.sql", "000007_add_outbox.up.sql", "000007_add_outbox.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := Create(dir, "add_tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(dir, "000008_add_tags.up.sql"); up != want {
+		t.Errorf("up = %s, want %s", up, want)
+	}
+	if want := filepath.Join(dir, "000008_add_tags.down.sql"); down != want {
+		t.Errorf("down = %s, want %s", down, want)
+	}
+	for _, path := range []string{up, down} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was not created: %v", path, err)
+		}
+	}
+}
+
+func TestCreateStartsAtOne(t *testing.T) {
+	up, _, err := Create(t.TempDir(), "create_products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filepath.Base(up); got != "000001_create_products.up.sql" {
+		t.Errorf("up = %s, want 000001_create_products.up.sql", got)
+	}
+}
+
+func TestCreateRejectsInvalidNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"", "AddTags", "add-tags", "add tags", "../escape"} {
+		if _, _, err := Create(dir, name); err == nil {
+			t.Errorf("Create(%q) succeeded, want an error", name)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("invalid names created %d files", len(entries))
+	}
+}
+
+func TestStatusApplied(t *testing.T) {
+	migrations := func() []Migration {
+		return []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	}
+
+	tests := []struct {
+		name        string
+		version     uint
+		dirty       bool
+		wantApplied []bool
+		wantPending int
+	}{
+		{"empty database", 0, false, []bool{false, false, false}, 3},
+		{"partially migrated", 2, false, []bool{true, true, false}, 1},
+		{"fully migrated", 3, false, []bool{true, true, true}, 0},
+		{"dirty version", 2, true, []bool{true, false, false}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := newStatus(migrations(), tt.version, tt.dirty)
+
+			var applied []bool
+			for _, m := range status.Migrations {
+				applied = append(applied, m.Applied)
+			}
+			if !reflect.DeepEqual(applied, tt.wantApplied) {
+				t.Errorf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+			if got := status.Pending(); got != tt.wantPending {
+				t.Errorf("Pending() = %d, want %d", got, tt.wantPending)
+			}
+			if status.Version != tt.version || status.Dirty != tt.dirty {
+				t.Errorf("status version %d dirty %v, want %d %v", status.Version, status.Dirty, tt.version, tt.dirty)
+			}
+		})
+	}
+}
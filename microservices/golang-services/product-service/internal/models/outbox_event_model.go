@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const AggregateProduct = "product"
+
+const (
+	EventProductCreated       = "product.created"
+	EventProductUpdated       = "product.updated"
+	EventProductStatusChanged = "product.status_changed"
+	EventProductDeleted       = "product.deleted"
+	EventProductRestored      = "product.restored"
+	EventProductPurged        = "product.purged"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// it describes and published to the event stream by the outbox relay.
+type OutboxEvent struct {
+	ID            uint64     `gorm:"primaryKey" json:"id"`
+	AggregateType string     `gorm:"type:varchar(50);not null" json:"aggregate_type"`
+	AggregateID   uint       `gorm:"not null" json:"aggregate_id"`
+	EventType     string     `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload       string     `gorm:"type:jsonb;not null" json:"payload"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PublishedAt   *time.Time `json:"published_at"`
+}
+
+type ProductEventPayload struct {
+	Product *Product      `json:"product"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// NewProductEvent builds an outbox event of eventType carrying the current
+// product state and, for updates, the fields that changed.
+func NewProductEvent(eventType string, product *Product, changes []FieldChange) (*OutboxEvent, error) {
+	payload, err := json.Marshal(ProductEventPayload{Product: product, Changes: changes})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		AggregateType: AggregateProduct,
+		AggregateID:   product.ID,
+		EventType:     eventType,
+		Payload:       string(payload),
+	}, nil
+}
+
+// ProductUpdateEvents describes the change from previous to product. Content
+// changes become a product.updated event and a status change is reported
+// separately as product.status_changed. Nothing is returned when the update
+// changed no tracked field.
+func ProductUpdateEvents(previous *ProductRevision, product *Product) ([]*OutboxEvent, error) {
+	fieldChanges, statusChanges := SplitStatusChange(previous.Diff(NewProductRevision(product, "")))
+
+	events := []*OutboxEvent{}
+	if len(fieldChanges) > 0 {
+		event, err := NewProductEvent(EventProductUpdated, product, fieldChanges)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if len(statusChanges) > 0 {
+		event, err := NewProductEvent(EventProductStatusChanged, product, statusChanges)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,117 @@
+package models
+
+import "math"
+
+const MaxBulkProducts = 1000
+
+const (
+	BulkOutcomeUpdated   = "updated"
+	BulkOutcomeUnchanged = "unchanged"
+	BulkOutcomeNotFound  = "not_found"
+	BulkOutcomeFailed    = "failed"
+)
+
+type BulkProductFilter struct {
+	Search string `json:"search"`
+	Status string `json:"status" binding:"omitempty,oneof=draft pending_review active inactive archived"`
+}
+
+type BulkPriceChange struct {
+	Mode  string  `json:"mode" binding:"required,oneof=absolute percentage"`
+	Value float64 `json:"value" binding:"required"`
+}
+
+type BulkProductPatch struct {
+	Price         *BulkPriceChange `json:"price"`
+	QuantityDelta *int             `json:"quantity_delta"`
+	Status        *string          `json:"status" binding:"omitempty,oneof=draft pending_review active inactive archived"`
+}
+
+type BulkUpdateProductsInput struct {
+	IDs    []uint             `json:"ids" binding:"omitempty,max=1000,dive,gt=0"`
+	Filter *BulkProductFilter `json:"filter"`
+	Patch  BulkProductPatch   `json:"patch" binding:"required"`
+}
+
+type BulkUpdateStatusInput struct {
+	IDs    []uint             `json:"ids" binding:"omitempty,max=1000,dive,gt=0"`
+	Filter *BulkProductFilter `json:"filter"`
+	Status string             `json:"status" binding:"required,oneof=draft pending_review active inactive archived"`
+}
+
+type BulkItemResult struct {
+	ID      uint                `json:"id"`
+	Outcome string              `json:"outcome"`
+	Errors  map[string][]string `json:"errors,omitempty"`
+	Changes []FieldChange       `json:"changes,omitempty"`
+	Product *Product            `json:"product,omitempty"`
+}
+
+type BulkUpdateReport struct {
+	Total     int              `json:"total"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	NotFound  int              `json:"not_found"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+// ValidateBulkSelection checks that exactly one of ids or filter selects the
+// products to change.
+func ValidateBulkSelection(ids []uint, filter *BulkProductFilter) map[string][]string {
+	if (len(ids) == 0) == (filter == nil) {
+		return map[string][]string{
+			"IDs": {"Either IDs or Filter must be provided, but not both."},
+		}
+	}
+	return nil
+}
+
+func (p *BulkProductPatch) IsEmpty() bool {
+	return p.Price == nil && p.QuantityDelta == nil && p.Status == nil
+}
+
+// ApplyTo applies the patch to product and returns validation errors, keyed
+// like ParseValidationErrors, when the result would be invalid. product is
+// left untouched when errors are returned.
+func (p *BulkProductPatch) ApplyTo(product *Product) map[string][]string {
+	errs := map[string][]string{}
+	price := product.Price
+	quantity := product.Quantity
+	status := product.Status
+
+	if p.Price != nil {
+		if p.Price.Mode == "percentage" {
+			price = math.Round(price*(100+p.Price.Value)) / 100
+		} else {
+			price = p.Price.Value
+		}
+		if price <= 0 {
+			errs["Price"] = []string{"The Price must be greater than 0."}
+		}
+	}
+
+	if p.QuantityDelta != nil {
+		quantity += *p.QuantityDelta
+		if quantity < 0 {
+			errs["Quantity"] = []string{"The Quantity must be greater than or equal to 0."}
+		}
+	}
+
+	if p.Status != nil {
+		target := ProductStatus(*p.Status)
+		if !status.CanTransitionTo(target) {
+			errs["Status"] = []string{"The Status cannot change from " + string(status) + " to " + string(target) + "."}
+		}
+		status = target
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	product.Price = price
+	product.Quantity = quantity
+	product.Status = status
+	return nil
+}
@@ -0,0 +1,181 @@
+package models
+
+import "testing"
+
+func TestBulkProductPatchApplyTo(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	statusPtr := func(s ProductStatus) *string { v := string(s); return &v }
+
+	tests := []struct {
+		name     string
+		product  Product
+		patch    BulkProductPatch
+		price    float64
+		quantity int
+		status   ProductStatus
+		errors   []string
+	}{
+		{
+			name:    "absolute price",
+			product: Product{Price: 10, Quantity: 1, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "absolute", Value: 7.25}},
+			price:   7.25, quantity: 1, status: StatusActive,
+		},
+		{
+			name:    "percentage increase",
+			product: Product{Price: 10, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "percentage", Value: 15}},
+			price:   11.5, status: StatusActive,
+		},
+		{
+			name:    "percentage rounds to cents",
+			product: Product{Price: 19.99, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "percentage", Value: 10}},
+			price:   21.99, status: StatusActive,
+		},
+		{
+			name:    "percentage rounds half away from zero",
+			product: Product{Price: 0.01, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "percentage", Value: -50}},
+			price:   0.01, status: StatusActive,
+		},
+		{
+			name:    "percentage discount",
+			product: Product{Price: 20, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "percentage", Value: -25}},
+			price:   15, status: StatusActive,
+		},
+		{
+			name:    "percentage rounding to zero",
+			product: Product{Price: 0.01, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "percentage", Value: -60}},
+			errors:  []string{"Price"},
+		},
+		{
+			name:    "full discount",
+			product: Product{Price: 20, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "percentage", Value: -100}},
+			errors:  []string{"Price"},
+		},
+		{
+			name:    "negative absolute price",
+			product: Product{Price: 20, Status: StatusActive},
+			patch:   BulkProductPatch{Price: &BulkPriceChange{Mode: "absolute", Value: -1}},
+			errors:  []string{"Price"},
+		},
+		{
+			name:    "negative quantity delta",
+			product: Product{Price: 10, Quantity: 5, Status: StatusActive},
+			patch:   BulkProductPatch{QuantityDelta: intPtr(-3)},
+			price:   10, quantity: 2, status: StatusActive,
+		},
+		{
+			name:    "quantity delta to zero",
+			product: Product{Price: 10, Quantity: 5, Status: StatusActive},
+			patch:   BulkProductPatch{QuantityDelta: intPtr(-5)},
+			price:   10, quantity: 0, status: StatusActive,
+		},
+		{
+			name:    "quantity delta below zero",
+			product: Product{Price: 10, Quantity: 5, Status: StatusActive},
+			patch:   BulkProductPatch{QuantityDelta: intPtr(-6)},
+			errors:  []string{"Quantity"},
+		},
+		{
+			name:    "allowed transition",
+			product: Product{Price: 10, Status: StatusActive},
+			patch:   BulkProductPatch{Status: statusPtr(StatusInactive)},
+			price:   10, status: StatusInactive,
+		},
+		{
+			name:    "same status",
+			product: Product{Price: 10, Status: StatusDraft},
+			patch:   BulkProductPatch{Status: statusPtr(StatusDraft)},
+			price:   10, status: StatusDraft,
+		},
+		{
+			name:    "invalid transition",
+			product: Product{Price: 10, Status: StatusArchived},
+			patch:   BulkProductPatch{Status: statusPtr(StatusActive)},
+			errors:  []string{"Status"},
+		},
+		{
+			name:    "every error at once",
+			product: Product{Price: 10, Quantity: 1, Status: StatusInactive},
+			patch: BulkProductPatch{
+				Price:         &BulkPriceChange{Mode: "absolute", Value: 0},
+				QuantityDelta: intPtr(-2),
+				Status:        statusPtr(StatusDraft),
+			},
+			errors: []string{"Price", "Quantity", "Status"},
+		},
+		{
+			name:    "one error blocks the valid changes",
+			product: Product{Price: 10, Quantity: 1, Status: StatusActive},
+			patch: BulkProductPatch{
+				Price:         &BulkPriceChange{Mode: "absolute", Value: 12},
+				QuantityDelta: intPtr(-2),
+			},
+			errors: []string{"Quantity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := tt.product
+			errs := tt.patch.ApplyTo(&product)
+
+			if len(tt.errors) > 0 {
+				if len(errs) != len(tt.errors) {
+					t.Errorf("errors = %v, want %v", errs, tt.errors)
+				}
+				for _, field := range tt.errors {
+					if _, ok := errs[field]; !ok {
+						t.Errorf("no error for %s in %v", field, errs)
+					}
+				}
+				if product != tt.product {
+					t.Errorf("product changed to %+v despite errors", product)
+				}
+				return
+			}
+
+			if errs != nil {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+			if product.Price != tt.price || product.Quantity != tt.quantity || product.Status != tt.status {
+				t.Errorf("got price %v, quantity %d, status %s; want %v, %d, %s",
+					product.Price, product.Quantity, product.Status, tt.price, tt.quantity, tt.status)
+			}
+		})
+	}
+}
+
+func TestValidateBulkSelection(t *testing.T) {
+	tests := []struct {
+		name   string
+		ids    []uint
+		filter *BulkProductFilter
+		valid  bool
+	}{
+		{"ids", []uint{1, 2}, nil, true},
+		{"filter", nil, &BulkProductFilter{Status: "draft"}, true},
+		{"empty filter", nil, &BulkProductFilter{}, true},
+		{"empty ids with filter", []uint{}, &BulkProductFilter{Search: "lamp"}, true},
+		{"neither", nil, nil, false},
+		{"empty ids", []uint{}, nil, false},
+		{"both", []uint{1}, &BulkProductFilter{Status: "active"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateBulkSelection(tt.ids, tt.filter)
+			if (errs == nil) != tt.valid {
+				t.Errorf("ValidateBulkSelection = %v, want valid %v", errs, tt.valid)
+			}
+			if !tt.valid && len(errs["IDs"]) == 0 {
+				t.Errorf("errors = %v, want one for IDs", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ChangeRequestStatus string
+
+const (
+	ChangeRequestPending  ChangeRequestStatus = "pending"
+	ChangeRequestApproved ChangeRequestStatus = "approved"
+	ChangeRequestRejected ChangeRequestStatus = "rejected"
+)
+
+type ProductChangeRequest struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ProductID   uint       `gorm:"not null;index" json:"product_id"`
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	Price       float64    `gorm:"type:decimal(10,2);not null" json:"price"`
+	Quantity    int        `gorm:"default:0;not null" json:"quantity"`
+	ImageURL    string     `gorm:"type:varchar(255)" json:"image_url"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+	// BaseUpdatedAt is the product's updated_at when the change was
+	// submitted.
+	BaseUpdatedAt *time.Time `json:"base_updated_at"`
+	// Base is the product at that version, a ProductRevision in JSON. It
+	// tells the fields the request changes from those it merely carries.
+	Base          string              `gorm:"type:jsonb" json:"-"`
+	Status        ChangeRequestStatus `gorm:"type:varchar(20);default:pending;index" json:"status"`
+	RequestedBy   string              `gorm:"type:varchar(255)" json:"requested_by"`
+	ReviewedBy    string              `gorm:"type:varchar(255)" json:"reviewed_by"`
+	ReviewComment string              `gorm:"type:text" json:"review_comment"`
+	ReviewedAt    *time.Time          `json:"reviewed_at"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+type ApproveChangeRequestInput struct {
+	Comment string `form:"comment" json:"comment"`
+}
+
+type RejectChangeRequestInput struct {
+	Comment string `form:"comment" json:"comment" binding:"required,not_blank"`
+}
+
+// NewProductChangeRequest captures the proposed state of product for review.
+func NewProductChangeRequest(product *Product, requestedBy string) *ProductChangeRequest {
+	return &ProductChangeRequest{
+		ProductID:   product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Quantity:    product.Quantity,
+		ImageURL:    product.ImageURL,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
+		Status:      ChangeRequestPending,
+		RequestedBy: requestedBy,
+	}
+}
+
+// SetBase records product as the version the request is submitted against.
+func (r *ProductChangeRequest) SetBase(product *Product) error {
+	base, err := json.Marshal(NewProductRevision(product, ""))
+	if err != nil {
+		return err
+	}
+
+	r.Base = string(base)
+	r.BaseUpdatedAt = &product.UpdatedAt
+	return nil
+}
+
+// ApplyTo copies the fields the request changes onto product. Fields it only
+// carries keep their current values, so writes made since submission, such
+// as stock reservations, survive approval. When product no longer holds the
+// base value of a field the request changes, applying it would revert that
+// update instead: product is left alone and the conflicting fields are
+// returned.
+//
+// Requests submitted before the base was recorded change every field that
+// differs from product, and conflict on all of them once product has been
+// updated at all.
+func (r *ProductChangeRequest) ApplyTo(product *Product) ([]string, error) {
+	current := NewProductRevision(product, "")
+	base := current
+	stale := r.BaseUpdatedAt != nil && !product.UpdatedAt.Equal(*r.BaseUpdatedAt)
+	if r.Base != "" {
+		base = &ProductRevision{}
+		if err := json.Unmarshal([]byte(r.Base), base); err != nil {
+			return nil, err
+		}
+		stale = false
+	}
+
+	proposed := *base
+	proposed.Name = r.Name
+	proposed.Description = r.Description
+	proposed.Price = r.Price
+	proposed.Quantity = r.Quantity
+	proposed.ImageURL = r.ImageURL
+	proposed.PublishAt = r.PublishAt
+	proposed.UnpublishAt = r.UnpublishAt
+	requested := base.Diff(&proposed)
+
+	moved := map[string]bool{}
+	for _, change := range base.Diff(current) {
+		moved[change.Field] = true
+	}
+
+	conflicts := []string{}
+	for _, change := range requested {
+		if stale || moved[change.Field] {
+			conflicts = append(conflicts, change.Field)
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	for _, change := range requested {
+		switch change.Field {
+		case "name":
+			product.Name = r.Name
+		case "description":
+			product.Description = r.Description
+		case "price":
+			product.Price = r.Price
+		case "quantity":
+			product.Quantity = r.Quantity
+		case "image_url":
+			product.ImageURL = r.ImageURL
+		case "publish_at":
+			product.PublishAt = r.PublishAt
+		case "unpublish_at":
+			product.UnpublishAt = r.UnpublishAt
+		}
+	}
+
+	return nil, nil
+}
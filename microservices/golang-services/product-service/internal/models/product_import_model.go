@@ -0,0 +1,41 @@
+package models
+
+type ImportProductRow struct {
+	CreateProductInput
+	ImageURL string `form:"image_url" binding:"omitempty,url,max=255"`
+}
+
+type ImportOptions struct {
+	DryRun bool `form:"dry_run" json:"dry_run"`
+	Upsert bool `form:"upsert" json:"upsert"`
+}
+
+type ImportRowResult struct {
+	Row       int    `json:"row"`
+	SKU       string `json:"sku,omitempty"`
+	Action    string `json:"action"`
+	ProductID uint   `json:"product_id,omitempty"`
+	// ChangeRequestID is set when review mode turned the update into a
+	// change request.
+	ChangeRequestID uint                `json:"change_request_id,omitempty"`
+	Errors          map[string][]string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool `json:"dry_run"`
+	Upsert  bool `json:"upsert"`
+	Total   int  `json:"total"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	// Submitted counts updates sent for review instead of applied.
+	Submitted int               `json:"submitted"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionSubmit = "submit"
+	ImportActionFailed = "failed"
+)
@@ -8,28 +8,109 @@ import (
 
 type Product struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
+	SKU         string         `gorm:"column:sku;type:varchar(100)" json:"sku"`
 	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
 	Price       float64        `gorm:"type:decimal(10,2);not null" json:"price"`
 	Quantity    int            `gorm:"default:0;not null" json:"quantity"`
-	Status      int16          `gorm:"default:1" json:"status"`
+	Status      ProductStatus  `gorm:"type:varchar(20);default:draft" json:"status"`
 	ImageURL    string         `gorm:"type:varchar(255)" json:"image_url"`
+	PublishAt   *time.Time     `gorm:"index" json:"publish_at"`
+	UnpublishAt *time.Time     `gorm:"index" json:"unpublish_at"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type CreateProductInput struct {
-	Name        string  `form:"name" binding:"required,not_blank,min=3"`
-	Description string  `form:"description" binding:"required,not_blank"`
-	Price       float64 `form:"price" binding:"required,gt=0"`
-	Quantity    int     `form:"quantity" binding:"required,gte=0"`
+	SKU         string     `form:"sku" binding:"omitempty,max=100"`
+	Name        string     `form:"name" binding:"required,not_blank,min=3"`
+	Description string     `form:"description" binding:"required,not_blank"`
+	Price       float64    `form:"price" binding:"required,gt=0"`
+	Quantity    int        `form:"quantity" binding:"required,gte=0"`
+	Status      string     `form:"status" binding:"omitempty,oneof=draft pending_review active"`
+	PublishAt   *time.Time `form:"publish_at" time_format:"2006-01-02T15:04:05Z07:00"`
+	UnpublishAt *time.Time `form:"unpublish_at" time_format:"2006-01-02T15:04:05Z07:00"`
 }
 
 type UpdateProductInput struct {
-	Name        string  `form:"name" binding:"required,not_blank,min=3"`
-	Description string  `form:"description" binding:"required,not_blank"`
-	Price       float64 `form:"price" binding:"required,gt=0"`
-	Quantity    int     `form:"quantity" binding:"required,gte=0"` // ✅ Diperbaiki
-	ImageURL    string  `form:"image_url"`
+	Name        string     `form:"name" json:"name" binding:"required,not_blank,min=3"`
+	Description string     `form:"description" json:"description" binding:"required,not_blank"`
+	Price       float64    `form:"price" json:"price" binding:"required,gt=0"`
+	Quantity    int        `form:"quantity" json:"quantity" binding:"required,gte=0"` // ✅ Diperbaiki
+	PublishAt   *time.Time `form:"publish_at" json:"publish_at" time_format:"2006-01-02T15:04:05Z07:00"`
+	UnpublishAt *time.Time `form:"unpublish_at" json:"unpublish_at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// PatchProductInput is a product as a PATCH leaves it. The fields are
+// pointers so that a member the patch removed is told apart from one set to
+// its zero value, such as the quantity of a product that sold out. Only the
+// fields the patch changed are validated.
+type PatchProductInput struct {
+	Name        *string    `json:"name" binding:"required,not_blank,min=3"`
+	Description *string    `json:"description" binding:"required,not_blank"`
+	Price       *float64   `json:"price" binding:"required,gt=0"`
+	Quantity    *int       `json:"quantity" binding:"required,gte=0"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+type ReprocessImagesInput struct {
+	IDs []uint `json:"ids" binding:"omitempty,max=1000,dive,gt=0"`
+}
+
+type UpdateProductStatusInput struct {
+	Status string `form:"status" json:"status" binding:"required,oneof=draft pending_review active inactive archived"`
+}
+
+// ScheduledStatuses are the statuses a product leaves for active once its
+// publish time has passed.
+var ScheduledStatuses = []ProductStatus{StatusDraft, StatusInactive}
+
+// IsVisible reports whether storefront users can see the product at now. An
+// active product is visible inside its publish window; a draft or inactive
+// one as soon as its publish time passes, without waiting for the scheduler
+// to activate it. Nothing is visible past its unpublish time.
+func (p *Product) IsVisible(now time.Time) bool {
+	if p.UnpublishAt != nil && !p.UnpublishAt.After(now) {
+		return false
+	}
+
+	switch p.Status {
+	case StatusActive:
+		return p.PublishAt == nil || !p.PublishAt.After(now)
+	case StatusDraft, StatusInactive:
+		return p.PublishAt != nil && !p.PublishAt.After(now)
+	default:
+		return false
+	}
+}
+
+// SettlePublishAt clears the publish time once it has no job left, given the
+// status the product had before this change. That is when the product is
+// active past its publish time, however it got there, and whenever it stops
+// being active. A draft or inactive product keeping an elapsed publish time
+// would count as visible and be activated again by the scheduler, undoing
+// the deactivation.
+func (p *Product) SettlePublishAt(previous ProductStatus, now time.Time) {
+	if p.PublishAt == nil {
+		return
+	}
+
+	leftActive := previous == StatusActive && p.Status != StatusActive
+	published := p.Status == StatusActive && !p.PublishAt.After(now)
+	if leftActive || published {
+		p.PublishAt = nil
+	}
+}
+
+// ValidatePublishWindow checks that an unpublish time, when both are set,
+// falls after the publish time.
+func ValidatePublishWindow(publishAt, unpublishAt *time.Time) map[string][]string {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return map[string][]string{
+			"UnpublishAt": {"The UnpublishAt must be after PublishAt."},
+		}
+	}
+	return nil
 }
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsVisible(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		status      ProductStatus
+		publishAt   *time.Time
+		unpublishAt *time.Time
+		want        bool
+	}{
+		{"active", StatusActive, nil, nil, true},
+		{"active after publish time", StatusActive, &past, nil, true},
+		{"active before publish time", StatusActive, &future, nil, false},
+		{"active publishing now", StatusActive, &now, nil, true},
+		{"active before unpublish time", StatusActive, nil, &future, true},
+		{"active past unpublish time", StatusActive, nil, &past, false},
+		{"active unpublishing now", StatusActive, nil, &now, false},
+		{"draft", StatusDraft, nil, nil, false},
+		{"draft after publish time", StatusDraft, &past, nil, true},
+		{"draft before publish time", StatusDraft, &future, nil, false},
+		{"draft past both times", StatusDraft, &past, &past, false},
+		{"inactive", StatusInactive, nil, nil, false},
+		{"inactive after publish time", StatusInactive, &past, nil, true},
+		{"pending review after publish time", StatusPendingReview, &past, nil, false},
+		{"archived after publish time", StatusArchived, &past, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{Status: tt.status, PublishAt: tt.publishAt, UnpublishAt: tt.unpublishAt}
+			if got := p.IsVisible(now); got != tt.want {
+				t.Errorf("IsVisible = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettlePublishAt(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		previous  ProductStatus
+		status    ProductStatus
+		publishAt *time.Time
+		cleared   bool
+	}{
+		{"activated after publish time", StatusDraft, StatusActive, &past, true},
+		{"activated before publish time", StatusDraft, StatusActive, &future, false},
+		{"created active after publish time", "", StatusActive, &past, true},
+		{"saved active after publish time", StatusActive, StatusActive, &past, true},
+		{"deactivated after publish time", StatusActive, StatusInactive, &past, true},
+		{"deactivated before publish time", StatusActive, StatusInactive, &future, true},
+		{"archived from active", StatusActive, StatusArchived, &future, true},
+		{"scheduled draft", StatusDraft, StatusDraft, &future, false},
+		{"draft due for publishing", StatusDraft, StatusDraft, &past, false},
+		{"inactive rescheduled", StatusInactive, StatusInactive, &past, false},
+		{"created draft", "", StatusDraft, &future, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{Status: tt.status, PublishAt: tt.publishAt}
+			p.SettlePublishAt(tt.previous, now)
+			if cleared := p.PublishAt == nil; cleared != tt.cleared {
+				t.Errorf("publish time cleared = %v, want %v", cleared, tt.cleared)
+			}
+		})
+	}
+}
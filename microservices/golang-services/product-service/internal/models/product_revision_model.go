@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+type ProductRevision struct {
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	ProductID   uint          `gorm:"not null;index" json:"product_id"`
+	SKU         string        `gorm:"column:sku;type:varchar(100)" json:"sku"`
+	Name        string        `gorm:"type:varchar(255);not null" json:"name"`
+	Description string        `gorm:"type:text" json:"description"`
+	Price       float64       `gorm:"type:decimal(10,2);not null" json:"price"`
+	Quantity    int           `gorm:"default:0;not null" json:"quantity"`
+	Status      ProductStatus `gorm:"type:varchar(20);default:draft" json:"status"`
+	ImageURL    string        `gorm:"type:varchar(255)" json:"image_url"`
+	PublishAt   *time.Time    `json:"publish_at"`
+	UnpublishAt *time.Time    `json:"unpublish_at"`
+	Actor       string        `gorm:"type:varchar(255)" json:"actor"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// NewProductRevision snapshots the given product state, attributing it to actor.
+func NewProductRevision(product *Product, actor string) *ProductRevision {
+	return &ProductRevision{
+		ProductID:   product.ID,
+		SKU:         product.SKU,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Quantity:    product.Quantity,
+		Status:      product.Status,
+		ImageURL:    product.ImageURL,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
+		Actor:       actor,
+	}
+}
+
+// SplitStatusChange separates a status change from the content changes, as
+// the two are reported as different events.
+func SplitStatusChange(changes []FieldChange) (fieldChanges, statusChanges []FieldChange) {
+	for _, change := range changes {
+		if change.Field == "status" {
+			statusChanges = append(statusChanges, change)
+		} else {
+			fieldChanges = append(fieldChanges, change)
+		}
+	}
+	return fieldChanges, statusChanges
+}
+
+// Diff lists the fields whose values differ between r and other.
+func (r *ProductRevision) Diff(other *ProductRevision) []FieldChange {
+	changes := []FieldChange{}
+
+	if r.SKU != other.SKU {
+		changes = append(changes, FieldChange{Field: "sku", From: r.SKU, To: other.SKU})
+	}
+	if r.Name != other.Name {
+		changes = append(changes, FieldChange{Field: "name", From: r.Name, To: other.Name})
+	}
+	if r.Description != other.Description {
+		changes = append(changes, FieldChange{Field: "description", From: r.Description, To: other.Description})
+	}
+	if r.Price != other.Price {
+		changes = append(changes, FieldChange{Field: "price", From: r.Price, To: other.Price})
+	}
+	if r.Quantity != other.Quantity {
+		changes = append(changes, FieldChange{Field: "quantity", From: r.Quantity, To: other.Quantity})
+	}
+	if r.Status != other.Status {
+		changes = append(changes, FieldChange{Field: "status", From: r.Status, To: other.Status})
+	}
+	if r.ImageURL != other.ImageURL {
+		changes = append(changes, FieldChange{Field: "image_url", From: r.ImageURL, To: other.ImageURL})
+	}
+	if !sameTime(r.PublishAt, other.PublishAt) {
+		changes = append(changes, FieldChange{Field: "publish_at", From: r.PublishAt, To: other.PublishAt})
+	}
+	if !sameTime(r.UnpublishAt, other.UnpublishAt) {
+		changes = append(changes, FieldChange{Field: "unpublish_at", From: r.UnpublishAt, To: other.UnpublishAt})
+	}
+
+	return changes
+}
+
+// sameTime reports whether a and b are both unset or the same instant.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
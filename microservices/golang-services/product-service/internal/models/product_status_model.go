@@ -0,0 +1,57 @@
+package models
+
+import "fmt"
+
+type ProductStatus string
+
+const (
+	StatusDraft         ProductStatus = "draft"
+	StatusPendingReview ProductStatus = "pending_review"
+	StatusActive        ProductStatus = "active"
+	StatusInactive      ProductStatus = "inactive"
+	StatusArchived      ProductStatus = "archived"
+)
+
+var ProductStatuses = []ProductStatus{
+	StatusDraft,
+	StatusPendingReview,
+	StatusActive,
+	StatusInactive,
+	StatusArchived,
+}
+
+var productStatusTransitions = map[ProductStatus][]ProductStatus{
+	StatusDraft:         {StatusPendingReview, StatusActive, StatusArchived},
+	StatusPendingReview: {StatusDraft, StatusActive, StatusArchived},
+	StatusActive:        {StatusInactive, StatusArchived},
+	StatusInactive:      {StatusActive, StatusArchived},
+	StatusArchived:      {StatusDraft},
+}
+
+func (s ProductStatus) IsValid() bool {
+	_, ok := productStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a product in state s may move to target.
+// Staying in the same state is always allowed so repeated submits are no-ops.
+func (s ProductStatus) CanTransitionTo(target ProductStatus) bool {
+	if s == target {
+		return target.IsValid()
+	}
+	for _, allowed := range productStatusTransitions[s] {
+		if allowed == target {
+			return true
+		}
+	}
+	return false
+}
+
+type InvalidTransitionError struct {
+	From ProductStatus
+	To   ProductStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot transition product from %s to %s", e.From, e.To)
+}
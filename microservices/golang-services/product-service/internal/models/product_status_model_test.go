@@ -0,0 +1,44 @@
+package models
+
+import "testing"
+
+func TestCanTransitionTo(t *testing.T) {
+	allowed := map[ProductStatus][]ProductStatus{
+		StatusDraft:         {StatusDraft, StatusPendingReview, StatusActive, StatusArchived},
+		StatusPendingReview: {StatusPendingReview, StatusDraft, StatusActive, StatusArchived},
+		StatusActive:        {StatusActive, StatusInactive, StatusArchived},
+		StatusInactive:      {StatusInactive, StatusActive, StatusArchived},
+		StatusArchived:      {StatusArchived, StatusDraft},
+	}
+
+	targets := append([]ProductStatus{"", "deleted", "ACTIVE"}, ProductStatuses...)
+	for _, from := range ProductStatuses {
+		for _, to := range targets {
+			want := false
+			for _, target := range allowed[from] {
+				if target == to {
+					want = true
+				}
+			}
+
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%q -> %q allowed = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	for _, from := range []ProductStatus{"", "deleted"} {
+		for _, to := range targets {
+			if from.CanTransitionTo(to) {
+				t.Errorf("unknown status %q may move to %q", from, to)
+			}
+		}
+	}
+}
+
+func TestInvalidTransitionError(t *testing.T) {
+	err := &InvalidTransitionError{From: StatusArchived, To: StatusActive}
+	if got := err.Error(); got != "cannot transition product from archived to active" {
+		t.Errorf("Error() = %q", got)
+	}
+}
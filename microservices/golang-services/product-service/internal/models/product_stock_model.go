@@ -0,0 +1,20 @@
+package models
+
+import "fmt"
+
+type StockReservation struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gte=1"`
+}
+
+// InsufficientStockError reports the first product of a reservation that
+// does not have enough quantity left.
+type InsufficientStockError struct {
+	ProductID uint
+	Requested int
+	Available int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %d: requested %d, available %d", e.ProductID, e.Requested, e.Available)
+}
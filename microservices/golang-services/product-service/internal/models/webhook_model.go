@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookEventAll subscribes to every product event.
+const WebhookEventAll = "*"
+
+// WebhookEventFilter lists the event types a subscription receives. It is
+// stored as a comma-separated column.
+type WebhookEventFilter []string
+
+func (f WebhookEventFilter) Matches(eventType string) bool {
+	for _, event := range f {
+		if event == WebhookEventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (f WebhookEventFilter) Value() (driver.Value, error) {
+	return strings.Join(f, ","), nil
+}
+
+func (f *WebhookEventFilter) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+		*f = WebhookEventFilter{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported webhook event filter type %T", value)
+	}
+
+	*f = WebhookEventFilter{}
+	for _, event := range strings.Split(raw, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			*f = append(*f, event)
+		}
+	}
+	return nil
+}
+
+type WebhookSubscription struct {
+	ID        uint               `gorm:"primaryKey" json:"id"`
+	URL       string             `gorm:"type:varchar(500);not null" json:"url"`
+	Secret    string             `gorm:"type:varchar(255);not null" json:"secret,omitempty"`
+	Events    WebhookEventFilter `gorm:"type:text;not null" json:"events"`
+	Active    bool               `gorm:"default:true;not null" json:"active"`
+	CreatedBy string             `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uint                  `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint                  `gorm:"not null;index" json:"subscription_id"`
+	Subscription   *WebhookSubscription  `gorm:"foreignKey:SubscriptionID" json:"-"`
+	EventID        uint64                `gorm:"not null" json:"event_id"`
+	EventType      string                `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload        string                `gorm:"type:jsonb;not null" json:"-"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);default:pending;not null" json:"status"`
+	Attempts       int                   `gorm:"default:0;not null" json:"attempts"`
+	ResponseCode   int                   `json:"response_code"`
+	ResponseBody   string                `gorm:"type:text" json:"response_body"`
+	Error          string                `gorm:"type:text" json:"error"`
+	RedeliveryOf   *uint                 `json:"redelivery_of"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+type WebhookSubscriptionInput struct {
+	URL    string   `form:"url" json:"url" binding:"required,url,max=500"`
+	Secret string   `form:"secret" json:"secret" binding:"omitempty,min=16,max=255"`
+	Events []string `form:"events" json:"events" binding:"required,min=1,dive,oneof=* product.created product.updated product.status_changed product.deleted product.restored product.purged"`
+	Active *bool    `form:"active" json:"active"`
+}
+
+// NewWebhookDelivery schedules event for immediate delivery to subscription.
+func NewWebhookDelivery(subscription *WebhookSubscription, event *OutboxEvent) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.EventType,
+		Payload:        event.Payload,
+		Status:         WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"product-service/config"
+	"product-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	GetUnpublished(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uint64, publishedAt time.Time) error
+	DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+type outboxRepository struct {
+	db config.GormPostgres
+}
+
+func NewOutboxRepository(db config.GormPostgres) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// GetUnpublished returns the oldest pending events in the order they were
+// written, which keeps the events of each product in commit order.
+func (r *outboxRepository) GetUnpublished(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	conn := r.db.GetConnection()
+	var events []models.OutboxEvent
+
+	err := conn.WithContext(ctx).
+		Where("published_at IS NULL").
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint64, publishedAt time.Time) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Update("published_at", publishedAt).Error
+}
+
+// DeletePublishedBefore removes up to limit events published before before
+// and reports how many were removed. Pending events are never removed.
+func (r *outboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	conn := r.db.GetConnection()
+	result := conn.WithContext(ctx).Exec(`
+		DELETE FROM outbox_events
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE published_at IS NOT NULL AND published_at < ?
+			ORDER BY id
+			LIMIT ?
+		)`, before, limit)
+	return result.RowsAffected, result.Error
+}
+
+// recordProductEvent stores a single product event inside tx.
+func recordProductEvent(tx *gorm.DB, eventType string, product *models.Product) error {
+	event, err := models.NewProductEvent(eventType, product, nil)
+	if err != nil {
+		return err
+	}
+	return tx.Create(event).Error
+}
+
+// recordProductUpdate stores the events describing the change from previous
+// to product inside tx.
+func recordProductUpdate(tx *gorm.DB, previous *models.ProductRevision, product *models.Product) error {
+	events, err := models.ProductUpdateEvents(previous, product)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/testdb"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	repo := NewOutboxRepository(db)
+
+	for i := 0; i < 5; i++ {
+		event := &models.OutboxEvent{AggregateType: models.AggregateProduct, AggregateID: 1, EventType: models.EventProductUpdated, Payload: "{}"}
+		if err := db.GetConnection().Create(event).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err := repo.GetUnpublished(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 || pending[0].ID != 1 || pending[2].ID != 3 {
+		t.Fatalf("pending = %+v, want events 1 to 3 in order", pending)
+	}
+
+	now := time.Now().UTC()
+	for id, publishedAt := range map[uint64]time.Time{1: now.Add(-48 * time.Hour), 2: now.Add(-36 * time.Hour), 3: now.Add(-time.Hour)} {
+		if err := repo.MarkPublished(ctx, id, publishedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err = repo.GetUnpublished(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].ID != 4 {
+		t.Fatalf("pending after publishing = %+v, want events 4 and 5", pending)
+	}
+
+	// Only events published before the cutoff go, oldest first and at most
+	// limit at a time; pending events are never removed.
+	cutoff := now.Add(-24 * time.Hour)
+	deleted, err := repo.DeletePublishedBefore(ctx, cutoff, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d events, want 1", deleted)
+	}
+	deleted, err = repo.DeletePublishedBefore(ctx, cutoff, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d events, want 1", deleted)
+	}
+
+	var remaining []models.OutboxEvent
+	if err := db.GetConnection().Order("id ASC").Find(&remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 3 || remaining[0].ID != 3 {
+		t.Errorf("remaining = %+v, want events 3 to 5", remaining)
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"product-service/config"
+	"product-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductChangeRequestRepository interface {
+	GetAll(ctx context.Context, limit, offset int, status *models.ChangeRequestStatus, productID uint) ([]models.ProductChangeRequest, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.ProductChangeRequest, error)
+	Create(ctx context.Context, request *models.ProductChangeRequest) error
+	Approve(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, *models.Product, []models.FieldChange, error)
+	Reject(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, error)
+}
+
+type productChangeRequestRepository struct {
+	db config.GormPostgres
+}
+
+func NewProductChangeRequestRepository(db config.GormPostgres) ProductChangeRequestRepository {
+	return &productChangeRequestRepository{db: db}
+}
+
+func (r *productChangeRequestRepository) GetAll(ctx context.Context, limit, offset int, status *models.ChangeRequestStatus, productID uint) ([]models.ProductChangeRequest, int64, error) {
+	conn := r.db.GetConnection()
+	var requests []models.ProductChangeRequest
+	var total int64
+
+	query := conn.WithContext(ctx).Model(&models.ProductChangeRequest{})
+
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if productID != 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&requests).Error
+
+	return requests, total, err
+}
+
+func (r *productChangeRequestRepository) GetByID(ctx context.Context, id uint) (*models.ProductChangeRequest, error) {
+	conn := r.db.GetConnection()
+	var request models.ProductChangeRequest
+	err := conn.WithContext(ctx).First(&request, id).Error
+	return &request, err
+}
+
+func (r *productChangeRequestRepository) Create(ctx context.Context, request *models.ProductChangeRequest) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Create(request).Error
+}
+
+// Approve applies a pending change request to its product, records the
+// replaced state as a revision and marks the request approved, all in one
+// transaction. It also returns the fields the change modified. Only the
+// fields the request changes are applied; when one of them was updated since
+// the request was submitted the product is left alone, as applying the
+// request would silently revert that update. The revision is attributed to
+// the reviewer, who applies the change; the requester stays on the request.
+func (r *productChangeRequestRepository) Approve(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, *models.Product, []models.FieldChange, error) {
+	conn := r.db.GetConnection()
+
+	var request models.ProductChangeRequest
+	var product models.Product
+	var changes []models.FieldChange
+
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			return err
+		}
+
+		if request.Status != models.ChangeRequestPending {
+			return fmt.Errorf("change request is not pending")
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", request.ProductID).
+			First(&product).Error; err != nil {
+			return err
+		}
+
+		revision := models.NewProductRevision(&product, reviewer)
+		conflicts, err := request.ApplyTo(&product)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("product changed since the request was submitted")
+		}
+
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		product.SettlePublishAt(product.Status, time.Now())
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+		changes = revision.Diff(models.NewProductRevision(&product, reviewer))
+		if err := recordProductUpdate(tx, revision, &product); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		request.Status = models.ChangeRequestApproved
+		request.ReviewedBy = reviewer
+		request.ReviewComment = comment
+		request.ReviewedAt = &now
+		return tx.Save(&request).Error
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &request, &product, changes, nil
+}
+
+func (r *productChangeRequestRepository) Reject(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, error) {
+	conn := r.db.GetConnection()
+
+	var request models.ProductChangeRequest
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			return err
+		}
+
+		if request.Status != models.ChangeRequestPending {
+			return fmt.Errorf("change request is not pending")
+		}
+
+		now := time.Now()
+		request.Status = models.ChangeRequestRejected
+		request.ReviewedBy = reviewer
+		request.ReviewComment = comment
+		request.ReviewedAt = &now
+		return tx.Save(&request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
@@ -2,18 +2,43 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"product-service/config"
 	"product-service/internal/models"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
-	GetAll(ctx context.Context, limit, offset int, search string, status *int) ([]models.Product, int64, error)
+	GetAll(ctx context.Context, limit, offset int, search string, status *models.ProductStatus) ([]models.Product, int64, error)
+	StreamAll(ctx context.Context, search string, status *models.ProductStatus, fn func(product *models.Product) error) error
 	GetByID(ctx context.Context, id uint) (*models.Product, error)
+	// GetByIDForUpdate reads from the primary, for callers that change the
+	// product and save it back; a lagging replica could hand them a stale
+	// copy. It takes no row lock.
+	GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Product, error)
 	GetByStatusActive(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error)
 	Create(ctx context.Context, product *models.Product) error
 	Update(ctx context.Context, product *models.Product) error
+	UpdateWithRevision(ctx context.Context, product *models.Product, actor string) ([]models.FieldChange, error)
+	BulkUpdate(ctx context.Context, ids []uint, filter *models.BulkProductFilter, patch *models.BulkProductPatch, actor string) ([]models.BulkItemResult, error)
+	ReserveStock(ctx context.Context, items []models.StockReservation, actor string) ([]models.Product, error)
 	Delete(ctx context.Context, id uint) error
+	GetTrashed(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error)
+	GetTrashedBefore(ctx context.Context, before time.Time) ([]models.Product, error)
+	GetDueForPublish(ctx context.Context, now time.Time) ([]models.Product, error)
+	GetWithLocalImages(ctx context.Context, ids []uint) ([]models.Product, error)
+	ReplaceImageURL(ctx context.Context, id uint, from, to, actor string) error
+	GetDueForUnpublish(ctx context.Context, now time.Time) ([]models.Product, error)
+	Restore(ctx context.Context, id uint) (*models.Product, error)
+	Purge(ctx context.Context, id uint) (*models.Product, error)
 }
 
 type productRepository struct {
@@ -24,20 +49,12 @@ func NewProductRepository(db config.GormPostgres) ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) GetAll(ctx context.Context, limit, offset int, search string, status *int) ([]models.Product, int64, error) {
-	conn := r.db.GetConnection()
+func (r *productRepository) GetAll(ctx context.Context, limit, offset int, search string, status *models.ProductStatus) ([]models.Product, int64, error) {
+	conn := r.db.GetReadConnection(ctx)
 	var products []models.Product
 	var total int64
 
-	query := conn.WithContext(ctx).Model(&models.Product{}).Where("deleted_at IS NULL")
-
-	if search != "" {
-		query = query.Where("name ILIKE ? OR description ILIKE ?  ", "%"+search+"%", "%"+search+"%")
-	}
-
-	if status != nil {
-		query = query.Where("status = ?", *status)
-	}
+	query := filterProducts(conn.WithContext(ctx).Model(&models.Product{}), search, status)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -52,19 +69,88 @@ func (r *productRepository) GetAll(ctx context.Context, limit, offset int, searc
 	return products, total, err
 }
 
-func (r *productRepository) GetByID(ctx context.Context, id uint) (*models.Product, error) {
+// StreamAll walks every product matching the GetAll filters through a
+// database cursor, calling fn once per row so memory stays constant.
+func (r *productRepository) StreamAll(ctx context.Context, search string, status *models.ProductStatus, fn func(product *models.Product) error) error {
 	conn := r.db.GetConnection()
+
+	rows, err := filterProducts(conn.WithContext(ctx).Model(&models.Product{}), search, status).
+		Order("created_at DESC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product models.Product
+		if err := conn.ScanRows(rows, &product); err != nil {
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func filterProducts(query *gorm.DB, search string, status *models.ProductStatus) *gorm.DB {
+	query = query.Where("deleted_at IS NULL")
+
+	if search != "" {
+		query = query.Where("name ILIKE ? OR description ILIKE ?  ", "%"+search+"%", "%"+search+"%")
+	}
+
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	return query
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id uint) (*models.Product, error) {
+	return getProduct(ctx, r.db.GetReadConnection(ctx), id)
+}
+
+func (r *productRepository) GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	return getProduct(ctx, r.db.GetConnection(), id)
+}
+
+func getProduct(ctx context.Context, conn *gorm.DB, id uint) (*models.Product, error) {
 	var product models.Product
 	err := conn.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&product).Error
 	return &product, err
 }
 
-func (r *productRepository) GetByStatusActive(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error) {
+// GetBySKU also finds products in the trash, as the unique SKU index covers
+// them too; callers check DeletedAt.
+func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	conn := r.db.GetConnection()
+	var product models.Product
+	err := conn.WithContext(ctx).Unscoped().Where("sku = ?", sku).First(&product).Error
+	return &product, err
+}
+
+func (r *productRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
 	conn := r.db.GetConnection()
 	var products []models.Product
+
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	err := conn.WithContext(ctx).Where("id IN ? AND deleted_at IS NULL", ids).Order("id ASC").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) GetByStatusActive(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error) {
+	conn := r.db.GetReadConnection(ctx)
+	var products []models.Product
 	var total int64
 
-	query := conn.WithContext(ctx).Model(&models.Product{}).Where("status = ? AND deleted_at IS NULL", 1)
+	now := time.Now()
+	query := visibleProducts(conn.WithContext(ctx).Model(&models.Product{}), now)
 
 	if search != "" {
 		query = query.Where("name ILIKE ? OR description ILIKE ?  ", "%"+search+"%", "%"+search+"%")
@@ -83,14 +169,219 @@ func (r *productRepository) GetByStatusActive(ctx context.Context, limit, offset
 	return products, total, err
 }
 
+// visibleProducts narrows query to the products storefront users can see at
+// now, matching Product.IsVisible.
+func visibleProducts(query *gorm.DB, now time.Time) *gorm.DB {
+	return query.
+		Where("deleted_at IS NULL").
+		Where("unpublish_at IS NULL OR unpublish_at > ?", now).
+		Where("(status = ? AND (publish_at IS NULL OR publish_at <= ?)) OR (status IN ? AND publish_at <= ?)",
+			models.StatusActive, now, models.ScheduledStatuses, now)
+}
+
 func (r *productRepository) Update(ctx context.Context, product *models.Product) error {
 	conn := r.db.GetConnection()
 	return conn.WithContext(ctx).Save(product).Error
 }
 
+// UpdateWithRevision saves product, records the state it replaces as a
+// revision attributed to actor and returns the fields that changed. That
+// state is read under a row lock inside the transaction and must still be
+// the one product was read at, judged by updated_at; otherwise the save
+// would overwrite a concurrent change such as a stock reservation, so the
+// update fails and the caller reloads.
+func (r *productRepository) UpdateWithRevision(ctx context.Context, product *models.Product, actor string) ([]models.FieldChange, error) {
+	conn := r.db.GetConnection()
+	var changes []models.FieldChange
+
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", product.ID).
+			First(&previous).Error; err != nil {
+			return err
+		}
+		if !previous.UpdatedAt.Equal(product.UpdatedAt) {
+			return fmt.Errorf("product changed concurrently")
+		}
+
+		revision := models.NewProductRevision(&previous, actor)
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		product.SettlePublishAt(previous.Status, time.Now())
+		if err := tx.Save(product).Error; err != nil {
+			return err
+		}
+		changes = revision.Diff(models.NewProductRevision(product, actor))
+		return recordProductUpdate(tx, revision, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// BulkUpdate applies patch to the products selected by ids or filter inside
+// a single transaction, recording a revision for each changed product. Items
+// the patch cannot be applied to are reported as failed and left unchanged;
+// only database errors abort and roll back the whole batch.
+func (r *productRepository) BulkUpdate(ctx context.Context, ids []uint, filter *models.BulkProductFilter, patch *models.BulkProductPatch, actor string) ([]models.BulkItemResult, error) {
+	conn := r.db.GetConnection()
+	var results []models.BulkItemResult
+
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		results = []models.BulkItemResult{}
+		var products []models.Product
+
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id ASC")
+		if len(ids) > 0 {
+			query = query.Where("id IN ? AND deleted_at IS NULL", ids)
+		} else {
+			var status *models.ProductStatus
+			if filter.Status != "" {
+				statusVal := models.ProductStatus(filter.Status)
+				status = &statusVal
+			}
+			query = filterProducts(query, filter.Search, status).Limit(models.MaxBulkProducts + 1)
+		}
+
+		if err := query.Find(&products).Error; err != nil {
+			return err
+		}
+
+		if len(products) > models.MaxBulkProducts {
+			return fmt.Errorf("bulk selection exceeds limit")
+		}
+
+		found := map[uint]bool{}
+		for i := range products {
+			product := &products[i]
+			found[product.ID] = true
+
+			previous := *product
+			if errs := patch.ApplyTo(product); errs != nil {
+				results = append(results, models.BulkItemResult{ID: product.ID, Outcome: models.BulkOutcomeFailed, Errors: errs})
+				continue
+			}
+
+			if previous.Price == product.Price && previous.Quantity == product.Quantity && previous.Status == product.Status {
+				results = append(results, models.BulkItemResult{ID: product.ID, Outcome: models.BulkOutcomeUnchanged, Product: product})
+				continue
+			}
+
+			revision := models.NewProductRevision(&previous, actor)
+			if err := tx.Create(revision).Error; err != nil {
+				return err
+			}
+			product.SettlePublishAt(previous.Status, time.Now())
+			if err := tx.Save(product).Error; err != nil {
+				return err
+			}
+			if err := recordProductUpdate(tx, revision, product); err != nil {
+				return err
+			}
+
+			results = append(results, models.BulkItemResult{
+				ID:      product.ID,
+				Outcome: models.BulkOutcomeUpdated,
+				Changes: revision.Diff(models.NewProductRevision(product, actor)),
+				Product: product,
+			})
+		}
+
+		for _, id := range ids {
+			if !found[id] {
+				found[id] = true
+				results = append(results, models.BulkItemResult{ID: id, Outcome: models.BulkOutcomeNotFound})
+			}
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// ReserveStock decrements the quantity of every reserved product in a single
+// transaction. Rows are locked in id order so concurrent reservations cannot
+// deadlock, and nothing is changed when any product is missing or short.
+func (r *productRepository) ReserveStock(ctx context.Context, items []models.StockReservation, actor string) ([]models.Product, error) {
+	conn := r.db.GetConnection()
+
+	requested := map[uint]int{}
+	ids := []uint{}
+	for _, item := range items {
+		if _, ok := requested[item.ProductID]; !ok {
+			ids = append(ids, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	var reserved []models.Product
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var products []models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND deleted_at IS NULL", ids).
+			Order("id ASC").
+			Find(&products).Error; err != nil {
+			return err
+		}
+
+		if len(products) != len(ids) {
+			return gorm.ErrRecordNotFound
+		}
+
+		byID := map[uint]*models.Product{}
+		for i := range products {
+			product := &products[i]
+			if product.Quantity < requested[product.ID] {
+				return &models.InsufficientStockError{ProductID: product.ID, Requested: requested[product.ID], Available: product.Quantity}
+			}
+
+			revision := models.NewProductRevision(product, actor)
+			if err := tx.Create(revision).Error; err != nil {
+				return err
+			}
+
+			product.Quantity -= requested[product.ID]
+			if err := tx.Save(product).Error; err != nil {
+				return err
+			}
+			if err := recordProductUpdate(tx, revision, product); err != nil {
+				return err
+			}
+			byID[product.ID] = product
+		}
+
+		reserved = make([]models.Product, 0, len(ids))
+		for _, id := range ids {
+			reserved = append(reserved, *byID[id])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reserved, nil
+}
+
 func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
 	conn := r.db.GetConnection()
-	return conn.WithContext(ctx).Create(product).Error
+	product.SettlePublishAt("", time.Now())
+	return conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			// A concurrent create can take the SKU after the service checked it.
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return fmt.Errorf("product sku already exists")
+			}
+			return err
+		}
+		return recordProductEvent(tx, models.EventProductCreated, product)
+	})
 }
 
 func (r *productRepository) Delete(ctx context.Context, id uint) error {
@@ -106,5 +397,171 @@ func (r *productRepository) Delete(ctx context.Context, id uint) error {
 		return fmt.Errorf("product already deleted")
 	}
 
-	return conn.WithContext(ctx).Delete(&product).Error
+	return conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		return recordProductEvent(tx, models.EventProductDeleted, &product)
+	})
+}
+
+func (r *productRepository) GetTrashed(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error) {
+	conn := r.db.GetConnection()
+	var products []models.Product
+	var total int64
+
+	query := conn.WithContext(ctx).Unscoped().Model(&models.Product{}).Where("deleted_at IS NOT NULL")
+
+	if search != "" {
+		query = query.Where("name ILIKE ? OR description ILIKE ?  ", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("deleted_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&products).Error
+
+	return products, total, err
+}
+
+func (r *productRepository) GetTrashedBefore(ctx context.Context, before time.Time) ([]models.Product, error) {
+	conn := r.db.GetConnection()
+	var products []models.Product
+
+	err := conn.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at ASC").
+		Find(&products).Error
+
+	return products, err
+}
+
+func (r *productRepository) GetDueForPublish(ctx context.Context, now time.Time) ([]models.Product, error) {
+	conn := r.db.GetConnection()
+	var products []models.Product
+
+	err := conn.WithContext(ctx).
+		Where("status IN ? AND deleted_at IS NULL", models.ScheduledStatuses).
+		Where("publish_at IS NOT NULL AND publish_at <= ?", now).
+		Where("unpublish_at IS NULL OR unpublish_at > ?", now).
+		Order("publish_at ASC").
+		Find(&products).Error
+
+	return products, err
+}
+
+func (r *productRepository) GetDueForUnpublish(ctx context.Context, now time.Time) ([]models.Product, error) {
+	conn := r.db.GetConnection()
+	var products []models.Product
+
+	err := conn.WithContext(ctx).
+		Where("status = ? AND deleted_at IS NULL", models.StatusActive).
+		Where("unpublish_at IS NOT NULL AND unpublish_at <= ?", now).
+		Order("unpublish_at ASC").
+		Find(&products).Error
+
+	return products, err
+}
+
+// GetWithLocalImages returns the products, optionally limited to ids, whose
+// image fell back to local storage instead of S3.
+func (r *productRepository) GetWithLocalImages(ctx context.Context, ids []uint) ([]models.Product, error) {
+	conn := r.db.GetConnection()
+	var products []models.Product
+
+	query := conn.WithContext(ctx).Where("image_url LIKE ? AND deleted_at IS NULL", "/uploads/%")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	err := query.Order("id ASC").Find(&products).Error
+	return products, err
+}
+
+// ReplaceImageURL points a product at a new copy of its image, recording a
+// revision like any other update. Only the image URL is written, and only
+// while it is still from, so edits made in the meantime are kept.
+func (r *productRepository) ReplaceImageURL(ctx context.Context, id uint, from, to, actor string) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", id).
+			First(&product).Error; err != nil {
+			return err
+		}
+
+		if product.ImageURL != from {
+			return fmt.Errorf("product image changed")
+		}
+
+		revision := models.NewProductRevision(&product, actor)
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		product.ImageURL = to
+		if err := tx.Model(&product).Update("image_url", to).Error; err != nil {
+			return err
+		}
+		return recordProductUpdate(tx, revision, &product)
+	})
+}
+
+func (r *productRepository) Restore(ctx context.Context, id uint) (*models.Product, error) {
+	conn := r.db.GetConnection()
+
+	var product models.Product
+	err := conn.WithContext(ctx).Unscoped().First(&product, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if !product.DeletedAt.Valid {
+		return nil, fmt.Errorf("product is not deleted")
+	}
+
+	err = conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&product).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		product.DeletedAt = gorm.DeletedAt{}
+		return recordProductEvent(tx, models.EventProductRestored, &product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (r *productRepository) Purge(ctx context.Context, id uint) (*models.Product, error) {
+	conn := r.db.GetConnection()
+
+	var product models.Product
+	err := conn.WithContext(ctx).Unscoped().First(&product, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if !product.DeletedAt.Valid {
+		return nil, fmt.Errorf("product is not deleted")
+	}
+
+	err = conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&product).Error; err != nil {
+			return err
+		}
+		return recordProductEvent(tx, models.EventProductPurged, &product)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
 }
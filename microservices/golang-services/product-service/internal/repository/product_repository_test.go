@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"product-service/config"
+	"product-service/internal/models"
+	"product-service/internal/testdb"
+
+	"gorm.io/gorm"
+)
+
+func createTestProduct(t *testing.T, repo ProductRepository, product *models.Product) {
+	t.Helper()
+	if product.Name == "" {
+		product.Name = "Lamp"
+	}
+	product.Price = 10
+	if err := repo.Create(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// updateTestProduct reloads the product, applies change and saves it the way
+// the service does.
+func updateTestProduct(t *testing.T, repo ProductRepository, id uint, change func(*models.Product)) []models.FieldChange {
+	t.Helper()
+	product, err := repo.GetByIDForUpdate(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change(product)
+	changes, err := repo.UpdateWithRevision(context.Background(), product, "editor@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return changes
+}
+
+func changedFields(changes []models.FieldChange) []string {
+	fields := []string{}
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	return fields
+}
+
+func TestUpdateWithRevisionRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	repo := NewProductRepository(db)
+	revisions := NewProductRevisionRepository(db)
+
+	product := &models.Product{SKU: "LAMP-1", Name: "Lamp", Description: "Brass", Quantity: 3, Status: models.StatusDraft}
+	createTestProduct(t, repo, product)
+
+	changes := updateTestProduct(t, repo, product.ID, func(p *models.Product) {
+		p.Name = "Desk lamp"
+		p.Quantity = 5
+	})
+	if got := changedFields(changes); len(got) != 2 || got[0] != "name" || got[1] != "quantity" {
+		t.Errorf("first update changed %v, want [name quantity]", got)
+	}
+
+	publishAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	changes = updateTestProduct(t, repo, product.ID, func(p *models.Product) {
+		p.PublishAt = &publishAt
+	})
+	if got := changedFields(changes); len(got) != 1 || got[0] != "publish_at" {
+		t.Errorf("schedule update changed %v, want [publish_at]", got)
+	}
+
+	history, total, err := revisions.GetByProductID(ctx, product.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(history) != 2 {
+		t.Fatalf("got %d revisions (total %d), want 2", len(history), total)
+	}
+
+	latest, first := history[0], history[1]
+	if first.Name != "Lamp" || first.Quantity != 3 || first.SKU != "LAMP-1" || first.PublishAt != nil {
+		t.Errorf("first revision = %+v, want the state as created", first)
+	}
+	if latest.Name != "Desk lamp" || latest.Quantity != 5 || latest.PublishAt != nil {
+		t.Errorf("latest revision = %+v, want the state before scheduling", latest)
+	}
+	if latest.Actor != "editor@example.com" {
+		t.Errorf("revision actor = %q, want editor@example.com", latest.Actor)
+	}
+
+	var events []models.OutboxEvent
+	if err := db.GetConnection().Where("aggregate_id = ?", product.ID).Order("id ASC").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	updated := 0
+	for _, event := range events {
+		if event.EventType == models.EventProductUpdated {
+			updated++
+		}
+	}
+	if updated != 2 {
+		t.Errorf("recorded %d %s events, want one per update", updated, models.EventProductUpdated)
+	}
+
+	latestByProduct, err := revisions.GetLatestByProductIDs(ctx, []uint{product.ID}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latestByProduct) != 1 || latestByProduct[0].ID != latest.ID {
+		t.Errorf("latest revisions = %+v, want revision %d", latestByProduct, latest.ID)
+	}
+}
+
+func TestUpdateWithRevisionRejectsStaleCopy(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	repo := NewProductRepository(db)
+
+	product := &models.Product{Quantity: 5, Status: models.StatusActive}
+	createTestProduct(t, repo, product)
+
+	stale, err := repo.GetByIDForUpdate(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A checkout reserves stock between the editor's read and save.
+	time.Sleep(time.Millisecond)
+	if _, err := repo.ReserveStock(ctx, []models.StockReservation{{ProductID: product.ID, Quantity: 2}}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+
+	stale.Name = "Renamed lamp"
+	_, err = repo.UpdateWithRevision(ctx, stale, "editor@example.com")
+	if err == nil || err.Error() != "product changed concurrently" {
+		t.Fatalf("saving a stale copy returned %v, want product changed concurrently", err)
+	}
+
+	got, err := repo.GetByID(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != 3 || got.Name != "Lamp" {
+		t.Errorf("product = %q with quantity %d, want the reservation kept and the stale save dropped", got.Name, got.Quantity)
+	}
+}
+
+func productEventTypes(t *testing.T, db config.GormPostgres, id uint) []string {
+	t.Helper()
+	var events []models.OutboxEvent
+	if err := db.GetConnection().Where("aggregate_id = ?", id).Order("id ASC").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	types := []string{}
+	for _, event := range events {
+		types = append(types, event.EventType)
+	}
+	return types
+}
+
+func TestRestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	repo := NewProductRepository(db)
+
+	product := &models.Product{Name: "Lamp", Status: models.StatusActive}
+	createTestProduct(t, repo, product)
+
+	if _, err := repo.Restore(ctx, product.ID); err == nil || err.Error() != "product is not deleted" {
+		t.Fatalf("Restore of a live product: err = %v", err)
+	}
+	if _, err := repo.Purge(ctx, product.ID); err == nil || err.Error() != "product is not deleted" {
+		t.Fatalf("Purge of a live product: err = %v", err)
+	}
+
+	if err := repo.Delete(ctx, product.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, product.ID); err == nil || err.Error() != "product already deleted" {
+		t.Fatalf("second Delete: err = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, product.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByID of a deleted product: err = %v", err)
+	}
+	if trashed, total, err := repo.GetTrashed(ctx, 10, 0, ""); err != nil || total != 1 || trashed[0].ID != product.ID {
+		t.Fatalf("GetTrashed = %v, %d, %v; want the deleted product", trashed, total, err)
+	}
+
+	restored, err := repo.Restore(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt.Valid {
+		t.Error("restored product still carries deleted_at")
+	}
+	if got, err := repo.GetByID(ctx, product.ID); err != nil || got.Name != "Lamp" {
+		t.Fatalf("GetByID after restore = %+v, %v", got, err)
+	}
+	if _, total, err := repo.GetTrashed(ctx, 10, 0, ""); err != nil || total != 0 {
+		t.Fatalf("trash holds %d products after restore, err %v", total, err)
+	}
+
+	if err := repo.Delete(ctx, product.ID); err != nil {
+		t.Fatal(err)
+	}
+	purged, err := repo.Purge(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.ID != product.ID {
+		t.Errorf("Purge returned product %d, want %d", purged.ID, product.ID)
+	}
+	var count int64
+	if err := db.GetConnection().Unscoped().Model(&models.Product{}).Where("id = ?", product.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("purged product is still stored")
+	}
+
+	want := []string{models.EventProductCreated, models.EventProductDeleted, models.EventProductRestored, models.EventProductDeleted, models.EventProductPurged}
+	if got := productEventTypes(t, db, product.ID); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("outbox events = %v, want %v", got, want)
+	}
+}
+
+func TestGetTrashedBefore(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	repo := NewProductRepository(db)
+	now := time.Now()
+
+	old := &models.Product{Name: "Old"}
+	recent := &models.Product{Name: "Recent"}
+	live := &models.Product{Name: "Live"}
+	for _, product := range []*models.Product{old, recent, live} {
+		createTestProduct(t, repo, product)
+	}
+	for _, product := range []*models.Product{old, recent} {
+		if err := repo.Delete(ctx, product.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.GetConnection().Unscoped().Model(&models.Product{}).Where("id = ?", old.ID).
+		Update("deleted_at", now.Add(-40*24*time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := repo.GetTrashedBefore(ctx, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].ID != old.ID {
+		t.Errorf("GetTrashedBefore = %+v, want only the product deleted 40 days ago", expired)
+	}
+}
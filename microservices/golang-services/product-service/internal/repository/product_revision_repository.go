@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"product-service/config"
+	"product-service/internal/models"
+)
+
+type ProductRevisionRepository interface {
+	GetByProductID(ctx context.Context, productID uint, limit, offset int) ([]models.ProductRevision, int64, error)
+	GetByID(ctx context.Context, productID, id uint) (*models.ProductRevision, error)
+	GetLatestByProductIDs(ctx context.Context, productIDs []uint, limit int) ([]models.ProductRevision, error)
+}
+
+type productRevisionRepository struct {
+	db config.GormPostgres
+}
+
+func NewProductRevisionRepository(db config.GormPostgres) ProductRevisionRepository {
+	return &productRevisionRepository{db: db}
+}
+
+func (r *productRevisionRepository) GetByProductID(ctx context.Context, productID uint, limit, offset int) ([]models.ProductRevision, int64, error) {
+	conn := r.db.GetConnection()
+	var revisions []models.ProductRevision
+	var total int64
+
+	query := conn.WithContext(ctx).Model(&models.ProductRevision{}).Where("product_id = ?", productID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).Error
+
+	return revisions, total, err
+}
+
+func (r *productRevisionRepository) GetByID(ctx context.Context, productID, id uint) (*models.ProductRevision, error) {
+	conn := r.db.GetConnection()
+	var revision models.ProductRevision
+	err := conn.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&revision).Error
+	return &revision, err
+}
+
+// GetLatestByProductIDs loads up to limit of the newest revisions of each
+// product in a single query, newest first within each product.
+func (r *productRevisionRepository) GetLatestByProductIDs(ctx context.Context, productIDs []uint, limit int) ([]models.ProductRevision, error) {
+	conn := r.db.GetConnection()
+	var revisions []models.ProductRevision
+
+	if len(productIDs) == 0 {
+		return revisions, nil
+	}
+
+	err := conn.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY created_at DESC, id DESC) AS rank
+			FROM product_revisions
+			WHERE product_id IN ?
+		) ranked
+		WHERE rank <= ?
+		ORDER BY product_id, rank`, productIDs, limit).
+		Scan(&revisions).Error
+
+	return revisions, err
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"product-service/config"
+	"product-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	GetAll(ctx context.Context, limit, offset int) ([]models.WebhookSubscription, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.WebhookSubscription, error)
+	GetActive(ctx context.Context) ([]models.WebhookSubscription, error)
+	Create(ctx context.Context, subscription *models.WebhookSubscription) error
+	Update(ctx context.Context, subscription *models.WebhookSubscription) error
+	Delete(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	GetDeliveryByID(ctx context.Context, id uint) (*models.WebhookDelivery, error)
+	CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	SaveDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db config.GormPostgres
+}
+
+func NewWebhookRepository(db config.GormPostgres) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) GetAll(ctx context.Context, limit, offset int) ([]models.WebhookSubscription, int64, error) {
+	conn := r.db.GetConnection()
+	var subscriptions []models.WebhookSubscription
+	var total int64
+
+	query := conn.WithContext(ctx).Model(&models.WebhookSubscription{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&subscriptions).Error
+
+	return subscriptions, total, err
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	conn := r.db.GetConnection()
+	var subscription models.WebhookSubscription
+	err := conn.WithContext(ctx).First(&subscription, id).Error
+	return &subscription, err
+}
+
+func (r *webhookRepository) GetActive(ctx context.Context) ([]models.WebhookSubscription, error) {
+	conn := r.db.GetConnection()
+	var subscriptions []models.WebhookSubscription
+	err := conn.WithContext(ctx).Where("active = ?", true).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *webhookRepository) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Save(subscription).Error
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	conn := r.db.GetConnection()
+
+	result := conn.WithContext(ctx).Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	conn := r.db.GetConnection()
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := conn.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error
+
+	return deliveries, total, err
+}
+
+func (r *webhookRepository) GetDeliveryByID(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	conn := r.db.GetConnection()
+	var delivery models.WebhookDelivery
+	err := conn.WithContext(ctx).First(&delivery, id).Error
+	return &delivery, err
+}
+
+// CreateDeliveries stores the fan-out of one event, skipping subscriptions
+// that already have a delivery for it so a replayed event is not sent twice.
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(deliveries).Error
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Create(delivery).Error
+}
+
+// ClaimDueDeliveries locks pending deliveries whose next attempt is due and
+// pushes their next attempt back by lease, so other replicas skip them while
+// this one sends. A replica that dies mid-send leaves them to be retried once
+// the lease expires.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	conn := r.db.GetConnection()
+	var deliveries []models.WebhookDelivery
+
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	subscriptionIDs := make([]uint, len(deliveries))
+	for i := range deliveries {
+		subscriptionIDs[i] = deliveries[i].SubscriptionID
+	}
+
+	var subscriptions []models.WebhookSubscription
+	if err := conn.WithContext(ctx).Where("id IN ?", subscriptionIDs).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	byID := map[uint]*models.WebhookSubscription{}
+	for i := range subscriptions {
+		byID[subscriptions[i].ID] = &subscriptions[i]
+	}
+	for i := range deliveries {
+		deliveries[i].Subscription = byID[deliveries[i].SubscriptionID]
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) SaveDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	conn := r.db.GetConnection()
+	return conn.WithContext(ctx).Omit("Subscription").Save(delivery).Error
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type docsRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.DocsHandler
+}
+
+func NewDocsRouter(v *gin.RouterGroup, handler handlers.DocsHandler) ProductRouter {
+	return &docsRouterImpl{v: v, handler: handler}
+}
+
+// Mount serves the API description without authentication so it can be
+// browsed before a token has been obtained.
+func (r *docsRouterImpl) Mount() {
+	r.v.GET("/openapi.json", r.handler.OpenAPI)
+	r.v.GET("/docs/*filepath", r.handler.UI)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type graphQLRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.GraphQLHandler
+	auth    gin.HandlerFunc
+}
+
+func NewGraphQLRouter(v *gin.RouterGroup, handler handlers.GraphQLHandler, auth gin.HandlerFunc) ProductRouter {
+	return &graphQLRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *graphQLRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.POST("", middleware.RequireAnyPermission("view_all_products", "view_active_products"), r.handler.Query)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.HealthHandler
+}
+
+func NewHealthRouter(v *gin.RouterGroup, handler handlers.HealthHandler) ProductRouter {
+	return &healthRouterImpl{v: v, handler: handler}
+}
+
+// Mount serves the probes without authentication, since the orchestrator
+// calling them has no token.
+func (r *healthRouterImpl) Mount() {
+	r.v.GET("/healthz", r.handler.Liveness)
+	r.v.GET("/readyz", r.handler.Readiness)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type jobRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.JobHandler
+	auth    gin.HandlerFunc
+}
+
+func NewJobRouter(v *gin.RouterGroup, handler handlers.JobHandler, auth gin.HandlerFunc) ProductRouter {
+	return &jobRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *jobRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.GET("/dead-letter", middleware.RequirePermission("manage_jobs"), r.handler.GetDeadJobs)
+	r.v.GET("/:id", r.handler.GetJob)
+	r.v.GET("/:id/download", r.handler.DownloadJobFile)
+
+	r.v.PUT("/retry/:id", middleware.RequirePermission("manage_jobs"), r.handler.RetryJob)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type metricsRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.MetricsHandler
+}
+
+func NewMetricsRouter(v *gin.RouterGroup, handler handlers.MetricsHandler) ProductRouter {
+	return &metricsRouterImpl{v: v, handler: handler}
+}
+
+// Mount serves Prometheus metrics without authentication so the scraper
+// needs no token; see MountMetrics.
+func (r *metricsRouterImpl) Mount() {
+	r.v.GET("/metrics", r.handler.Metrics)
+}
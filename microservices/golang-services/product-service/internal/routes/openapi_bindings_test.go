@@ -0,0 +1,473 @@
+package routes
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"product-service/internal/docs"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// sourcePackages are the packages whose handlers, helpers and binding
+// structs are read to find what each route accepts.
+var sourcePackages = map[string]string{
+	"handlers": "../handlers",
+	"models":   "../models",
+	"helpers":  "../../pkg/helpers",
+}
+
+// handlerName matches the name gin reports for a handler interface method
+// value, e.g. product-service/internal/handlers.ProductHandler.CreateProduct-fm.
+var handlerName = regexp.MustCompile(`/(\w+)\.(\w+)\.(\w+)-fm$`)
+
+// bindMethods maps the gin.Context binding methods to the body encodings
+// they accept; an empty list binds the query string.
+var bindMethods = map[string][]string{
+	"ShouldBind":      {"application/json", "application/x-www-form-urlencoded", "multipart/form-data"},
+	"ShouldBindJSON":  {"application/json"},
+	"ShouldBindQuery": {},
+}
+
+type binding struct {
+	method   string
+	typeName string
+}
+
+// handlerInputs is what a handler reads from the request, following the
+// calls it makes into the parsed packages.
+type handlerInputs struct {
+	queries  map[string]bool
+	files    map[string]bool
+	bindings []binding
+	rawBody  bool
+}
+
+type sourceIndex struct {
+	funcs   map[string]*ast.FuncDecl
+	structs map[string]*ast.StructType
+}
+
+func loadSources(t *testing.T) *sourceIndex {
+	t.Helper()
+
+	index := &sourceIndex{funcs: map[string]*ast.FuncDecl{}, structs: map[string]*ast.StructType{}}
+	fset := token.NewFileSet()
+	for pkg, dir := range sourcePackages {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatalf("parse %s: %v", path, err)
+			}
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					index.funcs[funcKey(pkg, decl)] = decl
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						if ts, ok := spec.(*ast.TypeSpec); ok {
+							if st, ok := ts.Type.(*ast.StructType); ok {
+								index.structs[pkg+"."+ts.Name.Name] = st
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+func funcKey(pkg string, decl *ast.FuncDecl) string {
+	if decl.Recv == nil {
+		return pkg + "." + decl.Name.Name
+	}
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	return pkg + ".(" + recv.(*ast.Ident).Name + ")." + decl.Name.Name
+}
+
+// inputs collects what the function key reads, including through the
+// methods of the same receiver and the functions of parsed packages it calls.
+func (s *sourceIndex) inputs(key string) *handlerInputs {
+	in := &handlerInputs{queries: map[string]bool{}, files: map[string]bool{}}
+	s.walk(key, in, map[string]bool{})
+	return in
+}
+
+func (s *sourceIndex) walk(key string, in *handlerInputs, seen map[string]bool) {
+	decl, ok := s.funcs[key]
+	if !ok || seen[key] || decl.Body == nil {
+		return
+	}
+	seen[key] = true
+	pkg := key[:strings.Index(key, ".")]
+
+	var recvName, recvType string
+	if decl.Recv != nil && len(decl.Recv.List[0].Names) > 0 {
+		recvName = decl.Recv.List[0].Names[0].Name
+		recvType = key[len(pkg)+1 : strings.LastIndex(key, ".")]
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && (sel.Sel.Name == "PostForm" || sel.Sel.Name == "Body") {
+			if inner, ok := sel.X.(*ast.SelectorExpr); ok && inner.Sel.Name == "Request" {
+				in.rawBody = true
+			}
+		}
+
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		switch fn := call.Fun.(type) {
+		case *ast.Ident:
+			s.walk(pkg+"."+fn.Name, in, seen)
+		case *ast.SelectorExpr:
+			method := fn.Sel.Name
+			switch method {
+			case "Query", "DefaultQuery", "GetQuery", "QueryArray":
+				if name, ok := stringArg(call); ok {
+					in.queries[name] = true
+				}
+			case "FormFile":
+				if name, ok := stringArg(call); ok {
+					in.files[name] = true
+				}
+			}
+			if _, ok := bindMethods[method]; ok && len(call.Args) == 1 {
+				if arg, ok := call.Args[0].(*ast.UnaryExpr); ok {
+					if ident, ok := arg.X.(*ast.Ident); ok {
+						in.bindings = append(in.bindings, binding{method, declaredType(decl.Body, ident.Name, pkg)})
+					}
+				}
+			}
+
+			if x, ok := fn.X.(*ast.Ident); ok {
+				if x.Name == recvName {
+					s.walk(pkg+"."+recvType+"."+method, in, seen)
+				} else if _, parsed := sourcePackages[x.Name]; parsed {
+					s.walk(x.Name+"."+method, in, seen)
+				}
+			}
+		}
+		return true
+	})
+}
+
+func stringArg(call *ast.CallExpr) (string, bool) {
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// declaredType finds the type of variable name, declared in body with var
+// or as a composite literal, as a "pkg.Type" key.
+func declaredType(body *ast.BlockStmt, name, pkg string) string {
+	var typeName string
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for _, ident := range n.Names {
+				if ident.Name == name && n.Type != nil {
+					typeName = typeKey(n.Type, pkg)
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name && i < len(n.Rhs) {
+					if lit, ok := n.Rhs[i].(*ast.CompositeLit); ok {
+						typeName = typeKey(lit.Type, pkg)
+					}
+				}
+			}
+		}
+		return typeName == ""
+	})
+	return typeName
+}
+
+// typeKey names a struct type expression as "pkg.Type", looking through
+// pointers and slices. Other types return "".
+func typeKey(expr ast.Expr, pkg string) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return typeKey(expr.X, pkg)
+	case *ast.ArrayType:
+		return typeKey(expr.Elt, pkg)
+	case *ast.Ident:
+		return pkg + "." + expr.Name
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			return x.Name + "." + expr.Sel.Name
+		}
+	}
+	return ""
+}
+
+type structField struct {
+	name     string
+	required bool
+	typeName string
+}
+
+// fields lists the request fields of a struct under the given tag ("json"
+// or "form"), including those of embedded structs.
+func (s *sourceIndex) fields(typeName, tag string) ([]structField, bool) {
+	st, ok := s.structs[typeName]
+	if !ok {
+		return nil, false
+	}
+	pkg := typeName[:strings.Index(typeName, ".")]
+
+	var fields []structField
+	for _, field := range st.Fields.List {
+		tags := reflect.StructTag("")
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tags = reflect.StructTag(raw)
+		}
+
+		if len(field.Names) == 0 {
+			if embedded, ok := s.fields(typeKey(field.Type, pkg), tag); ok {
+				fields = append(fields, embedded...)
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			name := strings.Split(tags.Get(tag), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = ident.Name
+			}
+			binding := tags.Get("binding")
+			fields = append(fields, structField{
+				name:     name,
+				required: binding == "required" || strings.HasPrefix(binding, "required,"),
+				typeName: typeKey(field.Type, pkg),
+			})
+		}
+	}
+	return fields, true
+}
+
+// compareSchema reports the differences between a binding struct and the
+// schema documenting it, descending into nested objects.
+func (s *sourceIndex) compareSchema(t *testing.T, where, typeName, tag string, schema *openapi3.Schema, files map[string]bool) {
+	t.Helper()
+
+	fields, ok := s.fields(typeName, tag)
+	if !ok {
+		t.Errorf("%s: binding type %s not found in %v", where, typeName, sourcePackages)
+		return
+	}
+
+	documented := map[string]bool{}
+	for name := range schema.Properties {
+		documented[name] = true
+	}
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	bound := map[string]bool{}
+	for name := range files {
+		bound[name] = true
+	}
+	for _, field := range fields {
+		bound[field.name] = true
+		if !documented[field.name] {
+			t.Errorf("%s: %s binds %q, which is not documented", where, typeName, field.name)
+			continue
+		}
+		if field.required && !required[field.name] {
+			t.Errorf("%s: %q is required by %s but optional in the spec", where, field.name, typeName)
+		}
+		if !field.required && required[field.name] {
+			t.Errorf("%s: %q is required in the spec but optional in %s", where, field.name, typeName)
+		}
+
+		property := schema.Properties[field.name].Value
+		if property.Type.Is("array") && property.Items != nil {
+			property = property.Items.Value
+		}
+		if _, nested := s.structs[field.typeName]; nested && len(property.Properties) > 0 {
+			s.compareSchema(t, where+"."+field.name, field.typeName, tag, property, nil)
+		}
+	}
+
+	for _, name := range sortedKeys(documented) {
+		if !bound[name] {
+			t.Errorf("%s: %q is documented but not bound by %s", where, name, typeName)
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestOpenAPIMatchesHandlerBindings checks the parameters and bodies in
+// internal/docs/openapi.json against what the registered handlers read:
+// path parameters against the route, query parameters against the
+// c.Query calls, and request bodies against the fields and required rules
+// of the structs they bind.
+func TestOpenAPIMatchesHandlerBindings(t *testing.T) {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(docs.OpenAPI)
+	if err != nil {
+		t.Fatalf("load openapi.json: %v", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		t.Fatalf("openapi.json is invalid: %v", err)
+	}
+
+	sources := loadSources(t)
+
+	checked := 0
+	gin.SetMode(gin.TestMode)
+	for _, route := range mountAll(gin.New()).Routes() {
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		item := spec.Paths.Find(path)
+		if item == nil {
+			continue
+		}
+		op := item.GetOperation(route.Method)
+		if op == nil {
+			continue
+		}
+		where := route.Method + " " + path
+
+		match := handlerName.FindStringSubmatch(route.Handler)
+		if match == nil {
+			continue
+		}
+		// Handler interfaces are implemented by productHandlerImpl and so on.
+		impl := strings.ToLower(match[2][:1]) + match[2][1:] + "Impl"
+		key := match[1] + ".(" + impl + ")." + match[3]
+		if _, ok := sources.funcs[key]; !ok {
+			t.Errorf("%s: handler %s not found", where, key)
+			continue
+		}
+		checked++
+		in := sources.inputs(key)
+
+		params := map[string]map[string]bool{"path": {}, "query": {}}
+		for _, ref := range append(item.Parameters, op.Parameters...) {
+			if params[ref.Value.In] != nil {
+				params[ref.Value.In][ref.Value.Name] = true
+			}
+		}
+
+		routeParams := map[string]bool{}
+		for _, m := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			routeParams[m[1]] = true
+		}
+		if !reflect.DeepEqual(routeParams, params["path"]) {
+			t.Errorf("%s: path parameters %v, route has %v", where, sortedKeys(params["path"]), sortedKeys(routeParams))
+		}
+
+		queries := in.queries
+		for _, b := range in.bindings {
+			if len(bindMethods[b.method]) > 0 && route.Method != http.MethodGet {
+				continue
+			}
+			fields, _ := sources.fields(b.typeName, "form")
+			for _, field := range fields {
+				queries[field.name] = true
+			}
+		}
+		for _, name := range sortedKeys(queries) {
+			if !params["query"][name] {
+				t.Errorf("%s: reads query parameter %q, which is not documented", where, name)
+			}
+		}
+		for _, name := range sortedKeys(params["query"]) {
+			if !queries[name] {
+				t.Errorf("%s: documents query parameter %q, which the handler does not read", where, name)
+			}
+		}
+
+		if in.rawBody {
+			continue
+		}
+
+		var bodies []binding
+		for _, b := range in.bindings {
+			if len(bindMethods[b.method]) > 0 && route.Method != http.MethodGet {
+				bodies = append(bodies, b)
+			}
+		}
+		if op.RequestBody == nil {
+			if len(bodies) > 0 {
+				t.Errorf("%s: binds a %s body, which is not documented", where, bodies[0].typeName)
+			}
+			continue
+		}
+		if len(bodies) == 0 {
+			t.Errorf("%s: documents a request body, which the handler does not bind", where)
+			continue
+		}
+
+		body := bodies[0]
+		for contentType, media := range op.RequestBody.Value.Content {
+			accepted := false
+			for _, ct := range bindMethods[body.method] {
+				accepted = accepted || ct == contentType
+			}
+			if !accepted {
+				t.Errorf("%s: documents %s, which %s does not accept", where, contentType, body.method)
+				continue
+			}
+
+			tag, files := "form", in.files
+			if contentType == "application/json" {
+				tag, files = "json", nil
+			}
+			sources.compareSchema(t, where+" "+contentType, body.typeName, tag, media.Schema.Value, files)
+		}
+	}
+
+	if checked == 0 {
+		t.Fatal("no documented route was matched to its handler")
+	}
+}
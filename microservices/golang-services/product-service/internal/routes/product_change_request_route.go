@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type productChangeRequestRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.ProductChangeRequestHandler
+	auth    gin.HandlerFunc
+}
+
+func NewProductChangeRequestRouter(v *gin.RouterGroup, handler handlers.ProductChangeRequestHandler, auth gin.HandlerFunc) ProductRouter {
+	return &productChangeRequestRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *productChangeRequestRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.GET("", middleware.RequireAnyPermission("approve_products", "update_products"), r.handler.GetChangeRequests)
+	r.v.GET("/:id", middleware.RequireAnyPermission("approve_products", "update_products"), r.handler.GetChangeRequest)
+
+	r.v.PUT("/approve/:id", middleware.RequirePermission("approve_products"), r.handler.ApproveChangeRequest)
+	r.v.PUT("/reject/:id", middleware.RequirePermission("approve_products"), r.handler.RejectChangeRequest)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type productExportRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.ProductExportHandler
+	auth    gin.HandlerFunc
+}
+
+func NewProductExportRouter(v *gin.RouterGroup, handler handlers.ProductExportHandler, auth gin.HandlerFunc) ProductRouter {
+	return &productExportRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *productExportRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.GET("", middleware.RequirePermission("export_products"), r.handler.ExportProducts)
+}
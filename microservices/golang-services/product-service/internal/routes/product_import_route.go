@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type productImportRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.ProductImportHandler
+	auth    gin.HandlerFunc
+}
+
+func NewProductImportRouter(v *gin.RouterGroup, handler handlers.ProductImportHandler, auth gin.HandlerFunc) ProductRouter {
+	return &productImportRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *productImportRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.POST("", middleware.RequirePermission("create_products"), r.handler.ImportProducts)
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type productRevisionRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.ProductRevisionHandler
+	auth    gin.HandlerFunc
+}
+
+func NewProductRevisionRouter(v *gin.RouterGroup, handler handlers.ProductRevisionHandler, auth gin.HandlerFunc) ProductRouter {
+	return &productRevisionRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *productRevisionRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.GET("/:id", middleware.RequirePermission("view_all_products"), r.handler.GetProductRevisions)
+	r.v.GET("/:id/diff", middleware.RequirePermission("view_all_products"), r.handler.DiffProductRevisions)
+	r.v.POST("/:id/rollback/:revision_id", middleware.RequirePermission("update_products"), r.handler.RollbackProductRevision)
+}
@@ -15,19 +15,28 @@ type ProductRouter interface {
 type productRouterImpl struct {
 	v       *gin.RouterGroup
 	handler handlers.ProductHandler
+	auth    gin.HandlerFunc
 }
 
-func NewProductRouter(v *gin.RouterGroup, handler handlers.ProductHandler) ProductRouter {
-	return &productRouterImpl{v: v, handler: handler}
+func NewProductRouter(v *gin.RouterGroup, handler handlers.ProductHandler, auth gin.HandlerFunc) ProductRouter {
+	return &productRouterImpl{v: v, handler: handler, auth: auth}
 }
 
 func (r *productRouterImpl) Mount() {
 	r.v.Use(cors.Default())
-	r.v.Use(middleware.AuthMiddleware())
+	r.v.Use(r.auth)
 	r.v.GET("", middleware.RequireAnyPermission("view_all_products", "view_active_products"), r.handler.GetAllProducts)
 
 	r.v.POST("/create", middleware.RequirePermission("create_products"), r.handler.CreateProduct)
 	r.v.PUT("/update/:id", middleware.RequirePermission("update_products"), r.handler.UpdateProduct)
+	r.v.PATCH("/:id", middleware.RequirePermission("update_products"), r.handler.PatchProduct)
 	r.v.PUT("/update-status/:id", middleware.RequirePermission("update_products"), r.handler.UpdateProductStatus)
+	r.v.PUT("/bulk/update", middleware.RequirePermission("update_products"), r.handler.BulkUpdateProducts)
+	r.v.PUT("/bulk/update-status", middleware.RequirePermission("update_products"), r.handler.BulkUpdateProductStatus)
+	r.v.POST("/images/reprocess", middleware.RequirePermission("update_products"), r.handler.ReprocessProductImages)
 	r.v.DELETE("/delete/:id", middleware.RequirePermission("delete_products"), r.handler.DeleteProduct)
+
+	r.v.GET("/trash", middleware.RequirePermission("delete_products"), r.handler.GetTrashedProducts)
+	r.v.PUT("/restore/:id", middleware.RequirePermission("delete_products"), r.handler.RestoreProduct)
+	r.v.DELETE("/purge/:id", middleware.RequirePermission("purge_products"), r.handler.PurgeProduct)
 }
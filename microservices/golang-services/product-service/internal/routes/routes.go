@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers holds every HTTP handler served by the product service.
+type Handlers struct {
+	Product       handlers.ProductHandler
+	Revision      handlers.ProductRevisionHandler
+	Import        handlers.ProductImportHandler
+	Export        handlers.ProductExportHandler
+	ChangeRequest handlers.ProductChangeRequestHandler
+	Job           handlers.JobHandler
+	Webhook       handlers.WebhookHandler
+	GraphQL       handlers.GraphQLHandler
+	Docs          handlers.DocsHandler
+	Health        handlers.HealthHandler
+}
+
+// Options holds what the route groups need besides their handlers.
+type Options struct {
+	// Auth authenticates every API route.
+	Auth gin.HandlerFunc
+	// UploadDir is served under /uploads.
+	UploadDir string
+}
+
+// Mount registers all route groups on g. Every API route added here must be
+// described in internal/docs/openapi.json; the docs and health endpoints
+// are not part of the API.
+func Mount(g *gin.Engine, opts Options, h Handlers) {
+	NewProductRouter(g.Group("/products"), h.Product, opts.Auth).Mount()
+	NewProductRevisionRouter(g.Group("/products/revisions"), h.Revision, opts.Auth).Mount()
+	NewProductImportRouter(g.Group("/products/import"), h.Import, opts.Auth).Mount()
+	NewProductExportRouter(g.Group("/products/export"), h.Export, opts.Auth).Mount()
+	NewProductChangeRequestRouter(g.Group("/products/change-requests"), h.ChangeRequest, opts.Auth).Mount()
+	NewJobRouter(g.Group("/jobs"), h.Job, opts.Auth).Mount()
+	NewWebhookRouter(g.Group("/webhooks"), h.Webhook, opts.Auth).Mount()
+	NewGraphQLRouter(g.Group("/graphql"), h.GraphQL, opts.Auth).Mount()
+	NewDocsRouter(g.Group(""), h.Docs).Mount()
+	NewHealthRouter(g.Group(""), h.Health).Mount()
+
+	g.Static("/uploads", opts.UploadDir)
+}
+
+// MountMetrics registers /metrics on g. It is served on its own listener so
+// the endpoint is never reachable through the public port.
+func MountMetrics(g *gin.Engine, h handlers.MetricsHandler) {
+	NewMetricsRouter(g.Group(""), h).Mount()
+}
@@ -0,0 +1,249 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"product-service/config"
+	"product-service/internal/docs"
+	"product-service/internal/handlers"
+	"product-service/internal/health"
+	"product-service/internal/logging"
+	"product-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+var pathParam = regexp.MustCompile(`[:*](\w+)`)
+
+// mountAll registers every route on g with handlers that have no
+// dependencies; registration never calls them.
+func mountAll(g *gin.Engine) *gin.Engine {
+	auth := middleware.AuthMiddleware(middleware.NewAuthenticator("", nil))
+	Mount(g, Options{Auth: auth, UploadDir: "uploads"}, Handlers{
+		Product:       handlers.NewproductHandler(nil, nil, nil, config.StorageConfig{}, false, logging.Discard()),
+		Revision:      handlers.NewProductRevisionHandler(nil, logging.Discard()),
+		Import:        handlers.NewProductImportHandler(nil, nil),
+		Export:        handlers.NewProductExportHandler(nil, nil, logging.Discard()),
+		ChangeRequest: handlers.NewProductChangeRequestHandler(nil, config.StorageConfig{}, logging.Discard()),
+		Job:           handlers.NewJobHandler(nil, config.StorageConfig{}, logging.Discard()),
+		Webhook:       handlers.NewWebhookHandler(nil, logging.Discard()),
+		GraphQL:       handlers.NewGraphQLHandler(nil),
+		Docs:          handlers.NewDocsHandler(),
+		Health:        handlers.NewHealthHandler(health.NewChecker(time.Second), logging.Discard()),
+	})
+	return g
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(docs.OpenAPI, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+
+	documented := map[string]bool{}
+	gin.SetMode(gin.TestMode)
+	for _, route := range mountAll(gin.New()).Routes() {
+		if route.Method == http.MethodHead || strings.HasPrefix(route.Path, "/uploads/") ||
+			route.Path == "/openapi.json" || strings.HasPrefix(route.Path, "/docs/") ||
+			route.Path == "/healthz" || route.Path == "/readyz" {
+			continue
+		}
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		key := route.Method + " " + path
+		documented[key] = true
+		if _, ok := spec.Paths[path][strings.ToLower(route.Method)]; !ok {
+			t.Errorf("%s is registered but missing from internal/docs/openapi.json", key)
+		}
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			if key := strings.ToUpper(method) + " " + path; !documented[key] {
+				t.Errorf("%s is documented but not registered", key)
+			}
+		}
+	}
+}
+
+func TestDocsRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	g := mountAll(gin.New())
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{"/openapi.json", `"openapi": "3.0.3"`},
+		{"/docs/", "swagger-ui"},
+		{"/docs/swagger-initializer.js", `url: "/openapi.json"`},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d, want %d", tt.path, w.Code, http.StatusOK)
+			continue
+		}
+		if !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("GET %s: body does not contain %q", tt.path, tt.contains)
+		}
+	}
+}
+
+func TestMetricsAreNotServedOnThePublicRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	mountAll(gin.New()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics on the public router: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	g := gin.New()
+	MountMetrics(g, handlers.NewMetricsHandler())
+	w = httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "go_goroutines") {
+		t.Errorf("GET /metrics on the metrics router: status %d, body without go_goroutines", w.Code)
+	}
+}
+
+func TestReadinessHidesDependencyErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checker := health.NewChecker(time.Second)
+	checker.Register("postgres", func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.3.5:5432: connection refused")
+	})
+	g := gin.New()
+	NewHealthRouter(g.Group(""), handlers.NewHealthHandler(checker, logging.Discard())).Mount()
+
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(w.Body.String(), `"postgres":{"status":"error"}`) {
+		t.Errorf("body %s does not report postgres as failing", w.Body)
+	}
+	if strings.Contains(w.Body.String(), "10.0.3.5") {
+		t.Errorf("body %s leaks the dependency error", w.Body)
+	}
+}
+
+func TestOpenAPIValidatorRejectsInvalidRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator, err := middleware.OpenAPIValidator(docs.OpenAPI, middleware.OpenAPIOptions{ValidateResponses: true})
+	if err != nil {
+		t.Fatalf("OpenAPIValidator: %v", err)
+	}
+
+	g := gin.New()
+	g.Use(validator)
+	mountAll(g)
+
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		errors      map[string][]string
+	}{
+		{
+			method: http.MethodGet,
+			path:   "/products?page=first&status=sold",
+			status: http.StatusBadRequest,
+			errors: map[string][]string{
+				"Page":   {"The Page must be an integer."},
+				"Status": {"The Status must be one of: draft, pending_review, active, inactive, archived."},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/products/create",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=ab&description=&price=0&quantity=1",
+			status:      http.StatusBadRequest,
+			errors: map[string][]string{
+				"Name":        {"The Name must be at least 3 characters."},
+				"Description": {"The Description field is required."},
+				"Price":       {"The Price must be greater than 0."},
+			},
+		},
+		{
+			method:      http.MethodPut,
+			path:        "/products/bulk/update",
+			contentType: "application/json",
+			body:        `{"ids":[0],"patch":{"price":{"mode":"double"}}}`,
+			status:      http.StatusBadRequest,
+			errors: map[string][]string{
+				"IDs":   {"The IDs must be greater than or equal to 1."},
+				"Mode":  {"The Mode must be one of: absolute, percentage."},
+				"Value": {"The Value field is required."},
+			},
+		},
+		{
+			method:      http.MethodPatch,
+			path:        "/products/7",
+			contentType: "application/merge-patch+json",
+			body:        `{"sku":"ABC-1"}`,
+			status:      http.StatusBadRequest,
+			errors: map[string][]string{
+				"sku": {"The sku field is not allowed."},
+			},
+		},
+		{
+			method:      http.MethodPut,
+			path:        "/products/update-status/7",
+			contentType: "text/plain",
+			body:        "active",
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.path, w.Code, tt.status, w.Body.String())
+			continue
+		}
+		if tt.errors == nil {
+			continue
+		}
+
+		var resp struct {
+			Error map[string][]string `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s %s: decode response: %v", tt.method, tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(resp.Error, tt.errors) {
+			t.Errorf("%s %s: errors %v, want %v", tt.method, tt.path, resp.Error, tt.errors)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"product-service/internal/handlers"
+	"product-service/internal/middleware"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handlers.WebhookHandler
+	auth    gin.HandlerFunc
+}
+
+func NewWebhookRouter(v *gin.RouterGroup, handler handlers.WebhookHandler, auth gin.HandlerFunc) ProductRouter {
+	return &webhookRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *webhookRouterImpl) Mount() {
+	r.v.Use(cors.Default())
+	r.v.Use(r.auth)
+	r.v.GET("", middleware.RequirePermission("manage_webhooks"), r.handler.GetWebhooks)
+	r.v.GET("/:id", middleware.RequirePermission("manage_webhooks"), r.handler.GetWebhook)
+	r.v.GET("/:id/deliveries", middleware.RequirePermission("manage_webhooks"), r.handler.GetWebhookDeliveries)
+
+	r.v.POST("/create", middleware.RequirePermission("manage_webhooks"), r.handler.CreateWebhook)
+	r.v.PUT("/update/:id", middleware.RequirePermission("manage_webhooks"), r.handler.UpdateWebhook)
+	r.v.PUT("/deliveries/redeliver/:id", middleware.RequirePermission("manage_webhooks"), r.handler.RedeliverWebhook)
+	r.v.DELETE("/delete/:id", middleware.RequirePermission("manage_webhooks"), r.handler.DeleteWebhook)
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"product-service/internal/audit"
+	"product-service/internal/logging"
+	"product-service/internal/middleware"
+	"product-service/proto/productpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodPermissions lists the permissions accepted by each RPC, any one of
+// which is enough. Methods missing from the map are denied.
+var methodPermissions = map[string][]string{
+	productpb.ProductService_ListProducts_FullMethodName:     {"view_all_products", "view_active_products"},
+	productpb.ProductService_GetProduct_FullMethodName:       {"view_all_products", "view_active_products"},
+	productpb.ProductService_BatchGetProducts_FullMethodName: {"view_all_products", "view_active_products"},
+	productpb.ProductService_CreateProduct_FullMethodName:    {"create_products"},
+	productpb.ProductService_UpdateProduct_FullMethodName:    {"update_products"},
+	productpb.ProductService_DeleteProduct_FullMethodName:    {"delete_products"},
+	productpb.ProductService_ReserveStock_FullMethodName:     {"reserve_stock"},
+}
+
+type claimsKey struct{}
+
+// AuthInterceptor is the gRPC counterpart of AuthMiddleware followed by
+// RequireAnyPermission: it authenticates the "authorization" metadata and
+// checks the permissions of the called method. Authentication failures are
+// logged to logger along with the caller's x-request-id, if any.
+func AuthInterceptor(auth middleware.Authenticator, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = logging.WithLogger(ctx, logger.With("method", info.FullMethod))
+
+		permissions, ok := methodPermissions[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, "Permission denied")
+		}
+
+		var authHeader, userAgent string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				authHeader = values[0]
+			}
+			if values := md.Get("user-agent"); len(values) > 0 {
+				userAgent = values[0]
+			}
+			if values := md.Get("x-request-id"); len(values) > 0 && logging.ValidRequestID(values[0]) {
+				ctx = logging.WithRequestID(ctx, values[0])
+			}
+		}
+
+		claims, authErr := auth.Authenticate(ctx, authHeader)
+		if authErr != nil {
+			return nil, status.Error(authCode(authErr.Status), authErr.Message)
+		}
+
+		if !middleware.HasAnyPermission(claims, permissions...) {
+			return nil, status.Error(codes.PermissionDenied, "Permission denied")
+		}
+
+		ctx = audit.WithActor(ctx, audit.Actor{
+			ID:        claims.ID,
+			Email:     claims.Email,
+			IPAddress: peerIP(ctx),
+			UserAgent: userAgent,
+		})
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
+
+// peerIP returns the host part of the caller's address, or "" when unknown.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func claimsFromContext(ctx context.Context) middleware.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(middleware.Claims)
+	return claims
+}
+
+func authCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
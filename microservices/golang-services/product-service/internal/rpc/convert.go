@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"product-service/internal/logging"
+	"product-service/internal/models"
+	"product-service/proto/productpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProto(product *models.Product) *productpb.Product {
+	return &productpb.Product{
+		Id:          uint32(product.ID),
+		Sku:         product.SKU,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Quantity:    int32(product.Quantity),
+		Status:      string(product.Status),
+		ImageUrl:    product.ImageURL,
+		PublishAt:   toTimestamp(product.PublishAt),
+		UnpublishAt: toTimestamp(product.UnpublishAt),
+		CreatedAt:   timestamppb.New(product.CreatedAt),
+		UpdatedAt:   timestamppb.New(product.UpdatedAt),
+	}
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// invalidArgument carries the same field errors as the HTTP validation
+// responses, as BadRequest details.
+func invalidArgument(validationErrors map[string][]string) error {
+	fields := make([]string, 0, len(validationErrors))
+	for field := range validationErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	details := &errdetails.BadRequest{}
+	for _, field := range fields {
+		for _, message := range validationErrors[field] {
+			details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: message,
+			})
+		}
+	}
+
+	st, err := status.New(codes.InvalidArgument, "Validation failed").WithDetails(details)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Validation failed")
+	}
+	return st.Err()
+}
+
+// toStatus maps service errors onto gRPC status codes. Unexpected errors are
+// logged and reported as a bare Internal status, so database and driver
+// details never reach the caller.
+func toStatus(ctx context.Context, err error) error {
+	var stockErr *models.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return status.Error(codes.FailedPrecondition, stockErr.Error())
+	}
+
+	switch err.Error() {
+	case "record not found", "gorm: record not found":
+		return status.Error(codes.NotFound, "Product not found")
+	case "product sku already exists":
+		return status.Error(codes.AlreadyExists, "The SKU has already been taken.")
+	case "product sku belongs to a trashed product":
+		return status.Error(codes.AlreadyExists, "The SKU belongs to a product in the trash; restore or purge it first.")
+	case "product already deleted":
+		return status.Error(codes.FailedPrecondition, "Product already deleted")
+	case "product changed concurrently":
+		return status.Error(codes.Aborted, "Product was changed by another request")
+	default:
+		logging.FromContext(ctx).ErrorContext(ctx, "RPC failed", "error", err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+}
@@ -0,0 +1,269 @@
+package rpc
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+	"product-service/proto/productpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPerPage   = 15
+	maxPerPage       = 100
+	maxBatchGetItems = 100
+)
+
+type productServer struct {
+	productpb.UnimplementedProductServiceServer
+	service    service.ProductService
+	reviewMode bool
+}
+
+// NewProductServer serves the product RPCs from service. With reviewMode
+// enabled, UpdateProduct is refused since edits must go through change
+// requests on the HTTP API.
+func NewProductServer(service service.ProductService, reviewMode bool) productpb.ProductServiceServer {
+	helpers.InitValidator()
+	return &productServer{service: service, reviewMode: reviewMode}
+}
+
+// canViewAll mirrors GetAllProducts: without view_all_products only visible
+// products are returned.
+func canViewAll(ctx context.Context) bool {
+	return middleware.HasAnyPermission(claimsFromContext(ctx), "view_all_products")
+}
+
+func (s *productServer) ListProducts(ctx context.Context, req *productpb.ListProductsRequest) (*productpb.ListProductsResponse, error) {
+	page := int(req.GetPage())
+	if page < 1 {
+		page = 1
+	}
+	perPage := int(req.GetPerPage())
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		return nil, invalidArgument(map[string][]string{
+			"PerPage": {"The PerPage must be less than or equal to " + strconv.Itoa(maxPerPage) + "."},
+		})
+	}
+	offset := (page - 1) * perPage
+
+	var products []models.Product
+	var total int64
+	var err error
+
+	if canViewAll(ctx) {
+		var productStatus *models.ProductStatus
+		if req.GetStatus() != "" {
+			statusVal := models.ProductStatus(req.GetStatus())
+			if !statusVal.IsValid() {
+				return nil, invalidArgument(map[string][]string{
+					"Status": {"The Status must be one of: draft, pending_review, active, inactive, archived."},
+				})
+			}
+			productStatus = &statusVal
+		}
+		products, total, err = s.service.GetAll(ctx, perPage, offset, req.GetSearch(), productStatus)
+	} else {
+		products, total, err = s.service.GetByStatusActive(ctx, perPage, offset, req.GetSearch())
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &productpb.ListProductsResponse{
+		Products:   make([]*productpb.Product, 0, len(products)),
+		Total:      total,
+		Page:       int32(page),
+		PerPage:    int32(perPage),
+		TotalPages: int32((total + int64(perPage) - 1) / int64(perPage)),
+	}
+	for i := range products {
+		resp.Products = append(resp.Products, toProto(&products[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *productServer) GetProduct(ctx context.Context, req *productpb.GetProductRequest) (*productpb.Product, error) {
+	product, err := s.service.GetByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	if !canViewAll(ctx) && !product.IsVisible(time.Now()) {
+		return nil, status.Error(codes.NotFound, "Product not found")
+	}
+
+	return toProto(product), nil
+}
+
+func (s *productServer) BatchGetProducts(ctx context.Context, req *productpb.BatchGetProductsRequest) (*productpb.BatchGetProductsResponse, error) {
+	if len(req.GetIds()) > maxBatchGetItems {
+		return nil, invalidArgument(map[string][]string{
+			"Ids": {"The Ids must contain at most " + strconv.Itoa(maxBatchGetItems) + " item(s)."},
+		})
+	}
+
+	ids := make([]uint, len(req.GetIds()))
+	for i, id := range req.GetIds() {
+		ids[i] = uint(id)
+	}
+
+	products, err := s.service.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	viewAll := canViewAll(ctx)
+	now := time.Now()
+	byID := map[uint]*models.Product{}
+	for i := range products {
+		if viewAll || products[i].IsVisible(now) {
+			byID[products[i].ID] = &products[i]
+		}
+	}
+
+	resp := &productpb.BatchGetProductsResponse{
+		Products:    []*productpb.Product{},
+		NotFoundIds: []uint32{},
+	}
+	for _, id := range req.GetIds() {
+		if product, ok := byID[uint(id)]; ok {
+			resp.Products = append(resp.Products, toProto(product))
+		} else {
+			resp.NotFoundIds = append(resp.NotFoundIds, id)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *productServer) CreateProduct(ctx context.Context, req *productpb.CreateProductRequest) (*productpb.Product, error) {
+	input := models.CreateProductInput{
+		SKU:         req.GetSku(),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+		Quantity:    int(req.GetQuantity()),
+		Status:      req.GetStatus(),
+		PublishAt:   fromTimestamp(req.GetPublishAt()),
+		UnpublishAt: fromTimestamp(req.GetUnpublishAt()),
+	}
+
+	if err := helpers.ValidateStruct(&input); err != nil {
+		return nil, invalidArgument(helpers.ParseValidationErrors(err))
+	}
+	if validationErrors := models.ValidatePublishWindow(input.PublishAt, input.UnpublishAt); validationErrors != nil {
+		return nil, invalidArgument(validationErrors)
+	}
+
+	product := models.Product{
+		SKU:         input.SKU,
+		Name:        input.Name,
+		Description: input.Description,
+		Price:       input.Price,
+		Quantity:    input.Quantity,
+		Status:      models.StatusActive,
+		PublishAt:   input.PublishAt,
+		UnpublishAt: input.UnpublishAt,
+	}
+	if input.Status != "" {
+		product.Status = models.ProductStatus(input.Status)
+	}
+
+	if err := s.service.Create(ctx, &product); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(&product), nil
+}
+
+func (s *productServer) UpdateProduct(ctx context.Context, req *productpb.UpdateProductRequest) (*productpb.Product, error) {
+	if s.reviewMode {
+		return nil, status.Error(codes.FailedPrecondition, "Product updates require review; submit them through the HTTP API")
+	}
+
+	input := models.UpdateProductInput{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+		Quantity:    int(req.GetQuantity()),
+		PublishAt:   fromTimestamp(req.GetPublishAt()),
+		UnpublishAt: fromTimestamp(req.GetUnpublishAt()),
+	}
+
+	if err := helpers.ValidateStruct(&input); err != nil {
+		return nil, invalidArgument(helpers.ParseValidationErrors(err))
+	}
+	if validationErrors := models.ValidatePublishWindow(input.PublishAt, input.UnpublishAt); validationErrors != nil {
+		return nil, invalidArgument(validationErrors)
+	}
+
+	product, err := s.service.GetByIDForUpdate(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	product.Name = input.Name
+	product.Description = input.Description
+	product.Price = input.Price
+	product.Quantity = input.Quantity
+	product.PublishAt = input.PublishAt
+	product.UnpublishAt = input.UnpublishAt
+
+	if err := s.service.Update(ctx, product.ID, product, claimsFromContext(ctx).Email); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(product), nil
+}
+
+func (s *productServer) DeleteProduct(ctx context.Context, req *productpb.DeleteProductRequest) (*productpb.DeleteProductResponse, error) {
+	if err := s.service.Delete(ctx, uint(req.GetId())); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &productpb.DeleteProductResponse{}, nil
+}
+
+func (s *productServer) ReserveStock(ctx context.Context, req *productpb.ReserveStockRequest) (*productpb.ReserveStockResponse, error) {
+	if len(req.GetItems()) == 0 {
+		return nil, invalidArgument(map[string][]string{
+			"Items": {"The Items field is required."},
+		})
+	}
+
+	items := make([]models.StockReservation, len(req.GetItems()))
+	for i, item := range req.GetItems() {
+		items[i] = models.StockReservation{ProductID: uint(item.GetProductId()), Quantity: int(item.GetQuantity())}
+		if err := helpers.ValidateStruct(&items[i]); err != nil {
+			validationErrors := map[string][]string{}
+			for field, messages := range helpers.ParseValidationErrors(err) {
+				validationErrors["Items["+strconv.Itoa(i)+"]."+field] = messages
+			}
+			return nil, invalidArgument(validationErrors)
+		}
+	}
+
+	products, err := s.service.ReserveStock(ctx, items, claimsFromContext(ctx).Email)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &productpb.ReserveStockResponse{Products: make([]*productpb.Product, 0, len(products))}
+	for i := range products {
+		resp.Products = append(resp.Products, toProto(&products[i]))
+	}
+
+	return resp, nil
+}
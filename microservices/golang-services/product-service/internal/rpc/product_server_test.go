@@ -0,0 +1,316 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"product-service/internal/audit"
+	"product-service/internal/middleware"
+	"product-service/internal/models"
+	"product-service/internal/service"
+	"product-service/proto/productpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// tokenAuthenticator accepts the bearer tokens it was built with.
+type tokenAuthenticator map[string]middleware.Claims
+
+func (a tokenAuthenticator) Authenticate(ctx context.Context, authHeader string) (middleware.Claims, *middleware.AuthError) {
+	claims, ok := a["Bearer "+strings.TrimPrefix(authHeader, "Bearer ")]
+	if !ok || !strings.HasPrefix(authHeader, "Bearer ") {
+		return middleware.Claims{}, &middleware.AuthError{Status: http.StatusUnauthorized, Message: "Unauthorized"}
+	}
+	return claims, nil
+}
+
+var testTokens = tokenAuthenticator{
+	"Bearer admin": {
+		UserClaims:  middleware.UserClaims{ID: "1", Email: "admin@example.com"},
+		Permissions: []string{"view_all_products", "create_products", "update_products", "delete_products", "reserve_stock"},
+	},
+	"Bearer shopper": {
+		UserClaims:  middleware.UserClaims{ID: "2", Email: "shopper@example.com"},
+		Permissions: []string{"view_active_products"},
+	},
+}
+
+// fakeProductService records the calls made by the server, along with the
+// audit actor of their context.
+type fakeProductService struct {
+	service.ProductService
+
+	mu      sync.Mutex
+	calls   []string
+	actors  []audit.Actor
+	perPage int
+	product *models.Product
+	err     error
+}
+
+func (f *fakeProductService) record(ctx context.Context, call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	f.actors = append(f.actors, audit.ActorFromContext(ctx))
+}
+
+func (f *fakeProductService) GetAll(ctx context.Context, limit, offset int, search string, status *models.ProductStatus) ([]models.Product, int64, error) {
+	f.record(ctx, "GetAll")
+	f.perPage = limit
+	return []models.Product{{ID: 1}}, 1, f.err
+}
+
+func (f *fakeProductService) GetByStatusActive(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error) {
+	f.record(ctx, "GetByStatusActive")
+	f.perPage = limit
+	return []models.Product{{ID: 1}}, 1, f.err
+}
+
+func (f *fakeProductService) GetByID(ctx context.Context, id uint) (*models.Product, error) {
+	f.record(ctx, "GetByID")
+	return f.get()
+}
+
+func (f *fakeProductService) GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	f.record(ctx, "GetByIDForUpdate")
+	return f.get()
+}
+
+func (f *fakeProductService) get() (*models.Product, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	copied := *f.product
+	return &copied, nil
+}
+
+func (f *fakeProductService) Create(ctx context.Context, product *models.Product) error {
+	f.record(ctx, "Create")
+	product.ID = 9
+	return f.err
+}
+
+func (f *fakeProductService) Update(ctx context.Context, id uint, product *models.Product, actor string) error {
+	f.record(ctx, "Update")
+	return f.err
+}
+
+func (f *fakeProductService) Delete(ctx context.Context, id uint) error {
+	f.record(ctx, "Delete")
+	return f.err
+}
+
+func (f *fakeProductService) ReserveStock(ctx context.Context, items []models.StockReservation, actor string) ([]models.Product, error) {
+	f.record(ctx, "ReserveStock")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []models.Product{{ID: items[0].ProductID, Quantity: 1}}, nil
+}
+
+// newTestClient serves svc over an in-memory connection behind the same
+// interceptors as the real server. Server logs are written to logs.
+func newTestClient(t *testing.T, svc service.ProductService, reviewMode bool, logs *bytes.Buffer) productpb.ProductServiceClient {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(logs, nil))
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthInterceptor(testTokens, logger), ReadSessionInterceptor()))
+	productpb.RegisterProductServiceServer(server, NewProductServer(svc, reviewMode))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return productpb.NewProductServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client := newTestClient(t, &fakeProductService{}, false, &bytes.Buffer{})
+
+	_, err := client.ListProducts(context.Background(), &productpb.ListProductsRequest{})
+	if code := status.Code(err); code != codes.Unauthenticated {
+		t.Errorf("without token: code = %v, want Unauthenticated", code)
+	}
+
+	_, err = client.DeleteProduct(withToken("shopper"), &productpb.DeleteProductRequest{Id: 1})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("without permission: code = %v, want PermissionDenied", code)
+	}
+}
+
+func TestListProductsPerPage(t *testing.T) {
+	tests := []struct {
+		perPage int32
+		want    int
+		code    codes.Code
+	}{
+		{perPage: 0, want: defaultPerPage, code: codes.OK},
+		{perPage: 100, want: 100, code: codes.OK},
+		{perPage: 101, code: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeProductService{}
+		client := newTestClient(t, svc, false, &bytes.Buffer{})
+
+		resp, err := client.ListProducts(withToken("admin"), &productpb.ListProductsRequest{PerPage: tt.perPage})
+		if code := status.Code(err); code != tt.code {
+			t.Errorf("per_page %d: code = %v, want %v", tt.perPage, code, tt.code)
+			continue
+		}
+		if err == nil && (svc.perPage != tt.want || int(resp.GetPerPage()) != tt.want) {
+			t.Errorf("per_page %d: queried %d and reported %d, want %d", tt.perPage, svc.perPage, resp.GetPerPage(), tt.want)
+		}
+	}
+}
+
+func TestListProductsHonoursVisibility(t *testing.T) {
+	svc := &fakeProductService{}
+	client := newTestClient(t, svc, false, &bytes.Buffer{})
+
+	if _, err := client.ListProducts(withToken("shopper"), &productpb.ListProductsRequest{}); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if _, err := client.ListProducts(withToken("admin"), &productpb.ListProductsRequest{}); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+
+	if want := []string{"GetByStatusActive", "GetAll"}; strings.Join(svc.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", svc.calls, want)
+	}
+}
+
+func TestCreateProductValidation(t *testing.T) {
+	svc := &fakeProductService{}
+	client := newTestClient(t, svc, false, &bytes.Buffer{})
+
+	_, err := client.CreateProduct(withToken("admin"), &productpb.CreateProductRequest{Price: -1})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+
+	fields := map[string]bool{}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				fields[violation.GetField()] = true
+			}
+		}
+	}
+	if !fields["Name"] || !fields["Price"] {
+		t.Errorf("field violations = %v, want Name and Price", fields)
+	}
+	if len(svc.calls) != 0 {
+		t.Errorf("service called with invalid input: %v", svc.calls)
+	}
+}
+
+func TestMutationsCarryTheCallerForAuditing(t *testing.T) {
+	svc := &fakeProductService{product: &models.Product{ID: 3, Name: "Mug", Price: 5}}
+	client := newTestClient(t, svc, false, &bytes.Buffer{})
+	ctx := withToken("admin")
+
+	if _, err := client.CreateProduct(ctx, &productpb.CreateProductRequest{Sku: "MUG", Name: "Mug", Description: "Stoneware", Price: 5, Quantity: 4}); err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if _, err := client.UpdateProduct(ctx, &productpb.UpdateProductRequest{Id: 3, Name: "Mug", Description: "Stoneware", Price: 6, Quantity: 4}); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if _, err := client.DeleteProduct(ctx, &productpb.DeleteProductRequest{Id: 3}); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	if _, err := client.ReserveStock(ctx, &productpb.ReserveStockRequest{Items: []*productpb.StockReservation{{ProductId: 3, Quantity: 1}}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+
+	for i, call := range svc.calls {
+		actor := svc.actors[i]
+		if actor.ID != "1" || actor.Email != "admin@example.com" || actor.IPAddress == "" || !strings.HasPrefix(actor.UserAgent, "grpc-go/") {
+			t.Errorf("%s ran as %+v, want the authenticated caller", call, actor)
+		}
+	}
+}
+
+func TestUpdateProductReadsThePrimary(t *testing.T) {
+	svc := &fakeProductService{product: &models.Product{ID: 3, Name: "Mug", Price: 5}}
+	client := newTestClient(t, svc, false, &bytes.Buffer{})
+
+	if _, err := client.UpdateProduct(withToken("admin"), &productpb.UpdateProductRequest{Id: 3, Name: "Mug", Description: "Stoneware", Price: 6, Quantity: 4}); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+
+	// The product is saved back, so it must not come from a lagging replica.
+	if len(svc.calls) == 0 || svc.calls[0] != "GetByIDForUpdate" {
+		t.Errorf("calls = %v, want the product loaded with GetByIDForUpdate", svc.calls)
+	}
+}
+
+func TestUpdateProductRefusedInReviewMode(t *testing.T) {
+	svc := &fakeProductService{product: &models.Product{ID: 3}}
+	client := newTestClient(t, svc, true, &bytes.Buffer{})
+
+	_, err := client.UpdateProduct(withToken("admin"), &productpb.UpdateProductRequest{Id: 3, Name: "Mug", Description: "Stoneware", Price: 6, Quantity: 4})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf("code = %v, want FailedPrecondition", code)
+	}
+	if len(svc.calls) != 0 {
+		t.Errorf("service called in review mode: %v", svc.calls)
+	}
+}
+
+func TestServiceErrorsMapToCodes(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{err: errors.New("record not found"), code: codes.NotFound, message: "Product not found"},
+		{err: errors.New("product sku already exists"), code: codes.AlreadyExists},
+		{err: &models.InsufficientStockError{ProductID: 3, Requested: 2, Available: 1}, code: codes.FailedPrecondition},
+		{err: errors.New(`pq: password authentication failed for user "products"`), code: codes.Internal, message: "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		var logs bytes.Buffer
+		client := newTestClient(t, &fakeProductService{err: tt.err}, false, &logs)
+
+		_, err := client.ReserveStock(withToken("admin"), &productpb.ReserveStockRequest{Items: []*productpb.StockReservation{{ProductId: 3, Quantity: 2}}})
+		st := status.Convert(err)
+		if st.Code() != tt.code {
+			t.Errorf("%v: code = %v, want %v", tt.err, st.Code(), tt.code)
+		}
+		if tt.message != "" && st.Message() != tt.message {
+			t.Errorf("%v: message = %q, want %q", tt.err, st.Message(), tt.message)
+		}
+		if tt.code == codes.Internal && !strings.Contains(logs.String(), "password authentication failed") {
+			t.Errorf("internal error was not logged: %q", logs.String())
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package rpc
+
+import (
+	"context"
+
+	"product-service/config"
+
+	"google.golang.org/grpc"
+)
+
+// ReadSessionInterceptor is the gRPC counterpart of middleware.ReadSession.
+func ReadSessionInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(config.WithReadSession(ctx), req)
+	}
+}
@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	outboxBatchSize      = 100
+	outboxStreamMaxLen   = 100000
+	outboxPruneInterval  = time.Hour
+	outboxPruneBatchSize = 1000
+)
+
+type OutboxRelay interface {
+	Start(ctx context.Context)
+}
+
+type outboxRelayImpl struct {
+	repo      repository.OutboxRepository
+	client    *redis.Client
+	stream    string
+	lock      LeaderLock
+	interval  time.Duration
+	retention time.Duration
+	lastPrune time.Time
+	logger    *slog.Logger
+}
+
+// NewOutboxRelay publishes stored outbox events to the Redis stream. Only the
+// replica holding lock relays, so events leave in the order they were written
+// and the events of a product reach the stream in the order it changed. The
+// leader also deletes events published longer than retention ago, hourly;
+// a retention of 0 keeps them.
+func NewOutboxRelay(repo repository.OutboxRepository, client *redis.Client, stream string, lock LeaderLock, interval, retention time.Duration, logger *slog.Logger) OutboxRelay {
+	return &outboxRelayImpl{
+		repo:      repo,
+		client:    client,
+		stream:    stream,
+		lock:      lock,
+		interval:  interval,
+		retention: retention,
+		logger:    logger.With("component", "outbox_relay"),
+	}
+}
+
+func (r *outboxRelayImpl) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			if err := r.lock.Release(context.Background()); err != nil {
+				r.logger.WarnContext(ctx, "Failed to release leader lock", "error", err)
+			}
+			return
+		case <-ticker.C:
+			r.run(ctx)
+		}
+	}
+}
+
+func (r *outboxRelayImpl) run(ctx context.Context) {
+	leader, err := r.lock.Acquire(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to acquire leader lock", "error", err)
+		return
+	}
+	if !leader {
+		return
+	}
+
+	if r.relay(ctx) {
+		r.prune(ctx)
+	}
+}
+
+// relay publishes pending events in batches and reports whether it caught
+// up while still holding the lock.
+func (r *outboxRelayImpl) relay(ctx context.Context) bool {
+	for {
+		events, err := r.repo.GetUnpublished(ctx, outboxBatchSize)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to load outbox events", "error", err)
+			return false
+		}
+
+		for i := range events {
+			// Stop at the first failure so later events of the same product
+			// are never published ahead of it.
+			if err := r.publish(ctx, &events[i]); err != nil {
+				r.logger.ErrorContext(ctx, "Failed to publish outbox event", "event_id", events[i].ID, "error", err)
+				return false
+			}
+		}
+
+		if len(events) < outboxBatchSize {
+			return true
+		}
+		if !r.renew(ctx) {
+			return false
+		}
+	}
+}
+
+// prune deletes published events older than the retention in batches, at
+// most once per outboxPruneInterval.
+func (r *outboxRelayImpl) prune(ctx context.Context) {
+	if r.retention <= 0 || time.Since(r.lastPrune) < outboxPruneInterval {
+		return
+	}
+	r.lastPrune = time.Now()
+
+	before := time.Now().Add(-r.retention)
+	var total int64
+	for {
+		deleted, err := r.repo.DeletePublishedBefore(ctx, before, outboxPruneBatchSize)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to prune outbox events", "error", err)
+			break
+		}
+		total += deleted
+		if deleted < outboxPruneBatchSize || !r.renew(ctx) {
+			break
+		}
+	}
+
+	if total > 0 {
+		r.logger.InfoContext(ctx, "Pruned published outbox events", "count", total, "published_before", before)
+	}
+}
+
+// renew extends the lease before every further batch, so a long backlog
+// cannot outlast it and let another replica relay the same events.
+func (r *outboxRelayImpl) renew(ctx context.Context) bool {
+	leader, err := r.lock.Acquire(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to renew leader lock", "error", err)
+		return false
+	}
+	if !leader {
+		r.logger.WarnContext(ctx, "Lost leader lock, stopping until the next run")
+	}
+	return leader
+}
+
+// publish adds event to the stream and then marks it published. A crash in
+// between publishes the event again on the next run, so consumers should
+// deduplicate on event_id.
+func (r *outboxRelayImpl) publish(ctx context.Context, event *models.OutboxEvent) error {
+	err := r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: outboxStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"event_id":       strconv.FormatUint(event.ID, 10),
+			"event_type":     event.EventType,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   strconv.FormatUint(uint64(event.AggregateID), 10),
+			"payload":        event.Payload,
+			"occurred_at":    event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}).Err()
+	if err != nil {
+		return err
+	}
+
+	return r.repo.MarkPublished(ctx, event.ID, time.Now())
+}
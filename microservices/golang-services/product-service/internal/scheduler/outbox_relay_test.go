@@ -0,0 +1,278 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"product-service/internal/logging"
+	"product-service/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testOutboxStream = "product-service:events"
+
+// fakeOutboxRepository keeps events in memory in id order.
+type fakeOutboxRepository struct {
+	events      []models.OutboxEvent
+	failMark    uint64
+	loads       int
+	deleteCalls int
+	deleted     []int64
+}
+
+func (r *fakeOutboxRepository) GetUnpublished(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	r.loads++
+	pending := []models.OutboxEvent{}
+	for _, event := range r.events {
+		if event.PublishedAt == nil && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeOutboxRepository) MarkPublished(ctx context.Context, id uint64, publishedAt time.Time) error {
+	if id == r.failMark {
+		return errors.New("connection reset")
+	}
+	for i := range r.events {
+		if r.events[i].ID == id {
+			r.events[i].PublishedAt = &publishedAt
+		}
+	}
+	return nil
+}
+
+// DeletePublishedBefore answers with the counts in deleted in turn.
+func (r *fakeOutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.deleteCalls++
+	if len(r.deleted) == 0 {
+		return 0, nil
+	}
+	count := r.deleted[0]
+	r.deleted = r.deleted[1:]
+	return count, nil
+}
+
+func (r *fakeOutboxRepository) published() []uint64 {
+	ids := []uint64{}
+	for _, event := range r.events {
+		if event.PublishedAt != nil {
+			ids = append(ids, event.ID)
+		}
+	}
+	return ids
+}
+
+// fakeLeaderLock answers Acquire with leader in turn, then with true.
+type fakeLeaderLock struct {
+	leader   []bool
+	acquires int
+}
+
+func (l *fakeLeaderLock) Acquire(ctx context.Context) (bool, error) {
+	l.acquires++
+	if len(l.leader) == 0 {
+		return true, nil
+	}
+	leader := l.leader[0]
+	l.leader = l.leader[1:]
+	return leader, nil
+}
+
+func (l *fakeLeaderLock) Release(ctx context.Context) error {
+	return nil
+}
+
+func newOutboxEvents(n int) []models.OutboxEvent {
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := make([]models.OutboxEvent, n)
+	for i := range events {
+		events[i] = models.OutboxEvent{
+			ID:            uint64(i + 1),
+			AggregateType: models.AggregateProduct,
+			AggregateID:   uint(i%3 + 1),
+			EventType:     models.EventProductUpdated,
+			Payload:       fmt.Sprintf(`{"n":%d}`, i+1),
+			CreatedAt:     created.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return events
+}
+
+func newTestOutboxRelay(t *testing.T, repo *fakeOutboxRepository, lock *fakeLeaderLock, retention time.Duration) (*outboxRelayImpl, *miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	relay := NewOutboxRelay(repo, client, testOutboxStream, lock, time.Hour, retention, logging.Discard()).(*outboxRelayImpl)
+	return relay, mr, client
+}
+
+func streamEventIDs(t *testing.T, client *redis.Client) []string {
+	t.Helper()
+	entries, err := client.XRange(context.Background(), testOutboxStream, "-", "+").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, entry := range entries {
+		ids = append(ids, entry.Values["event_id"].(string))
+	}
+	return ids
+}
+
+func TestOutboxRelayPublishesInOrder(t *testing.T) {
+	repo := &fakeOutboxRepository{events: newOutboxEvents(3)}
+	relay, _, client := newTestOutboxRelay(t, repo, &fakeLeaderLock{}, 0)
+
+	relay.run(context.Background())
+
+	if got := fmt.Sprint(streamEventIDs(t, client)); got != "[1 2 3]" {
+		t.Errorf("stream holds events %s, want [1 2 3]", got)
+	}
+	if got := fmt.Sprint(repo.published()); got != "[1 2 3]" {
+		t.Errorf("marked %s published, want [1 2 3]", got)
+	}
+
+	entries, err := client.XRange(context.Background(), testOutboxStream, "-", "+").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"event_id":       "1",
+		"event_type":     models.EventProductUpdated,
+		"aggregate_type": models.AggregateProduct,
+		"aggregate_id":   "1",
+		"payload":        `{"n":1}`,
+		"occurred_at":    "2025-06-01T12:00:00Z",
+	}
+	for field, value := range want {
+		if entries[0].Values[field] != value {
+			t.Errorf("%s = %v, want %v", field, entries[0].Values[field], value)
+		}
+	}
+
+	// Published events are not relayed again.
+	relay.run(context.Background())
+	if got := len(streamEventIDs(t, client)); got != 3 {
+		t.Errorf("stream holds %d entries after a second run, want 3", got)
+	}
+}
+
+func TestOutboxRelayStopsAtFirstFailure(t *testing.T) {
+	t.Run("mark published", func(t *testing.T) {
+		repo := &fakeOutboxRepository{events: newOutboxEvents(4), failMark: 2}
+		relay, _, client := newTestOutboxRelay(t, repo, &fakeLeaderLock{}, time.Hour)
+
+		relay.run(context.Background())
+
+		// Event 2 reached the stream but stays pending, so it is sent again,
+		// and nothing after it goes out first.
+		if got := fmt.Sprint(streamEventIDs(t, client)); got != "[1 2]" {
+			t.Errorf("stream holds events %s, want [1 2]", got)
+		}
+		if got := fmt.Sprint(repo.published()); got != "[1]" {
+			t.Errorf("marked %s published, want [1]", got)
+		}
+		if repo.deleteCalls != 0 {
+			t.Error("pruned without catching up")
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		repo := &fakeOutboxRepository{events: newOutboxEvents(2)}
+		relay, mr, _ := newTestOutboxRelay(t, repo, &fakeLeaderLock{}, 0)
+		if err := mr.Set(testOutboxStream, "not a stream"); err != nil {
+			t.Fatal(err)
+		}
+
+		relay.run(context.Background())
+
+		if got := len(repo.published()); got != 0 {
+			t.Errorf("marked %d events published when XADD failed", got)
+		}
+	})
+}
+
+func TestOutboxRelayRenewsLeaseBetweenBatches(t *testing.T) {
+	repo := &fakeOutboxRepository{events: newOutboxEvents(2*outboxBatchSize + 10)}
+	lock := &fakeLeaderLock{}
+	relay, _, client := newTestOutboxRelay(t, repo, lock, 0)
+
+	relay.run(context.Background())
+
+	if got := len(streamEventIDs(t, client)); got != 2*outboxBatchSize+10 {
+		t.Errorf("published %d events, want all %d", got, 2*outboxBatchSize+10)
+	}
+	if repo.loads != 3 {
+		t.Errorf("loaded %d batches, want 3", repo.loads)
+	}
+	// One acquisition for the run and one renewal before each further batch.
+	if lock.acquires != 3 {
+		t.Errorf("acquired the lock %d times, want 3", lock.acquires)
+	}
+}
+
+func TestOutboxRelayStopsWhenLeaseIsLost(t *testing.T) {
+	repo := &fakeOutboxRepository{events: newOutboxEvents(2 * outboxBatchSize)}
+	lock := &fakeLeaderLock{leader: []bool{true, false}}
+	relay, _, client := newTestOutboxRelay(t, repo, lock, time.Hour)
+
+	relay.run(context.Background())
+
+	if got := len(streamEventIDs(t, client)); got != outboxBatchSize {
+		t.Errorf("published %d events, want the first batch of %d", got, outboxBatchSize)
+	}
+	if repo.deleteCalls != 0 {
+		t.Error("pruned after losing the lease")
+	}
+}
+
+func TestOutboxRelaySkipsWhenNotLeader(t *testing.T) {
+	repo := &fakeOutboxRepository{events: newOutboxEvents(1)}
+	relay, _, _ := newTestOutboxRelay(t, repo, &fakeLeaderLock{leader: []bool{false}}, time.Hour)
+
+	relay.run(context.Background())
+
+	if repo.loads != 0 || repo.deleteCalls != 0 {
+		t.Errorf("follower loaded %d batches and pruned %d times", repo.loads, repo.deleteCalls)
+	}
+}
+
+func TestOutboxRelayPrunesPublishedEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		deleted   []int64
+		calls     int
+	}{
+		{"disabled", 0, nil, 0},
+		{"nothing to prune", 24 * time.Hour, nil, 1},
+		{"single batch", 24 * time.Hour, []int64{12}, 1},
+		{"several batches", 24 * time.Hour, []int64{outboxPruneBatchSize, outboxPruneBatchSize, 5}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeOutboxRepository{deleted: tt.deleted}
+			relay, _, _ := newTestOutboxRelay(t, repo, &fakeLeaderLock{}, tt.retention)
+
+			relay.run(context.Background())
+			if repo.deleteCalls != tt.calls {
+				t.Errorf("deleted %d times, want %d", repo.deleteCalls, tt.calls)
+			}
+
+			// Pruning runs at most once per outboxPruneInterval.
+			relay.run(context.Background())
+			if repo.deleteCalls != tt.calls {
+				t.Errorf("deleted %d times after a second run, want still %d", repo.deleteCalls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"product-service/internal/service"
+)
+
+type PublishScheduler interface {
+	Start(ctx context.Context)
+}
+
+type publishSchedulerImpl struct {
+	service  service.ProductService
+	lock     LeaderLock
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewPublishScheduler(service service.ProductService, lock LeaderLock, interval time.Duration, logger *slog.Logger) PublishScheduler {
+	return &publishSchedulerImpl{
+		service:  service,
+		lock:     lock,
+		interval: interval,
+		logger:   logger.With("component", "publish_scheduler"),
+	}
+}
+
+func (p *publishSchedulerImpl) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			if err := p.lock.Release(context.Background()); err != nil {
+				p.logger.WarnContext(ctx, "Failed to release leader lock", "error", err)
+			}
+			return
+		case <-ticker.C:
+			p.run(ctx)
+		}
+	}
+}
+
+func (p *publishSchedulerImpl) run(ctx context.Context) {
+	leader, err := p.lock.Acquire(ctx)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to acquire leader lock", "error", err)
+		return
+	}
+	if !leader {
+		return
+	}
+
+	published, unpublished, err := p.service.ApplyPublishSchedule(ctx, time.Now())
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to apply publish schedule", "error", err)
+	}
+	if published > 0 || unpublished > 0 {
+		p.logger.InfoContext(ctx, "Applied publish schedule", "published", published, "unpublished", unpublished)
+	}
+}
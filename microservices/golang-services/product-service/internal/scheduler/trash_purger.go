@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"product-service/config"
+	"product-service/internal/service"
+	"product-service/pkg/helpers"
+)
+
+type TrashPurger interface {
+	Start(ctx context.Context)
+}
+
+type trashPurgerImpl struct {
+	service   service.ProductService
+	lock      LeaderLock
+	retention time.Duration
+	interval  time.Duration
+	storage   config.StorageConfig
+	logger    *slog.Logger
+}
+
+// NewTrashPurger returns a purger that only runs on the replica holding lock,
+// so replicas never delete the same products and images twice.
+func NewTrashPurger(service service.ProductService, lock LeaderLock, retention, interval time.Duration, storage config.StorageConfig, logger *slog.Logger) TrashPurger {
+	return &trashPurgerImpl{
+		service:   service,
+		lock:      lock,
+		retention: retention,
+		interval:  interval,
+		storage:   storage,
+		logger:    logger.With("component", "trash_purger"),
+	}
+}
+
+func (p *trashPurgerImpl) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			if err := p.lock.Release(context.Background()); err != nil {
+				p.logger.WarnContext(ctx, "Failed to release leader lock", "error", err)
+			}
+			return
+		case <-ticker.C:
+			p.run(ctx)
+		}
+	}
+}
+
+func (p *trashPurgerImpl) run(ctx context.Context) {
+	leader, err := p.lock.Acquire(ctx)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to acquire leader lock", "error", err)
+		return
+	}
+	if !leader {
+		return
+	}
+
+	purged, err := p.service.PurgeExpired(ctx, p.retention)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to purge expired products", "error", err)
+	}
+	if len(purged) == 0 {
+		return
+	}
+
+	s3Uploader, err := helpers.NewS3Uploader(
+		p.storage.S3Bucket,
+		p.storage.S3Folder,
+		p.logger,
+		nil,
+	)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to initialize S3 uploader", "error", err)
+		return
+	}
+
+	for _, product := range purged {
+		if err := s3Uploader.DeleteStoredFile(ctx, product.ImageURL, p.storage.ProductUploadDir()); err != nil {
+			p.logger.WarnContext(ctx, "Failed to delete product image", "product_id", product.ID, "error", err)
+		}
+	}
+
+	p.logger.InfoContext(ctx, "Purged expired products", "count", len(purged), "retention", p.retention)
+}
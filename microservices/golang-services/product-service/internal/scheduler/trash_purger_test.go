@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"product-service/config"
+	"product-service/internal/audit"
+	"product-service/internal/logging"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/service"
+	"product-service/internal/testdb"
+)
+
+const testTrashRetention = 30 * 24 * time.Hour
+
+type trashFixture struct {
+	db      config.GormPostgres
+	storage config.StorageConfig
+	svc     service.ProductService
+}
+
+func newTrashFixture(t *testing.T) *trashFixture {
+	t.Helper()
+	db := testdb.Open(t)
+	storage := config.StorageConfig{UploadDir: t.TempDir()}
+	if err := os.MkdirAll(storage.ProductUploadDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewProductService(repository.NewProductRepository(db), audit.NewHTTPLogger("", nil, audit.DefaultOptions(), logging.Discard()))
+	return &trashFixture{db: db, storage: storage, svc: svc}
+}
+
+// trash creates a product with a stored image and deletes it age ago.
+func (f *trashFixture) trash(t *testing.T, name string, age time.Duration) *models.Product {
+	t.Helper()
+	ctx := context.Background()
+	if err := os.WriteFile(filepath.Join(f.storage.ProductUploadDir(), name+".png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	product := &models.Product{Name: name, Price: 10, Quantity: 1, ImageURL: "/uploads/products/" + name + ".png"}
+	if err := f.svc.Create(ctx, product); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.svc.Delete(ctx, product.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.db.GetConnection().Unscoped().Model(&models.Product{}).Where("id = ?", product.ID).
+		Update("deleted_at", time.Now().Add(-age)).Error; err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func (f *trashFixture) stored(t *testing.T, product *models.Product) bool {
+	t.Helper()
+	var count int64
+	if err := f.db.GetConnection().Unscoped().Model(&models.Product{}).Where("id = ?", product.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count == 1
+}
+
+func (f *trashFixture) imageKept(product *models.Product) bool {
+	_, err := os.Stat(filepath.Join(f.storage.ProductUploadDir(), filepath.Base(product.ImageURL)))
+	return err == nil
+}
+
+func TestTrashPurgerRemovesExpiredProducts(t *testing.T) {
+	f := newTrashFixture(t)
+	expired := f.trash(t, "expired", 40*24*time.Hour)
+	recent := f.trash(t, "recent", 24*time.Hour)
+
+	purger := NewTrashPurger(f.svc, &fakeLeaderLock{}, testTrashRetention, time.Hour, f.storage, logging.Discard()).(*trashPurgerImpl)
+	purger.run(context.Background())
+
+	if f.stored(t, expired) || f.imageKept(expired) {
+		t.Error("product past the retention was not purged with its image")
+	}
+	if !f.stored(t, recent) || !f.imageKept(recent) {
+		t.Error("product within the retention was purged")
+	}
+
+	var events int64
+	if err := f.db.GetConnection().Model(&models.OutboxEvent{}).
+		Where("aggregate_id = ? AND event_type = ?", expired.ID, models.EventProductPurged).Count(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if events != 1 {
+		t.Errorf("recorded %d purge events, want 1", events)
+	}
+}
+
+func TestTrashPurgerSkipsWhenNotLeader(t *testing.T) {
+	f := newTrashFixture(t)
+	expired := f.trash(t, "expired", 40*24*time.Hour)
+
+	purger := NewTrashPurger(f.svc, &fakeLeaderLock{leader: []bool{false}}, testTrashRetention, time.Hour, f.storage, logging.Discard()).(*trashPurgerImpl)
+	purger.run(context.Background())
+
+	if !f.stored(t, expired) || !f.imageKept(expired) {
+		t.Error("follower purged a product")
+	}
+}
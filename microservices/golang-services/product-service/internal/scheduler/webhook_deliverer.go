@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"product-service/internal/service"
+)
+
+type WebhookDeliverer interface {
+	Start(ctx context.Context)
+}
+
+type webhookDelivererImpl struct {
+	service  service.WebhookService
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewWebhookDeliverer sends due webhook deliveries every interval. Deliveries
+// are claimed with row locks, so every replica can run one.
+func NewWebhookDeliverer(service service.WebhookService, interval time.Duration, logger *slog.Logger) WebhookDeliverer {
+	return &webhookDelivererImpl{
+		service:  service,
+		interval: interval,
+		logger:   logger.With("component", "webhook_deliverer"),
+	}
+}
+
+func (w *webhookDelivererImpl) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *webhookDelivererImpl) run(ctx context.Context) {
+	for {
+		sent, err := w.service.DeliverDue(ctx, time.Now())
+		if err != nil {
+			w.logger.ErrorContext(ctx, "Failed to deliver webhooks", "error", err)
+			return
+		}
+		if sent == 0 {
+			return
+		}
+	}
+}
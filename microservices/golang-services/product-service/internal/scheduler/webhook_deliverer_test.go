@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"product-service/internal/logging"
+	"product-service/internal/service"
+)
+
+// fakeWebhookService answers DeliverDue with results in turn, then with 0.
+type fakeWebhookService struct {
+	service.WebhookService
+
+	mu      sync.Mutex
+	results []deliverResult
+	calls   int
+}
+
+type deliverResult struct {
+	sent int
+	err  error
+}
+
+func (f *fakeWebhookService) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.results) == 0 {
+		return 0, nil
+	}
+	result := f.results[0]
+	f.results = f.results[1:]
+	return result.sent, result.err
+}
+
+func (f *fakeWebhookService) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestWebhookDelivererDrainsDueDeliveries(t *testing.T) {
+	svc := &fakeWebhookService{results: []deliverResult{{sent: 50}, {sent: 50}, {sent: 3}}}
+	deliverer := NewWebhookDeliverer(svc, time.Hour, logging.Discard()).(*webhookDelivererImpl)
+
+	deliverer.run(context.Background())
+
+	// Batches are claimed until one comes back empty.
+	if got := svc.callCount(); got != 4 {
+		t.Errorf("DeliverDue called %d times, want 4", got)
+	}
+}
+
+func TestWebhookDelivererStopsOnError(t *testing.T) {
+	svc := &fakeWebhookService{results: []deliverResult{{sent: 50}, {err: errors.New("connection refused")}, {sent: 50}}}
+	deliverer := NewWebhookDeliverer(svc, time.Hour, logging.Discard()).(*webhookDelivererImpl)
+
+	deliverer.run(context.Background())
+
+	if got := svc.callCount(); got != 2 {
+		t.Errorf("DeliverDue called %d times, want 2", got)
+	}
+}
+
+func TestWebhookDelivererRunsEveryInterval(t *testing.T) {
+	svc := &fakeWebhookService{}
+	deliverer := NewWebhookDeliverer(svc, 10*time.Millisecond, logging.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		deliverer.Start(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for svc.callCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after ctx was cancelled")
+	}
+	if got := svc.callCount(); got < 3 {
+		t.Errorf("DeliverDue called %d times in 5s, want at least 3", got)
+	}
+}
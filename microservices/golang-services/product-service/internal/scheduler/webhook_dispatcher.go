@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	webhookConsumerGroup = "product-service:webhooks"
+	webhookReadCount     = 100
+	webhookReadBlock     = 5 * time.Second
+	webhookClaimIdle     = 5 * time.Minute
+)
+
+type WebhookDispatcher interface {
+	Start(ctx context.Context)
+}
+
+type webhookDispatcherImpl struct {
+	service  service.WebhookService
+	client   *redis.Client
+	stream   string
+	consumer string
+	logger   *slog.Logger
+}
+
+// NewWebhookDispatcher reads product events from the outbox stream through a
+// consumer group and turns them into webhook deliveries. Entries are only
+// acknowledged once their deliveries are stored, and entries left pending by
+// a replica that went away are claimed after webhookClaimIdle.
+func NewWebhookDispatcher(service service.WebhookService, client *redis.Client, stream string, logger *slog.Logger) WebhookDispatcher {
+	hostname, _ := os.Hostname()
+	return &webhookDispatcherImpl{
+		service:  service,
+		client:   client,
+		stream:   stream,
+		consumer: hostname,
+		logger:   logger.With("component", "webhook_dispatcher"),
+	}
+}
+
+func (d *webhookDispatcherImpl) Start(ctx context.Context) {
+	// Keep trying while Redis is unreachable so dispatching starts once it
+	// comes back.
+	for {
+		err := d.client.XGroupCreateMkStream(ctx, d.stream, webhookConsumerGroup, "0").Err()
+		if err == nil || strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		d.logger.ErrorContext(ctx, "Failed to create consumer group", "error", err)
+		sleepContext(ctx, webhookReadBlock)
+	}
+
+	// Start with this consumer's own unacknowledged entries, left over from a
+	// previous run or from a failed dispatch.
+	readPending := true
+	lastClaim := time.Time{}
+
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= time.Minute {
+			d.claimAbandoned(ctx)
+			lastClaim = time.Now()
+		}
+
+		id := ">"
+		if readPending {
+			id = "0"
+		}
+
+		streams, err := d.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    webhookConsumerGroup,
+			Consumer: d.consumer,
+			Streams:  []string{d.stream, id},
+			Count:    webhookReadCount,
+			Block:    webhookReadBlock,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			if ctx.Err() == nil {
+				d.logger.ErrorContext(ctx, "Failed to read events", "error", err)
+				sleepContext(ctx, webhookReadBlock)
+			}
+			continue
+		}
+
+		var messages []redis.XMessage
+		if len(streams) > 0 {
+			messages = streams[0].Messages
+		}
+
+		if readPending && len(messages) == 0 {
+			readPending = false
+			continue
+		}
+
+		if !d.handle(ctx, messages) {
+			readPending = true
+			sleepContext(ctx, webhookReadBlock)
+		}
+	}
+}
+
+// handle dispatches messages in order and reports whether all of them were
+// acknowledged.
+func (d *webhookDispatcherImpl) handle(ctx context.Context, messages []redis.XMessage) bool {
+	for _, message := range messages {
+		event := eventFromMessage(message)
+		if _, err := d.service.Dispatch(ctx, event); err != nil {
+			d.logger.ErrorContext(ctx, "Failed to dispatch event", "event_id", event.ID, "error", err)
+			return false
+		}
+
+		if err := d.client.XAck(ctx, d.stream, webhookConsumerGroup, message.ID).Err(); err != nil {
+			d.logger.ErrorContext(ctx, "Failed to acknowledge event", "event_id", event.ID, "error", err)
+			return false
+		}
+	}
+	return true
+}
+
+func (d *webhookDispatcherImpl) claimAbandoned(ctx context.Context) {
+	messages, _, err := d.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   d.stream,
+		Group:    webhookConsumerGroup,
+		Consumer: d.consumer,
+		MinIdle:  webhookClaimIdle,
+		Start:    "0",
+		Count:    webhookReadCount,
+	}).Result()
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Failed to claim abandoned events", "error", err)
+		return
+	}
+
+	d.handle(ctx, messages)
+}
+
+func eventFromMessage(message redis.XMessage) *models.OutboxEvent {
+	field := func(name string) string {
+		value, _ := message.Values[name].(string)
+		return value
+	}
+
+	id, _ := strconv.ParseUint(field("event_id"), 10, 64)
+	aggregateID, _ := strconv.ParseUint(field("aggregate_id"), 10, 32)
+	occurredAt, _ := time.Parse(time.RFC3339Nano, field("occurred_at"))
+
+	return &models.OutboxEvent{
+		ID:            id,
+		AggregateType: field("aggregate_type"),
+		AggregateID:   uint(aggregateID),
+		EventType:     field("event_type"),
+		Payload:       field("payload"),
+		CreatedAt:     occurredAt,
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
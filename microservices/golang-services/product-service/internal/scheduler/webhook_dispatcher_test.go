@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"product-service/internal/logging"
+	"product-service/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// dispatchingWebhookService records the events it dispatches and fails on
+// failID.
+type dispatchingWebhookService struct {
+	fakeWebhookService
+
+	dispatched []uint64
+	failID     uint64
+}
+
+func (s *dispatchingWebhookService) Dispatch(ctx context.Context, event *models.OutboxEvent) (int, error) {
+	if event.ID == s.failID {
+		return 0, errors.New("database is down")
+	}
+	s.dispatched = append(s.dispatched, event.ID)
+	return 1, nil
+}
+
+func TestEventFromMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   models.OutboxEvent
+	}{
+		{
+			name: "complete",
+			values: map[string]interface{}{
+				"event_id":       "42",
+				"event_type":     models.EventProductStatusChanged,
+				"aggregate_type": models.AggregateProduct,
+				"aggregate_id":   "7",
+				"payload":        `{"product":{"id":7}}`,
+				"occurred_at":    "2025-06-01T12:00:00.123456789Z",
+			},
+			want: models.OutboxEvent{
+				ID:            42,
+				EventType:     models.EventProductStatusChanged,
+				AggregateType: models.AggregateProduct,
+				AggregateID:   7,
+				Payload:       `{"product":{"id":7}}`,
+				CreatedAt:     time.Date(2025, 6, 1, 12, 0, 0, 123456789, time.UTC),
+			},
+		},
+		{
+			name:   "missing fields",
+			values: map[string]interface{}{"event_type": models.EventProductDeleted},
+			want:   models.OutboxEvent{EventType: models.EventProductDeleted},
+		},
+		{
+			name: "malformed numbers and time",
+			values: map[string]interface{}{
+				"event_id":     "forty-two",
+				"aggregate_id": "-1",
+				"occurred_at":  "yesterday",
+			},
+			want: models.OutboxEvent{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventFromMessage(redis.XMessage{ID: "1-0", Values: tt.values})
+			if got.ID != tt.want.ID || got.EventType != tt.want.EventType || got.AggregateType != tt.want.AggregateType ||
+				got.AggregateID != tt.want.AggregateID || got.Payload != tt.want.Payload || !got.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Errorf("eventFromMessage = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// readTestMessages adds events with the given ids to the stream and reads
+// them through the consumer group, leaving them pending.
+func readTestMessages(t *testing.T, client *redis.Client, consumer string, ids ...string) []redis.XMessage {
+	t.Helper()
+	ctx := context.Background()
+	if err := client.XGroupCreateMkStream(ctx, testOutboxStream, webhookConsumerGroup, "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if err := client.XAdd(ctx, &redis.XAddArgs{Stream: testOutboxStream, Values: map[string]interface{}{"event_id": id}}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    webhookConsumerGroup,
+		Consumer: consumer,
+		Streams:  []string{testOutboxStream, ">"},
+	}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return streams[0].Messages
+}
+
+func pendingCount(t *testing.T, client *redis.Client) int64 {
+	t.Helper()
+	pending, err := client.XPending(context.Background(), testOutboxStream, webhookConsumerGroup).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pending.Count
+}
+
+func newTestWebhookDispatcher(t *testing.T, svc *dispatchingWebhookService) (*webhookDispatcherImpl, *redis.Client) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewWebhookDispatcher(svc, client, testOutboxStream, logging.Discard()).(*webhookDispatcherImpl), client
+}
+
+func TestWebhookDispatcherHandleAcknowledges(t *testing.T) {
+	svc := &dispatchingWebhookService{}
+	dispatcher, client := newTestWebhookDispatcher(t, svc)
+	messages := readTestMessages(t, client, dispatcher.consumer, "1", "2", "3")
+
+	if !dispatcher.handle(context.Background(), messages) {
+		t.Fatal("handle reported a failure")
+	}
+
+	if len(svc.dispatched) != 3 || svc.dispatched[0] != 1 || svc.dispatched[2] != 3 {
+		t.Errorf("dispatched %v, want [1 2 3]", svc.dispatched)
+	}
+	if got := pendingCount(t, client); got != 0 {
+		t.Errorf("%d entries left pending, want all acknowledged", got)
+	}
+}
+
+func TestWebhookDispatcherHandleStopsAtFailure(t *testing.T) {
+	svc := &dispatchingWebhookService{failID: 2}
+	dispatcher, client := newTestWebhookDispatcher(t, svc)
+	messages := readTestMessages(t, client, dispatcher.consumer, "1", "2", "3")
+
+	if dispatcher.handle(context.Background(), messages) {
+		t.Fatal("handle reported success despite a failed dispatch")
+	}
+
+	// Event 3 waits for event 2, which stays pending to be read again.
+	if len(svc.dispatched) != 1 || svc.dispatched[0] != 1 {
+		t.Errorf("dispatched %v, want [1]", svc.dispatched)
+	}
+	if got := pendingCount(t, client); got != 2 {
+		t.Errorf("%d entries left pending, want 2", got)
+	}
+}
+
+func TestRedisLeaderLock(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	first := NewRedisLeaderLock(client, "product-service:test-leader", time.Minute)
+	second := NewRedisLeaderLock(client, "product-service:test-leader", time.Minute)
+
+	acquire := func(lock LeaderLock) bool {
+		t.Helper()
+		leader, err := lock.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leader
+	}
+
+	if !acquire(first) {
+		t.Fatal("first instance did not take the free lock")
+	}
+	if acquire(second) {
+		t.Fatal("second instance took a held lock")
+	}
+
+	// Renewing resets the lease, so the holder keeps it past the first TTL.
+	mr.FastForward(40 * time.Second)
+	if !acquire(first) {
+		t.Fatal("holder could not renew its lease")
+	}
+	mr.FastForward(40 * time.Second)
+	if acquire(second) {
+		t.Fatal("renewed lease expired early")
+	}
+
+	if err := second.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if acquire(second) {
+		t.Fatal("a non-holder released the lock")
+	}
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !acquire(second) {
+		t.Error("lock not free after the holder released it")
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"product-service/internal/audit"
+	"product-service/internal/models"
+)
+
+// auditProductChanges reports content changes as product.updated and a status
+// change as product.status_changed, matching the domain events.
+func auditProductChanges(ctx context.Context, auditLog audit.Logger, productID uint, changes []models.FieldChange) {
+	fieldChanges, statusChanges := models.SplitStatusChange(changes)
+
+	if len(fieldChanges) > 0 {
+		auditLog.Log(ctx, audit.Event{
+			Event: audit.EventProductUpdated,
+			Details: map[string]interface{}{
+				"product_id": productID,
+				"changes":    fieldChanges,
+			},
+		})
+	}
+
+	for _, change := range statusChanges {
+		auditLog.Log(ctx, audit.Event{
+			Event: audit.EventProductStatusChanged,
+			Details: map[string]interface{}{
+				"product_id": productID,
+				"from":       change.From,
+				"to":         change.To,
+			},
+		})
+	}
+}
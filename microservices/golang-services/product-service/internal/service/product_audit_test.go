@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"product-service/internal/audit"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// recordedEvent is an audit event with the actor of the context it was
+// logged in.
+type recordedEvent struct {
+	audit.Event
+	Actor audit.Actor
+}
+
+type recordingAuditLog struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+func (l *recordingAuditLog) Log(ctx context.Context, event audit.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, recordedEvent{event, audit.ActorFromContext(ctx)})
+}
+
+func (l *recordingAuditLog) Start(ctx context.Context) {}
+
+func (l *recordingAuditLog) names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := []string{}
+	for _, e := range l.events {
+		names = append(names, e.Event.Event)
+	}
+	return names
+}
+
+// stubProductRepository answers the calls the audited service methods make
+// with canned results.
+type stubProductRepository struct {
+	repository.ProductRepository
+
+	product *models.Product
+	changes []models.FieldChange
+	err     error
+
+	// replicaReads counts GetByID calls, which may be served by a replica.
+	replicaReads int
+}
+
+func (r *stubProductRepository) GetByID(ctx context.Context, id uint) (*models.Product, error) {
+	r.replicaReads++
+	return r.GetByIDForUpdate(ctx, id)
+}
+
+func (r *stubProductRepository) GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	if r.product == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.product
+	return &copied, nil
+}
+
+func (r *stubProductRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *stubProductRepository) Create(ctx context.Context, product *models.Product) error {
+	product.ID = 7
+	return r.err
+}
+
+func (r *stubProductRepository) UpdateWithRevision(ctx context.Context, product *models.Product, actor string) ([]models.FieldChange, error) {
+	return r.changes, r.err
+}
+
+func (r *stubProductRepository) ReserveStock(ctx context.Context, items []models.StockReservation, actor string) ([]models.Product, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return []models.Product{{ID: 7, Quantity: 3}}, nil
+}
+
+func (r *stubProductRepository) ReplaceImageURL(ctx context.Context, id uint, from, to, actor string) error {
+	return r.err
+}
+
+func (r *stubProductRepository) Delete(ctx context.Context, id uint) error {
+	return r.err
+}
+
+func (r *stubProductRepository) Restore(ctx context.Context, id uint) (*models.Product, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &models.Product{ID: id}, nil
+}
+
+func (r *stubProductRepository) Purge(ctx context.Context, id uint) (*models.Product, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &models.Product{ID: id, SKU: "SKU-7", Name: "Mug"}, nil
+}
+
+var testActor = audit.Actor{ID: "42", Email: "editor@example.com", IPAddress: "10.0.0.1", UserAgent: "test"}
+
+func TestProductServiceAuditsMutations(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, svc ProductService) error
+		want []string
+	}{
+		{
+			name: "create",
+			run: func(ctx context.Context, svc ProductService) error {
+				return svc.Create(ctx, &models.Product{SKU: "SKU-7", Name: "Mug"})
+			},
+			want: []string{audit.EventProductCreated},
+		},
+		{
+			name: "update",
+			run: func(ctx context.Context, svc ProductService) error {
+				return svc.Update(ctx, 7, &models.Product{}, "editor@example.com")
+			},
+			want: []string{audit.EventProductUpdated, audit.EventProductStatusChanged},
+		},
+		{
+			name: "reserve stock",
+			run: func(ctx context.Context, svc ProductService) error {
+				_, err := svc.ReserveStock(ctx, []models.StockReservation{{ProductID: 7, Quantity: 2}}, "editor@example.com")
+				return err
+			},
+			want: []string{audit.EventProductUpdated},
+		},
+		{
+			name: "replace image",
+			run: func(ctx context.Context, svc ProductService) error {
+				return svc.ReplaceImageURL(ctx, 7, "/uploads/a.png", "https://s3/a.png", "editor@example.com")
+			},
+			want: []string{audit.EventProductUpdated},
+		},
+		{
+			name: "delete",
+			run:  func(ctx context.Context, svc ProductService) error { return svc.Delete(ctx, 7) },
+			want: []string{audit.EventProductDeleted},
+		},
+		{
+			name: "restore",
+			run: func(ctx context.Context, svc ProductService) error {
+				_, err := svc.Restore(ctx, 7)
+				return err
+			},
+			want: []string{audit.EventProductRestored},
+		},
+		{
+			name: "purge",
+			run: func(ctx context.Context, svc ProductService) error {
+				_, err := svc.Purge(ctx, 7)
+				return err
+			},
+			want: []string{audit.EventProductPurged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubProductRepository{changes: []models.FieldChange{
+				{Field: "price", From: 10.0, To: 12.0},
+				{Field: "status", From: models.StatusDraft, To: models.StatusActive},
+			}}
+			auditLog := &recordingAuditLog{}
+			svc := NewProductService(repo, auditLog)
+
+			if err := tt.run(audit.WithActor(context.Background(), testActor), svc); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := auditLog.names(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("audited %v, want %v", got, tt.want)
+			}
+			for _, e := range auditLog.events {
+				if e.Actor != testActor {
+					t.Errorf("%s attributed to %+v, want %+v", e.Event.Event, e.Actor, testActor)
+				}
+				if e.Details["product_id"] != uint(7) {
+					t.Errorf("%s product_id = %v, want 7", e.Event.Event, e.Details["product_id"])
+				}
+			}
+		})
+	}
+}
+
+func TestProductServiceDoesNotAuditFailures(t *testing.T) {
+	repo := &stubProductRepository{err: gorm.ErrRecordNotFound}
+	auditLog := &recordingAuditLog{}
+	svc := NewProductService(repo, auditLog)
+	ctx := context.Background()
+
+	_ = svc.Update(ctx, 7, &models.Product{}, "editor@example.com")
+	_ = svc.ReplaceImageURL(ctx, 7, "/uploads/a.png", "https://s3/a.png", "editor@example.com")
+	_ = svc.Delete(ctx, 7)
+	_, _ = svc.Restore(ctx, 7)
+	_, _ = svc.Purge(ctx, 7)
+	_, _ = svc.ReserveStock(ctx, []models.StockReservation{{ProductID: 7, Quantity: 1}}, "editor@example.com")
+
+	if got := auditLog.names(); len(got) != 0 {
+		t.Fatalf("audited %v after failures, want nothing", got)
+	}
+}
+
+func TestReserveStockAuditsQuantityChange(t *testing.T) {
+	auditLog := &recordingAuditLog{}
+	svc := NewProductService(&stubProductRepository{}, auditLog)
+
+	_, err := svc.ReserveStock(context.Background(), []models.StockReservation{{ProductID: 7, Quantity: 2}, {ProductID: 7, Quantity: 1}}, "checkout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []models.FieldChange{{Field: "quantity", From: 6, To: 3}}
+	if got := auditLog.events[0].Details["changes"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
+// stubRevisionRepository returns a single revision to roll back to.
+type stubRevisionRepository struct {
+	repository.ProductRevisionRepository
+}
+
+func (stubRevisionRepository) GetByID(ctx context.Context, productID, revisionID uint) (*models.ProductRevision, error) {
+	return &models.ProductRevision{ID: revisionID, ProductID: productID, Name: "Old name", Price: 10}, nil
+}
+
+func TestRollbackIsAuditedAsUpdate(t *testing.T) {
+	repo := &stubProductRepository{
+		product: &models.Product{ID: 7, Name: "New name", Price: 10},
+		changes: []models.FieldChange{{Field: "name", From: "New name", To: "Old name"}},
+	}
+	auditLog := &recordingAuditLog{}
+	products := NewProductService(repo, auditLog)
+	revisions := NewProductRevisionService(stubRevisionRepository{}, products, nil, false)
+
+	if _, _, err := revisions.Rollback(audit.WithActor(context.Background(), testActor), 7, 3, testActor.Email); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := auditLog.names(), []string{audit.EventProductUpdated}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("audited %v, want %v", got, want)
+	}
+}
+
+// stubChangeRequestRepository approves and rejects a single request.
+type stubChangeRequestRepository struct {
+	repository.ProductChangeRequestRepository
+
+	changes []models.FieldChange
+}
+
+func (r *stubChangeRequestRepository) Create(ctx context.Context, request *models.ProductChangeRequest) error {
+	request.ID = 5
+	return nil
+}
+
+func (r *stubChangeRequestRepository) Approve(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, *models.Product, []models.FieldChange, error) {
+	request := &models.ProductChangeRequest{ID: id, ProductID: 7, Status: models.ChangeRequestApproved, ReviewComment: comment}
+	return request, &models.Product{ID: 7, ImageURL: "/uploads/new.png"}, r.changes, nil
+}
+
+func (r *stubChangeRequestRepository) Reject(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, error) {
+	return &models.ProductChangeRequest{ID: id, ProductID: 7, Status: models.ChangeRequestRejected, ReviewComment: comment}, nil
+}
+
+func TestChangeRequestServiceAuditsReviews(t *testing.T) {
+	repo := &stubChangeRequestRepository{changes: []models.FieldChange{
+		{Field: "image_url", From: "/uploads/old.png", To: "/uploads/new.png"},
+	}}
+	auditLog := &recordingAuditLog{}
+	products := NewProductService(&stubProductRepository{product: &models.Product{ID: 7}}, auditLog)
+	svc := NewProductChangeRequestService(repo, products, auditLog)
+	ctx := audit.WithActor(context.Background(), testActor)
+
+	if err := svc.Submit(ctx, &models.ProductChangeRequest{ProductID: 7}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	_, _, replaced, err := svc.Approve(ctx, 5, testActor.Email, "looks good")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if replaced != "/uploads/old.png" {
+		t.Errorf("replaced image = %q, want /uploads/old.png", replaced)
+	}
+	if _, _, err := svc.Reject(ctx, 6, testActor.Email, "no"); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+
+	want := []string{
+		audit.EventChangeRequestSubmitted,
+		audit.EventChangeRequestApproved,
+		audit.EventProductUpdated,
+		audit.EventChangeRequestRejected,
+	}
+	if got := auditLog.names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("audited %v, want %v", got, want)
+	}
+	if got := auditLog.events[1].Details["comment"]; got != "looks good" {
+		t.Errorf("approval comment = %v, want looks good", got)
+	}
+	for _, e := range auditLog.events {
+		if e.Actor != testActor {
+			t.Errorf("%s attributed to %+v, want %+v", e.Event.Event, e.Actor, testActor)
+		}
+	}
+}
+
+// TestChangesAreBasedOnThePrimary checks that the read-modify-write paths
+// load the product from the primary, so a lagging replica cannot make them
+// overwrite newer changes or record a stale base version.
+func TestChangesAreBasedOnThePrimary(t *testing.T) {
+	repo := &stubProductRepository{product: &models.Product{ID: 7, Name: "New name", Status: models.StatusInactive, Price: 10}}
+	products := NewProductService(repo, &recordingAuditLog{})
+	revisions := NewProductRevisionService(stubRevisionRepository{}, products, nil, false)
+	changeRequests := NewProductChangeRequestService(&stubChangeRequestRepository{}, products, &recordingAuditLog{})
+	ctx := context.Background()
+
+	if _, _, err := products.TransitionStatus(ctx, 7, models.StatusActive, "admin"); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+	if _, _, err := revisions.Rollback(ctx, 7, 3, "admin"); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if err := changeRequests.Submit(ctx, &models.ProductChangeRequest{ProductID: 7}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	if repo.replicaReads != 0 {
+		t.Errorf("%d reads went through GetByID, want all from the primary", repo.replicaReads)
+	}
+}
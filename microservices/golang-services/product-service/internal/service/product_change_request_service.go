@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"product-service/internal/audit"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+type ProductChangeRequestService interface {
+	GetAll(ctx context.Context, limit, offset int, status *models.ChangeRequestStatus, productID uint) ([]models.ProductChangeRequest, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.ProductChangeRequest, error)
+	Submit(ctx context.Context, request *models.ProductChangeRequest) error
+	Approve(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, *models.Product, string, error)
+	Reject(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, string, error)
+}
+
+type productChangeRequestService struct {
+	repo     repository.ProductChangeRequestRepository
+	product  ProductService
+	auditLog audit.Logger
+}
+
+// NewProductChangeRequestService builds the change request service. Submitted,
+// approved and rejected requests, and the product changes approval applies,
+// are reported to auditLog.
+func NewProductChangeRequestService(repo repository.ProductChangeRequestRepository, product ProductService, auditLog audit.Logger) ProductChangeRequestService {
+	return &productChangeRequestService{repo, product, auditLog}
+}
+
+func (s *productChangeRequestService) GetAll(ctx context.Context, limit, offset int, status *models.ChangeRequestStatus, productID uint) ([]models.ProductChangeRequest, int64, error) {
+	return s.repo.GetAll(ctx, limit, offset, status, productID)
+}
+
+func (s *productChangeRequestService) GetByID(ctx context.Context, id uint) (*models.ProductChangeRequest, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Submit files request as pending against the product's current version.
+func (s *productChangeRequestService) Submit(ctx context.Context, request *models.ProductChangeRequest) error {
+	// The base version must be the latest one, or approving would overwrite
+	// whatever a lagging replica had not caught up with.
+	product, err := s.product.GetByIDForUpdate(ctx, request.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := request.SetBase(product); err != nil {
+		return err
+	}
+	request.Status = models.ChangeRequestPending
+	if err := s.repo.Create(ctx, request); err != nil {
+		return err
+	}
+
+	s.auditRequest(ctx, audit.EventChangeRequestSubmitted, request)
+	return nil
+}
+
+// Approve applies the change and returns the approved request, the updated
+// product and, when the change replaced the image, the old image URL.
+func (s *productChangeRequestService) Approve(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, *models.Product, string, error) {
+	request, product, changes, err := s.repo.Approve(ctx, id, reviewer, comment)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	s.auditRequest(ctx, audit.EventChangeRequestApproved, request)
+	auditProductChanges(ctx, s.auditLog, product.ID, changes)
+
+	var replacedImageURL string
+	for _, change := range changes {
+		if change.Field == "image_url" {
+			replacedImageURL, _ = change.From.(string)
+		}
+	}
+
+	return request, product, replacedImageURL, nil
+}
+
+// Reject closes the request without applying it and returns the image URL
+// it proposed when that image is not in use by the product.
+func (s *productChangeRequestService) Reject(ctx context.Context, id uint, reviewer, comment string) (*models.ProductChangeRequest, string, error) {
+	request, err := s.repo.Reject(ctx, id, reviewer, comment)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.auditRequest(ctx, audit.EventChangeRequestRejected, request)
+
+	product, err := s.product.GetByID(ctx, request.ProductID)
+	if err != nil || request.ImageURL == product.ImageURL {
+		return request, "", nil
+	}
+
+	return request, request.ImageURL, nil
+}
+
+func (s *productChangeRequestService) auditRequest(ctx context.Context, event string, request *models.ProductChangeRequest) {
+	details := map[string]interface{}{
+		"change_request_id": request.ID,
+		"product_id":        request.ProductID,
+	}
+	if request.ReviewComment != "" {
+		details["comment"] = request.ReviewComment
+	}
+
+	s.auditLog.Log(ctx, audit.Event{Event: event, Details: details})
+}
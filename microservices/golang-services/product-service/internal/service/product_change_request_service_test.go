@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"product-service/internal/audit"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/testdb"
+)
+
+type changeRequestFixture struct {
+	products       ProductService
+	productRepo    repository.ProductRepository
+	revisions      repository.ProductRevisionRepository
+	changeRequests ProductChangeRequestService
+	auditLog       *recordingAuditLog
+}
+
+func newChangeRequestFixture(t *testing.T) *changeRequestFixture {
+	db := testdb.Open(t)
+	productRepo := repository.NewProductRepository(db)
+	products := NewProductService(productRepo, &recordingAuditLog{})
+	auditLog := &recordingAuditLog{}
+
+	return &changeRequestFixture{
+		products:       products,
+		productRepo:    productRepo,
+		revisions:      repository.NewProductRevisionRepository(db),
+		changeRequests: NewProductChangeRequestService(repository.NewProductChangeRequestRepository(db), products, auditLog),
+		auditLog:       auditLog,
+	}
+}
+
+// submit proposes change to the stored product the way the update handler
+// does in review mode.
+func (f *changeRequestFixture) submit(t *testing.T, id uint, change func(*models.Product)) *models.ProductChangeRequest {
+	t.Helper()
+	product, err := f.productRepo.GetByIDForUpdate(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change(product)
+
+	request := models.NewProductChangeRequest(product, "author@example.com")
+	if err := f.changeRequests.Submit(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func (f *changeRequestFixture) product(t *testing.T, id uint) *models.Product {
+	t.Helper()
+	product, err := f.productRepo.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func TestSubmitChangeRequest(t *testing.T) {
+	f := newChangeRequestFixture(t)
+	product := &models.Product{Name: "Lamp"}
+	createProduct(t, f.products, product)
+
+	request := f.submit(t, product.ID, func(p *models.Product) { p.Name = "Desk lamp" })
+
+	if request.ID == 0 || request.Status != models.ChangeRequestPending {
+		t.Errorf("request = %+v, want a stored pending request", request)
+	}
+	if request.Base == "" || request.BaseUpdatedAt == nil || !request.BaseUpdatedAt.Equal(product.UpdatedAt) {
+		t.Errorf("request base = %q at %v, want the product as submitted", request.Base, request.BaseUpdatedAt)
+	}
+	if got := f.product(t, product.ID); got.Name != "Lamp" {
+		t.Errorf("product renamed to %q before approval", got.Name)
+	}
+	if len(f.auditLog.events) != 1 || f.auditLog.events[0].Event.Event != audit.EventChangeRequestSubmitted {
+		t.Errorf("audit events = %+v, want one submission", f.auditLog.events)
+	}
+}
+
+func TestApproveChangeRequest(t *testing.T) {
+	ctx := context.Background()
+	f := newChangeRequestFixture(t)
+	product := &models.Product{Name: "Lamp", Description: "Brass"}
+	createProduct(t, f.products, product)
+
+	request := f.submit(t, product.ID, func(p *models.Product) { p.Name = "Desk lamp" })
+
+	approved, updated, replacedImageURL, err := f.changeRequests.Approve(ctx, request.ID, "reviewer@example.com", "Looks good")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if approved.Status != models.ChangeRequestApproved || approved.ReviewedBy != "reviewer@example.com" || approved.RequestedBy != "author@example.com" {
+		t.Errorf("approved request = %+v", approved)
+	}
+	if updated.Name != "Desk lamp" || f.product(t, product.ID).Name != "Desk lamp" {
+		t.Errorf("product name = %q, want the requested one", updated.Name)
+	}
+	if replacedImageURL != "" {
+		t.Errorf("replaced image = %q, want none", replacedImageURL)
+	}
+
+	history, _, err := f.revisions.GetByProductID(ctx, product.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Name != "Lamp" || history[0].Actor != "reviewer@example.com" {
+		t.Errorf("revisions = %+v, want the replaced state attributed to the reviewer", history)
+	}
+
+	if _, _, _, err := f.changeRequests.Approve(ctx, request.ID, "reviewer@example.com", ""); err == nil || err.Error() != "change request is not pending" {
+		t.Errorf("approving twice returned %v, want change request is not pending", err)
+	}
+}
+
+func TestApproveChangeRequestAfterUnrelatedWrite(t *testing.T) {
+	ctx := context.Background()
+	f := newChangeRequestFixture(t)
+	product := &models.Product{Name: "Lamp", Status: models.StatusActive}
+	createProduct(t, f.products, product)
+
+	request := f.submit(t, product.ID, func(p *models.Product) { p.Price = 12 })
+
+	// A checkout reserves stock while the request waits for review.
+	if _, err := f.productRepo.ReserveStock(ctx, []models.StockReservation{{ProductID: product.ID, Quantity: 1}}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := f.changeRequests.Approve(ctx, request.ID, "reviewer@example.com", ""); err != nil {
+		t.Fatalf("approving after an unrelated write: %v", err)
+	}
+
+	got := f.product(t, product.ID)
+	if got.Price != 12 || got.Quantity != 0 {
+		t.Errorf("product price %v with quantity %d, want the new price and the reservation kept", got.Price, got.Quantity)
+	}
+}
+
+func TestApproveStaleChangeRequest(t *testing.T) {
+	ctx := context.Background()
+	f := newChangeRequestFixture(t)
+	product := &models.Product{Name: "Lamp"}
+	createProduct(t, f.products, product)
+
+	request := f.submit(t, product.ID, func(p *models.Product) { p.Name = "Desk lamp" })
+	other := f.submit(t, product.ID, func(p *models.Product) { p.Name = "Floor lamp" })
+
+	if _, _, _, err := f.changeRequests.Approve(ctx, other.ID, "reviewer@example.com", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err := f.changeRequests.Approve(ctx, request.ID, "reviewer@example.com", "")
+	if err == nil || err.Error() != "product changed since the request was submitted" {
+		t.Fatalf("approving a stale request returned %v, want product changed since the request was submitted", err)
+	}
+
+	if got := f.product(t, product.ID); got.Name != "Floor lamp" {
+		t.Errorf("product name = %q, want the approved change kept", got.Name)
+	}
+	stale, err := f.changeRequests.GetByID(ctx, request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale.Status != models.ChangeRequestPending {
+		t.Errorf("stale request is %s, want it left pending", stale.Status)
+	}
+}
+
+func TestRejectChangeRequest(t *testing.T) {
+	ctx := context.Background()
+	f := newChangeRequestFixture(t)
+	product := &models.Product{Name: "Lamp", ImageURL: "https://cdn.example.com/lamp.png"}
+	createProduct(t, f.products, product)
+
+	request := f.submit(t, product.ID, func(p *models.Product) { p.ImageURL = "https://cdn.example.com/desk-lamp.png" })
+
+	rejected, proposedImageURL, err := f.changeRequests.Reject(ctx, request.ID, "reviewer@example.com", "Wrong photo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rejected.Status != models.ChangeRequestRejected || rejected.ReviewComment != "Wrong photo" {
+		t.Errorf("rejected request = %+v", rejected)
+	}
+	if proposedImageURL != "https://cdn.example.com/desk-lamp.png" {
+		t.Errorf("unused image = %q, want the proposed one", proposedImageURL)
+	}
+	if got := f.product(t, product.ID); got.ImageURL != "https://cdn.example.com/lamp.png" {
+		t.Errorf("product image = %q, want it unchanged", got.ImageURL)
+	}
+	if _, _, _, err := f.changeRequests.Approve(ctx, request.ID, "reviewer@example.com", ""); err == nil || err.Error() != "change request is not pending" {
+		t.Errorf("approving a rejected request returned %v, want change request is not pending", err)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/pkg/helpers"
+	"strconv"
+	"time"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatXLSX   = "xlsx"
+)
+
+// ExportContentTypes maps each export format to its MIME type.
+var ExportContentTypes = map[string]string{
+	ExportFormatCSV:    "text/csv; charset=utf-8",
+	ExportFormatNDJSON: "application/x-ndjson",
+	ExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+var exportColumns = []string{
+	"id", "sku", "name", "description", "price", "quantity", "status",
+	"image_url", "publish_at", "unpublish_at", "created_at", "updated_at",
+}
+
+type ProductExportService interface {
+	Export(ctx context.Context, w io.Writer, format, search string, status *models.ProductStatus) error
+}
+
+type productExportService struct {
+	repo repository.ProductRepository
+}
+
+func NewProductExportService(repo repository.ProductRepository) ProductExportService {
+	return &productExportService{repo}
+}
+
+type productExportWriter interface {
+	Write(product *models.Product) error
+	Close() error
+}
+
+// Export streams every product matching the GetAll filters to w in the given
+// format, reading them through a database cursor.
+func (s *productExportService) Export(ctx context.Context, w io.Writer, format, search string, status *models.ProductStatus) error {
+	var writer productExportWriter
+	var err error
+
+	switch format {
+	case ExportFormatCSV:
+		writer, err = newCSVExportWriter(w)
+	case ExportFormatNDJSON:
+		writer = &ndjsonExportWriter{encoder: json.NewEncoder(w)}
+	case ExportFormatXLSX:
+		writer, err = newXLSXExportWriter(w)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.StreamAll(ctx, search, status, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func exportRow(product *models.Product) []interface{} {
+	return []interface{}{
+		product.ID,
+		product.SKU,
+		product.Name,
+		product.Description,
+		product.Price,
+		product.Quantity,
+		string(product.Status),
+		product.ImageURL,
+		formatExportTime(product.PublishAt),
+		formatExportTime(product.UnpublishAt),
+		formatExportTime(&product.CreatedAt),
+		formatExportTime(&product.UpdatedAt),
+	}
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{writer}, nil
+}
+
+func (e *csvExportWriter) Write(product *models.Product) error {
+	row := exportRow(product)
+	record := make([]string, len(row))
+	for i, cell := range row {
+		switch v := cell.(type) {
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		case string:
+			record[i] = helpers.EscapeFormula(v)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExportWriter) Write(product *models.Product) error {
+	return e.encoder.Encode(product)
+}
+
+func (e *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+type xlsxExportWriter struct {
+	writer *helpers.XLSXStreamWriter
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	writer, err := helpers.NewXLSXStreamWriter(w, "Products")
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]interface{}, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return nil, err
+	}
+
+	return &xlsxExportWriter{writer}, nil
+}
+
+func (e *xlsxExportWriter) Write(product *models.Product) error {
+	return e.writer.WriteRow(exportRow(product))
+}
+
+func (e *xlsxExportWriter) Close() error {
+	return e.writer.Close()
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// streamingProductRepository streams a fixed list of products.
+type streamingProductRepository struct {
+	repository.ProductRepository
+
+	products []models.Product
+}
+
+func (r *streamingProductRepository) StreamAll(ctx context.Context, search string, status *models.ProductStatus, fn func(product *models.Product) error) error {
+	for i := range r.products {
+		if err := fn(&r.products[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportTestProducts() []models.Product {
+	created := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	publishAt := created.Add(24 * time.Hour)
+	return []models.Product{
+		{ID: 1, SKU: "LAMP-1", Name: "Lamp, \"brass\"", Description: "Two\nlines", Price: 12.5, Quantity: 3, Status: models.StatusActive, PublishAt: &publishAt, CreatedAt: created, UpdatedAt: created},
+		{ID: 2, SKU: "-1", Name: "=HYPERLINK(\"http://evil\")", Description: "@SUM(A1)", Price: 4, Status: models.StatusDraft, CreatedAt: created, UpdatedAt: created},
+	}
+}
+
+func exportProducts(t *testing.T, format string) []byte {
+	t.Helper()
+	svc := NewProductExportService(&streamingProductRepository{products: exportTestProducts()})
+
+	var buf bytes.Buffer
+	if err := svc.Export(context.Background(), &buf, format, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(exportProducts(t, ExportFormatCSV))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		exportColumns,
+		{"1", "LAMP-1", "Lamp, \"brass\"", "Two\nlines", "12.50", "3", "active", "", "2025-03-02T09:30:00Z", "", "2025-03-01T09:30:00Z", "2025-03-01T09:30:00Z"},
+		{"2", "'-1", "'=HYPERLINK(\"http://evil\")", "'@SUM(A1)", "4.00", "0", "draft", "", "", "", "2025-03-01T09:30:00Z", "2025-03-01T09:30:00Z"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	scanner := bufio.NewScanner(bytes.NewReader(exportProducts(t, ExportFormatNDJSON)))
+	var got []models.Product
+	for scanner.Scan() {
+		var product models.Product
+		if err := json.Unmarshal(scanner.Bytes(), &product); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, product)
+	}
+
+	want := exportTestProducts()
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(got), len(want))
+	}
+	for i := range want {
+		// NDJSON is read by programs, not spreadsheets, so nothing is escaped.
+		if got[i].ID != want[i].ID || got[i].Name != want[i].Name || got[i].Description != want[i].Description || got[i].Price != want[i].Price {
+			t.Errorf("line %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+	if got[0].PublishAt == nil || !got[0].PublishAt.Equal(*want[0].PublishAt) {
+		t.Errorf("publish_at = %v, want %v", got[0].PublishAt, want[0].PublishAt)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	content := exportProducts(t, ExportFormatXLSX)
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sheet, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`,
+		`<c r="E2"><v>12.5</v></c>`,
+		`<c r="C3" t="inlineStr"><is><t xml:space="preserve">&#39;=HYPERLINK(&#34;http://evil&#34;)</t></is></c>`,
+	} {
+		if !bytes.Contains(sheet, []byte(want)) {
+			t.Errorf("sheet is missing %s", want)
+		}
+	}
+}
+
+func TestExportUnsupportedFormat(t *testing.T) {
+	svc := NewProductExportService(&streamingProductRepository{})
+	err := svc.Export(context.Background(), io.Discard, "pdf", "", nil)
+	if err == nil || err.Error() != "unsupported export format: pdf" {
+		t.Errorf("Export returned %v, want unsupported export format", err)
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"product-service/internal/models"
+	"product-service/pkg/helpers"
+	"strconv"
+	"strings"
+)
+
+type ProductImportService interface {
+	Import(ctx context.Context, r io.Reader, opts models.ImportOptions, actor string) (*models.ImportReport, error)
+}
+
+type productImportService struct {
+	product        ProductService
+	changeRequests ProductChangeRequestService
+	reviewMode     bool
+}
+
+// NewProductImportService builds the import service. With reviewMode enabled,
+// upserted rows are submitted as change requests instead of applied.
+func NewProductImportService(product ProductService, changeRequests ProductChangeRequestService, reviewMode bool) ProductImportService {
+	return &productImportService{product, changeRequests, reviewMode}
+}
+
+var importColumnAliases = map[string]string{
+	"sku":         "sku",
+	"name":        "name",
+	"description": "description",
+	"price":       "price",
+	"quantity":    "quantity",
+	"status":      "status",
+	"image_url":   "image_url",
+	"image url":   "image_url",
+	"imageurl":    "image_url",
+}
+
+var requiredImportColumns = []string{"name", "description", "price", "quantity"}
+
+// Import reads products from a CSV file with a header row and creates them,
+// or updates products matched by SKU when opts.Upsert is set; in review mode
+// those updates are submitted as change requests. Each row is
+// validated with the CreateProductInput rules; invalid rows are reported and
+// skipped while valid rows are imported. With opts.DryRun nothing is written.
+// The returned error is only set when the file itself cannot be read.
+func (s *productImportService) Import(ctx context.Context, r io.Reader, opts models.ImportOptions, actor string) (*models.ImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("csv file is empty")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if column, ok := importColumnAliases[name]; ok {
+			columns[column] = i
+		}
+	}
+	for _, column := range requiredImportColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("csv file is missing the %s column", column)
+		}
+	}
+
+	report := &models.ImportReport{
+		DryRun: opts.DryRun,
+		Upsert: opts.Upsert,
+		Rows:   []models.ImportRowResult{},
+	}
+	seenSKUs := map[string]int{}
+
+	for rowNumber := 2; ; rowNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return helpers.UnescapeFormula(strings.TrimSpace(record[i]))
+		}
+
+		result := s.importRow(ctx, rowNumber, value, seenSKUs, opts, actor)
+
+		report.Total++
+		switch result.Action {
+		case models.ImportActionCreate:
+			report.Created++
+		case models.ImportActionUpdate:
+			report.Updated++
+		case models.ImportActionSubmit:
+			report.Submitted++
+		default:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	return report, nil
+}
+
+func (s *productImportService) importRow(ctx context.Context, rowNumber int, value func(string) string, seenSKUs map[string]int, opts models.ImportOptions, actor string) models.ImportRowResult {
+	result := models.ImportRowResult{Row: rowNumber, SKU: value("sku")}
+	fail := func(errs map[string][]string) models.ImportRowResult {
+		result.Action = models.ImportActionFailed
+		result.Errors = errs
+		return result
+	}
+
+	row, errs := parseImportRow(value)
+	if errs != nil {
+		return fail(errs)
+	}
+
+	if row.SKU != "" {
+		if firstRow, ok := seenSKUs[row.SKU]; ok {
+			return fail(map[string][]string{
+				"SKU": {fmt.Sprintf("The SKU is duplicated in row %d.", firstRow)},
+			})
+		}
+		seenSKUs[row.SKU] = rowNumber
+	}
+
+	var existing *models.Product
+	if row.SKU != "" {
+		product, err := s.product.GetBySKU(ctx, row.SKU)
+		if err == nil {
+			existing = product
+		} else if err.Error() != "record not found" {
+			return fail(map[string][]string{"error": {err.Error()}})
+		}
+	}
+
+	if existing != nil && existing.DeletedAt.Valid {
+		return fail(map[string][]string{
+			"SKU": {"The SKU belongs to a product in the trash; restore or purge it first."},
+		})
+	}
+
+	if existing == nil {
+		product := models.Product{
+			SKU:         row.SKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Quantity:    row.Quantity,
+			Status:      models.StatusActive,
+			ImageURL:    row.ImageURL,
+		}
+		if row.Status != "" {
+			product.Status = models.ProductStatus(row.Status)
+		}
+
+		if !opts.DryRun {
+			if err := s.product.Create(ctx, &product); err != nil {
+				if err.Error() == "product sku already exists" {
+					return fail(map[string][]string{"SKU": {"The SKU has already been taken."}})
+				}
+				return fail(map[string][]string{"error": {err.Error()}})
+			}
+			result.ProductID = product.ID
+		}
+
+		result.Action = models.ImportActionCreate
+		return result
+	}
+
+	if !opts.Upsert {
+		return fail(map[string][]string{"SKU": {"The SKU has already been taken."}})
+	}
+
+	if s.reviewMode && row.Status != "" && models.ProductStatus(row.Status) != existing.Status {
+		return fail(map[string][]string{
+			"Status": {"The Status cannot be changed by an import in review mode."},
+		})
+	}
+
+	if row.Status != "" && !existing.Status.CanTransitionTo(models.ProductStatus(row.Status)) {
+		return fail(map[string][]string{
+			"Status": {"The Status cannot change from " + string(existing.Status) + " to " + row.Status + "."},
+		})
+	}
+
+	existing.Name = row.Name
+	existing.Description = row.Description
+	existing.Price = row.Price
+	existing.Quantity = row.Quantity
+	if row.Status != "" {
+		existing.Status = models.ProductStatus(row.Status)
+	}
+	if row.ImageURL != "" {
+		existing.ImageURL = row.ImageURL
+	}
+
+	result.ProductID = existing.ID
+
+	if s.reviewMode {
+		if !opts.DryRun {
+			changeRequest := models.NewProductChangeRequest(existing, actor)
+			if err := s.changeRequests.Submit(ctx, changeRequest); err != nil {
+				return fail(map[string][]string{"error": {err.Error()}})
+			}
+			result.ChangeRequestID = changeRequest.ID
+		}
+
+		result.Action = models.ImportActionSubmit
+		return result
+	}
+
+	if !opts.DryRun {
+		if err := s.product.Update(ctx, existing.ID, existing, actor); err != nil {
+			return fail(map[string][]string{"error": {err.Error()}})
+		}
+	}
+
+	result.Action = models.ImportActionUpdate
+	return result
+}
+
+func parseImportRow(value func(string) string) (*models.ImportProductRow, map[string][]string) {
+	errs := map[string][]string{}
+	row := &models.ImportProductRow{
+		CreateProductInput: models.CreateProductInput{
+			SKU:         value("sku"),
+			Name:        value("name"),
+			Description: value("description"),
+			Status:      value("status"),
+		},
+		ImageURL: value("image_url"),
+	}
+
+	if raw := value("price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			errs["Price"] = []string{"The Price must be a number."}
+		}
+		row.Price = price
+	}
+
+	if raw := value("quantity"); raw != "" {
+		quantity, err := strconv.Atoi(raw)
+		if err != nil {
+			errs["Quantity"] = []string{"The Quantity must be an integer."}
+		}
+		row.Quantity = quantity
+	}
+
+	if err := helpers.ValidateStruct(row); err != nil {
+		for field, messages := range helpers.ParseValidationErrors(err) {
+			if _, parsed := errs[field]; !parsed {
+				errs[field] = messages
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return row, nil
+}
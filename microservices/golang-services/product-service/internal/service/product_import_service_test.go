@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"product-service/internal/models"
+	"product-service/pkg/helpers"
+)
+
+func importCSV(t *testing.T, svc ProductImportService, csv string, opts models.ImportOptions) *models.ImportReport {
+	t.Helper()
+	report, err := svc.Import(context.Background(), strings.NewReader(csv), opts, "importer@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func newImportFixture(t *testing.T, reviewMode bool) (*changeRequestFixture, ProductImportService) {
+	helpers.InitValidator()
+	f := newChangeRequestFixture(t)
+	return f, NewProductImportService(f.products, f.changeRequests, reviewMode)
+}
+
+func rowError(result models.ImportRowResult, field string) string {
+	if messages := result.Errors[field]; len(messages) > 0 {
+		return messages[0]
+	}
+	return ""
+}
+
+func TestImportHeaderAliases(t *testing.T) {
+	f, svc := newImportFixture(t, false)
+
+	csv := "\ufeffSKU, Name ,DESCRIPTION,Price,Quantity,Image URL,colour\n" +
+		"LAMP-1,Desk lamp,Brass,12.50,3,https://cdn.example.com/lamp.png,gold\n"
+	report := importCSV(t, svc, csv, models.ImportOptions{})
+
+	if report.Total != 1 || report.Created != 1 {
+		t.Fatalf("report = %+v, want one created row", report)
+	}
+
+	product := f.product(t, report.Rows[0].ProductID)
+	if product.SKU != "LAMP-1" || product.Name != "Desk lamp" || product.Price != 12.5 || product.Quantity != 3 || product.ImageURL != "https://cdn.example.com/lamp.png" {
+		t.Errorf("imported product = %+v", product)
+	}
+	if product.Status != models.StatusActive {
+		t.Errorf("imported status = %s, want active by default", product.Status)
+	}
+
+	_, err := svc.Import(context.Background(), strings.NewReader("name,description,quantity\n"), models.ImportOptions{}, "importer@example.com")
+	if err == nil || err.Error() != "csv file is missing the price column" {
+		t.Errorf("importing without a price column returned %v", err)
+	}
+}
+
+func TestImportRowValidation(t *testing.T) {
+	_, svc := newImportFixture(t, false)
+
+	csv := "sku,name,description,price,quantity\n" +
+		"LAMP-1,Desk lamp,Brass,cheap,3\n" +
+		"LAMP-2,ab,Brass,10,-1\n" +
+		"LAMP-3,Floor lamp,Steel,10,1\n"
+	report := importCSV(t, svc, csv, models.ImportOptions{})
+
+	if report.Total != 3 || report.Created != 1 || report.Failed != 2 {
+		t.Fatalf("report = %+v, want one created and two failed rows", report)
+	}
+	if msg := rowError(report.Rows[0], "Price"); msg != "The Price must be a number." {
+		t.Errorf("row 2 price error = %q", msg)
+	}
+	if rowError(report.Rows[1], "Name") == "" || rowError(report.Rows[1], "Quantity") == "" {
+		t.Errorf("row 3 errors = %v, want Name and Quantity", report.Rows[1].Errors)
+	}
+}
+
+func TestImportDuplicateSKUs(t *testing.T) {
+	_, svc := newImportFixture(t, false)
+
+	csv := "sku,name,description,price,quantity\n" +
+		"LAMP-1,Desk lamp,Brass,10,1\n" +
+		"LAMP-1,Desk lamp again,Brass,10,1\n"
+	report := importCSV(t, svc, csv, models.ImportOptions{Upsert: true})
+
+	if report.Created != 1 || report.Failed != 1 {
+		t.Fatalf("report = %+v, want the first row created and the second failed", report)
+	}
+	if msg := rowError(report.Rows[1], "SKU"); msg != "The SKU is duplicated in row 2." {
+		t.Errorf("duplicate error = %q", msg)
+	}
+}
+
+func TestImportRejectsTrashedSKU(t *testing.T) {
+	ctx := context.Background()
+	f, svc := newImportFixture(t, false)
+
+	trashed := &models.Product{SKU: "LAMP-1"}
+	createProduct(t, f.products, trashed)
+	if err := f.productRepo.Delete(ctx, trashed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, upsert := range []bool{false, true} {
+		report := importCSV(t, svc, "sku,name,description,price,quantity\nLAMP-1,Desk lamp,Brass,10,1\n", models.ImportOptions{Upsert: upsert})
+		if report.Failed != 1 {
+			t.Fatalf("upsert %v: report = %+v, want the row failed", upsert, report)
+		}
+		if msg := rowError(report.Rows[0], "SKU"); msg != "The SKU belongs to a product in the trash; restore or purge it first." {
+			t.Errorf("upsert %v: SKU error = %q", upsert, msg)
+		}
+	}
+}
+
+func TestImportUpsertStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		upsert bool
+		from   models.ProductStatus
+		status string
+		action string
+		want   models.ProductStatus
+		error  string
+	}{
+		{"without upsert", false, models.StatusActive, "", models.ImportActionFailed, models.StatusActive, "The SKU has already been taken."},
+		{"keeps status", true, models.StatusActive, "", models.ImportActionUpdate, models.StatusActive, ""},
+		{"same status", true, models.StatusActive, "active", models.ImportActionUpdate, models.StatusActive, ""},
+		{"allowed transition", true, models.StatusDraft, "active", models.ImportActionUpdate, models.StatusActive, ""},
+		{"disallowed transition", true, models.StatusActive, "draft", models.ImportActionFailed, models.StatusActive, "The Status cannot change from active to draft."},
+		{"status imports cannot set", true, models.StatusActive, "inactive", models.ImportActionFailed, models.StatusActive, "The Status must be one of: draft, pending_review, active."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, svc := newImportFixture(t, false)
+			existing := &models.Product{SKU: "LAMP-1", Status: tt.from}
+			createProduct(t, f.products, existing)
+
+			csv := "sku,name,description,price,quantity,status\nLAMP-1,Desk lamp,Brass,15,4," + tt.status + "\n"
+			report := importCSV(t, svc, csv, models.ImportOptions{Upsert: tt.upsert})
+
+			result := report.Rows[0]
+			if result.Action != tt.action {
+				t.Fatalf("action = %s, want %s: %v", result.Action, tt.action, result.Errors)
+			}
+			if tt.error != "" {
+				field := "SKU"
+				if tt.status != "" {
+					field = "Status"
+				}
+				if msg := rowError(result, field); msg != tt.error {
+					t.Errorf("%s error = %q, want %q", field, msg, tt.error)
+				}
+			}
+
+			got := f.product(t, existing.ID)
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+			if updated := got.Name == "Desk lamp" && got.Price == 15 && got.Quantity == 4; updated != (tt.action == models.ImportActionUpdate) {
+				t.Errorf("product = %+v after a %s", got, tt.action)
+			}
+		})
+	}
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	ctx := context.Background()
+	f, svc := newImportFixture(t, false)
+	existing := &models.Product{SKU: "LAMP-1", Status: models.StatusActive}
+	createProduct(t, f.products, existing)
+
+	csv := "sku,name,description,price,quantity\n" +
+		"LAMP-1,Desk lamp,Brass,15,4\n" +
+		"LAMP-2,Floor lamp,Steel,20,1\n"
+	report := importCSV(t, svc, csv, models.ImportOptions{DryRun: true, Upsert: true})
+
+	if !report.DryRun || report.Updated != 1 || report.Created != 1 {
+		t.Fatalf("report = %+v, want one update and one create", report)
+	}
+	if report.Rows[1].ProductID != 0 {
+		t.Errorf("dry run reported product %d for a new row", report.Rows[1].ProductID)
+	}
+
+	products, total, err := f.productRepo.GetAll(ctx, 10, 0, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || products[0].Name != "Lamp" {
+		t.Errorf("products after dry run = %+v, want only the untouched original", products)
+	}
+	if _, revisions, err := f.revisions.GetByProductID(ctx, existing.ID, 10, 0); err != nil || revisions != 0 {
+		t.Errorf("dry run recorded %d revisions (%v), want none", revisions, err)
+	}
+}
+
+func TestImportInReviewMode(t *testing.T) {
+	ctx := context.Background()
+	f, svc := newImportFixture(t, true)
+	existing := &models.Product{SKU: "LAMP-1", Status: models.StatusActive}
+	createProduct(t, f.products, existing)
+
+	csv := "sku,name,description,price,quantity,status\n" +
+		"LAMP-1,Desk lamp,Brass,15,4,\n" +
+		"LAMP-1,Desk lamp,Brass,15,4,draft\n" +
+		"LAMP-2,Floor lamp,Steel,20,1,\n"
+	report := importCSV(t, svc, csv, models.ImportOptions{Upsert: true})
+
+	if report.Submitted != 1 || report.Created != 1 || report.Failed != 1 {
+		t.Fatalf("report = %+v, want one submitted, one created and one failed row", report)
+	}
+
+	submitted := report.Rows[0]
+	if submitted.Action != models.ImportActionSubmit || submitted.ChangeRequestID == 0 {
+		t.Fatalf("row 2 = %+v, want a submitted change request", submitted)
+	}
+	request, err := f.changeRequests.GetByID(ctx, submitted.ChangeRequestID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.ProductID != existing.ID || request.Name != "Desk lamp" || request.RequestedBy != "importer@example.com" || request.Status != models.ChangeRequestPending {
+		t.Errorf("change request = %+v", request)
+	}
+	if got := f.product(t, existing.ID); got.Name != "Lamp" {
+		t.Errorf("product renamed to %q before review", got.Name)
+	}
+
+	// The repeated SKU fails as a duplicate before its status is looked at.
+	if msg := rowError(report.Rows[1], "SKU"); msg != "The SKU is duplicated in row 2." {
+		t.Errorf("row 3 error = %v", report.Rows[1].Errors)
+	}
+
+	statusChange := importCSV(t, svc, "sku,name,description,price,quantity,status\nLAMP-1,Desk lamp,Brass,15,4,draft\n", models.ImportOptions{Upsert: true})
+	if msg := rowError(statusChange.Rows[0], "Status"); msg != "The Status cannot be changed by an import in review mode." {
+		t.Errorf("status change error = %v", statusChange.Rows[0].Errors)
+	}
+}
+
+func TestImportExportedFile(t *testing.T) {
+	f, svc := newImportFixture(t, false)
+
+	exported := &streamingProductRepository{products: []models.Product{
+		{SKU: "-LAMP", Name: "=Desk lamp", Description: "@home", Price: 12.5, Quantity: 3, Status: models.StatusActive},
+	}}
+	var buf strings.Builder
+	if err := NewProductExportService(exported).Export(context.Background(), &buf, ExportFormatCSV, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	report := importCSV(t, svc, buf.String(), models.ImportOptions{})
+	if report.Created != 1 {
+		t.Fatalf("report = %+v, want the exported product created", report)
+	}
+
+	got := f.product(t, report.Rows[0].ProductID)
+	if got.SKU != "-LAMP" || got.Name != "=Desk lamp" || got.Description != "@home" {
+		t.Errorf("imported %q / %q / %q, want the exported text without escaping", got.SKU, got.Name, got.Description)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+type ProductRevisionService interface {
+	GetByProductID(ctx context.Context, productID uint, limit, offset int) ([]models.ProductRevision, int64, error)
+	Diff(ctx context.Context, productID, fromID, toID uint) ([]models.FieldChange, error)
+	Rollback(ctx context.Context, productID, revisionID uint, actor string) (*models.Product, *models.ProductChangeRequest, error)
+	GetLatestByProductIDs(ctx context.Context, productIDs []uint, limit int) (map[uint][]models.ProductRevision, error)
+}
+
+type productRevisionService struct {
+	repo           repository.ProductRevisionRepository
+	product        ProductService
+	changeRequests ProductChangeRequestService
+	reviewMode     bool
+}
+
+// NewProductRevisionService builds the revision service. With reviewMode
+// enabled, rollbacks are submitted as change requests instead of applied.
+func NewProductRevisionService(repo repository.ProductRevisionRepository, product ProductService, changeRequests ProductChangeRequestService, reviewMode bool) ProductRevisionService {
+	return &productRevisionService{repo, product, changeRequests, reviewMode}
+}
+
+func (s *productRevisionService) GetByProductID(ctx context.Context, productID uint, limit, offset int) ([]models.ProductRevision, int64, error) {
+	if _, err := s.product.GetByID(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetByProductID(ctx, productID, limit, offset)
+}
+
+// GetLatestByProductIDs returns up to limit of the newest revisions of each
+// product, keyed by product ID.
+func (s *productRevisionService) GetLatestByProductIDs(ctx context.Context, productIDs []uint, limit int) (map[uint][]models.ProductRevision, error) {
+	revisions, err := s.repo.GetLatestByProductIDs(ctx, productIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := map[uint][]models.ProductRevision{}
+	for _, revision := range revisions {
+		byProduct[revision.ProductID] = append(byProduct[revision.ProductID], revision)
+	}
+	return byProduct, nil
+}
+
+// Diff compares two revisions of a product. A toID of 0 compares against the
+// product's current state.
+func (s *productRevisionService) Diff(ctx context.Context, productID, fromID, toID uint) ([]models.FieldChange, error) {
+	from, err := s.repo.GetByID(ctx, productID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *models.ProductRevision
+	if toID == 0 {
+		product, err := s.product.GetByID(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		to = models.NewProductRevision(product, "")
+	} else {
+		to, err = s.repo.GetByID(ctx, productID, toID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return from.Diff(to), nil
+}
+
+// Rollback restores the content and publish window of a revision as a new
+// update, so the state being replaced is itself kept as a revision. Status,
+// image and SKU are left untouched: status has its own endpoint, replaced
+// images are deleted and revisions recorded before SKUs were tracked have
+// none.
+// In review mode nothing is applied; the restored content is submitted as a
+// change request, which is returned instead of the product.
+func (s *productRevisionService) Rollback(ctx context.Context, productID, revisionID uint, actor string) (*models.Product, *models.ProductChangeRequest, error) {
+	revision, err := s.repo.GetByID(ctx, productID, revisionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	product, err := s.product.GetByIDForUpdate(ctx, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	product.Name = revision.Name
+	product.Description = revision.Description
+	product.Price = revision.Price
+	product.Quantity = revision.Quantity
+	product.PublishAt = revision.PublishAt
+	product.UnpublishAt = revision.UnpublishAt
+
+	if s.reviewMode {
+		changeRequest := models.NewProductChangeRequest(product, actor)
+		if err := s.changeRequests.Submit(ctx, changeRequest); err != nil {
+			return nil, nil, err
+		}
+		return nil, changeRequest, nil
+	}
+
+	if err := s.product.Update(ctx, productID, product, actor); err != nil {
+		return nil, nil, err
+	}
+
+	return product, nil, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/testdb"
+)
+
+func TestRollbackRestoresRevision(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	repo := repository.NewProductRepository(db)
+	revisions := repository.NewProductRevisionRepository(db)
+	products := NewProductService(repo, &recordingAuditLog{})
+
+	tests := []struct {
+		name       string
+		reviewMode bool
+	}{
+		{"applied", false},
+		{"submitted for review", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changeRequests := NewProductChangeRequestService(repository.NewProductChangeRequestRepository(db), products, &recordingAuditLog{})
+			svc := NewProductRevisionService(revisions, products, changeRequests, tt.reviewMode)
+
+			unpublishAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+			product := &models.Product{Name: "Lamp", Description: "Brass", UnpublishAt: &unpublishAt}
+			createProduct(t, products, product)
+
+			edited, err := repo.GetByIDForUpdate(ctx, product.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			edited.Name = "Desk lamp"
+			edited.Quantity = 9
+			edited.UnpublishAt = nil
+			if err := products.Update(ctx, edited.ID, edited, "editor@example.com"); err != nil {
+				t.Fatal(err)
+			}
+
+			history, _, err := revisions.GetByProductID(ctx, product.ID, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			restored, changeRequest, err := svc.Rollback(ctx, product.ID, history[0].ID, "editor@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := repo.GetByID(ctx, product.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.reviewMode {
+				if restored != nil || changeRequest == nil {
+					t.Fatalf("review mode returned product %v and request %v, want only a request", restored, changeRequest)
+				}
+				if changeRequest.Name != "Lamp" || changeRequest.UnpublishAt == nil || !changeRequest.UnpublishAt.Equal(unpublishAt) {
+					t.Errorf("change request = %+v, want the revision's content and schedule", changeRequest)
+				}
+				if got.Name != "Desk lamp" {
+					t.Errorf("product renamed to %q before review", got.Name)
+				}
+				return
+			}
+
+			if got.Name != "Lamp" || got.Quantity != 1 || got.UnpublishAt == nil || !got.UnpublishAt.Equal(unpublishAt) {
+				t.Errorf("restored product = %+v, want the revision's content and schedule", got)
+			}
+
+			history, total, err := revisions.GetByProductID(ctx, product.ID, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 2 || history[0].Name != "Desk lamp" {
+				t.Errorf("history after rollback = %+v, want the replaced state kept as a revision", history)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/testdb"
+)
+
+func newScheduledProductService(t *testing.T) (ProductService, repository.ProductRepository) {
+	repo := repository.NewProductRepository(testdb.Open(t))
+	return NewProductService(repo, &recordingAuditLog{}), repo
+}
+
+func createProduct(t *testing.T, svc ProductService, product *models.Product) {
+	t.Helper()
+	product.Price = 10
+	product.Quantity = 1
+	if product.Name == "" {
+		product.Name = "Lamp"
+	}
+	if err := svc.Create(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyPublishSchedule(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newScheduledProductService(t)
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	due := &models.Product{Name: "Due draft", Status: models.StatusDraft, PublishAt: &past}
+	later := &models.Product{Name: "Later draft", Status: models.StatusDraft, PublishAt: &future}
+	expiring := &models.Product{Name: "Expiring", Status: models.StatusActive, UnpublishAt: &past}
+	for _, p := range []*models.Product{due, later, expiring} {
+		createProduct(t, svc, p)
+	}
+
+	published, unpublished, err := svc.ApplyPublishSchedule(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 1 || unpublished != 1 {
+		t.Errorf("published %d and unpublished %d, want 1 and 1", published, unpublished)
+	}
+
+	tests := []struct {
+		product   *models.Product
+		status    models.ProductStatus
+		scheduled bool
+	}{
+		{due, models.StatusActive, false},
+		{later, models.StatusDraft, true},
+		{expiring, models.StatusInactive, false},
+	}
+	for _, tt := range tests {
+		got, err := repo.GetByID(ctx, tt.product.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != tt.status {
+			t.Errorf("%s is %s, want %s", got.Name, got.Status, tt.status)
+		}
+		if scheduled := got.PublishAt != nil || got.UnpublishAt != nil; scheduled != tt.scheduled {
+			t.Errorf("%s still scheduled = %v, want %v", got.Name, scheduled, tt.scheduled)
+		}
+	}
+
+	published, unpublished, err = svc.ApplyPublishSchedule(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 0 || unpublished != 0 {
+		t.Errorf("second run published %d and unpublished %d, want nothing", published, unpublished)
+	}
+}
+
+func TestDeactivatedProductStaysDown(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		product models.Product
+	}{
+		// Created active with a publish time the scheduler never applied.
+		{"created active", models.Product{Status: models.StatusActive, PublishAt: &past}},
+		// Activated by hand once its publish time had passed.
+		{"activated by hand", models.Product{Status: models.StatusDraft, PublishAt: &past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newScheduledProductService(t)
+			product := tt.product
+			createProduct(t, svc, &product)
+
+			if product.Status != models.StatusActive {
+				if _, _, err := svc.TransitionStatus(ctx, product.ID, models.StatusActive, "admin"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, _, err := svc.TransitionStatus(ctx, product.ID, models.StatusInactive, "admin"); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := repo.GetByID(ctx, product.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.IsVisible(now) {
+				t.Error("deactivated product is still visible")
+			}
+
+			visible, _, err := repo.GetByStatusActive(ctx, 10, 0, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(visible) != 0 {
+				t.Errorf("storefront lists %d products, want none", len(visible))
+			}
+
+			published, _, err := svc.ApplyPublishSchedule(ctx, now.Add(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if published != 0 {
+				t.Errorf("scheduler re-activated %d products", published)
+			}
+		})
+	}
+}
@@ -2,28 +2,49 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"product-service/internal/audit"
 	"product-service/internal/models"
 	"product-service/internal/repository"
+	"time"
 )
 
 type ProductService interface {
-	GetAll(ctx context.Context, limit, offset int, search string, status *int) ([]models.Product, int64, error)
+	GetAll(ctx context.Context, limit, offset int, search string, status *models.ProductStatus) ([]models.Product, int64, error)
 	GetByID(ctx context.Context, id uint) (*models.Product, error)
+	// GetByIDForUpdate reads from the primary; use it to load a product that
+	// is about to be changed and saved.
+	GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Product, error)
 	GetByStatusActive(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error)
 	Create(ctx context.Context, product *models.Product) error
-	Update(ctx context.Context, id uint, product *models.Product) error
+	Update(ctx context.Context, id uint, product *models.Product, actor string) error
+	TransitionStatus(ctx context.Context, id uint, target models.ProductStatus, actor string) (*models.Product, models.ProductStatus, error)
+	ApplyPublishSchedule(ctx context.Context, now time.Time) (published, unpublished int, err error)
+	GetWithLocalImages(ctx context.Context, ids []uint) ([]models.Product, error)
+	ReplaceImageURL(ctx context.Context, id uint, from, to, actor string) error
+	BulkUpdate(ctx context.Context, ids []uint, filter *models.BulkProductFilter, patch *models.BulkProductPatch, actor string) (*models.BulkUpdateReport, error)
+	ReserveStock(ctx context.Context, items []models.StockReservation, actor string) ([]models.Product, error)
 	Delete(ctx context.Context, id uint) error
+	GetTrashed(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error)
+	Restore(ctx context.Context, id uint) (*models.Product, error)
+	Purge(ctx context.Context, id uint) (*models.Product, error)
+	PurgeExpired(ctx context.Context, retention time.Duration) ([]models.Product, error)
 }
 
 type productService struct {
-	repo repository.ProductRepository
+	repo     repository.ProductRepository
+	auditLog audit.Logger
 }
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{repo}
+// NewProductService builds the product service. Every successful mutation is
+// reported to auditLog, attributed to the actor carried by its context.
+func NewProductService(repo repository.ProductRepository, auditLog audit.Logger) ProductService {
+	return &productService{repo, auditLog}
 }
 
-func (s *productService) GetAll(ctx context.Context, limit, offset int, search string, status *int) ([]models.Product, int64, error) {
+func (s *productService) GetAll(ctx context.Context, limit, offset int, search string, status *models.ProductStatus) ([]models.Product, int64, error) {
 	return s.repo.GetAll(ctx, limit, offset, search, status)
 }
 
@@ -31,19 +52,249 @@ func (s *productService) GetByID(ctx context.Context, id uint) (*models.Product,
 	return s.repo.GetByID(ctx, id)
 }
 
+func (s *productService) GetByIDForUpdate(ctx context.Context, id uint) (*models.Product, error) {
+	return s.repo.GetByIDForUpdate(ctx, id)
+}
+
+func (s *productService) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	return s.repo.GetBySKU(ctx, sku)
+}
+
+func (s *productService) GetByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	return s.repo.GetByIDs(ctx, ids)
+}
+
 func (s *productService) GetByStatusActive(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error) {
 	return s.repo.GetByStatusActive(ctx, limit, offset, search)
 }
 
 func (s *productService) Create(ctx context.Context, product *models.Product) error {
-	return s.repo.Create(ctx, product)
+	if product.SKU != "" {
+		if existing, err := s.repo.GetBySKU(ctx, product.SKU); err == nil {
+			if existing.DeletedAt.Valid {
+				return fmt.Errorf("product sku belongs to a trashed product")
+			}
+			return fmt.Errorf("product sku already exists")
+		}
+	}
+	if err := s.repo.Create(ctx, product); err != nil {
+		return err
+	}
+
+	s.auditLog.Log(ctx, audit.Event{
+		Event: audit.EventProductCreated,
+		Details: map[string]interface{}{
+			"product_id": product.ID,
+			"sku":        product.SKU,
+			"name":       product.Name,
+			"status":     product.Status,
+		},
+	})
+	return nil
 }
 
-func (s *productService) Update(ctx context.Context, id uint, product *models.Product) error {
+// Update saves product and records its previous state as a revision
+// attributed to actor.
+func (s *productService) Update(ctx context.Context, id uint, product *models.Product, actor string) error {
 	product.ID = id
-	return s.repo.Update(ctx, product)
+	changes, err := s.repo.UpdateWithRevision(ctx, product, actor)
+	if err != nil {
+		return err
+	}
+
+	auditProductChanges(ctx, s.auditLog, id, changes)
+	return nil
+}
+
+// TransitionStatus moves a product to target if the lifecycle allows it and
+// returns the product along with the status it had before. Requesting the
+// current state is a no-op.
+func (s *productService) TransitionStatus(ctx context.Context, id uint, target models.ProductStatus, actor string) (*models.Product, models.ProductStatus, error) {
+	product, err := s.repo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	previous := product.Status
+	if !previous.CanTransitionTo(target) {
+		return nil, previous, &models.InvalidTransitionError{From: previous, To: target}
+	}
+
+	if previous == target {
+		return product, previous, nil
+	}
+
+	product.Status = target
+	if err := s.Update(ctx, id, product, actor); err != nil {
+		return nil, previous, err
+	}
+
+	return product, previous, nil
+}
+
+// ApplyPublishSchedule activates draft and inactive products whose publish
+// time has passed and deactivates active products whose unpublish time has
+// passed. The applied timestamp is cleared so later manual transitions stick.
+// A product changed while the run was under way is left to the next run.
+func (s *productService) ApplyPublishSchedule(ctx context.Context, now time.Time) (published, unpublished int, err error) {
+	due, err := s.repo.GetDueForPublish(ctx, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range due {
+		product := &due[i]
+		product.Status = models.StatusActive
+		product.PublishAt = nil
+		if err := s.Update(ctx, product.ID, product, "scheduler"); err != nil {
+			if err.Error() == "product changed concurrently" {
+				continue
+			}
+			return published, unpublished, err
+		}
+		published++
+	}
+
+	due, err = s.repo.GetDueForUnpublish(ctx, now)
+	if err != nil {
+		return published, unpublished, err
+	}
+
+	for i := range due {
+		product := &due[i]
+		product.Status = models.StatusInactive
+		product.UnpublishAt = nil
+		if err := s.Update(ctx, product.ID, product, "scheduler"); err != nil {
+			if err.Error() == "product changed concurrently" {
+				continue
+			}
+			return published, unpublished, err
+		}
+		unpublished++
+	}
+
+	return published, unpublished, nil
+}
+
+func (s *productService) GetWithLocalImages(ctx context.Context, ids []uint) ([]models.Product, error) {
+	return s.repo.GetWithLocalImages(ctx, ids)
+}
+
+func (s *productService) ReplaceImageURL(ctx context.Context, id uint, from, to, actor string) error {
+	if err := s.repo.ReplaceImageURL(ctx, id, from, to, actor); err != nil {
+		return err
+	}
+
+	auditProductChanges(ctx, s.auditLog, id, []models.FieldChange{{Field: "image_url", From: from, To: to}})
+	return nil
+}
+
+func (s *productService) BulkUpdate(ctx context.Context, ids []uint, filter *models.BulkProductFilter, patch *models.BulkProductPatch, actor string) (*models.BulkUpdateReport, error) {
+	items, err := s.repo.BulkUpdate(ctx, ids, filter, patch, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BulkUpdateReport{Total: len(items), Items: items}
+	for _, item := range items {
+		switch item.Outcome {
+		case models.BulkOutcomeUpdated:
+			report.Updated++
+			auditProductChanges(ctx, s.auditLog, item.ID, item.Changes)
+		case models.BulkOutcomeUnchanged:
+			report.Unchanged++
+		case models.BulkOutcomeNotFound:
+			report.NotFound++
+		default:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+func (s *productService) ReserveStock(ctx context.Context, items []models.StockReservation, actor string) ([]models.Product, error) {
+	products, err := s.repo.ReserveStock(ctx, items, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := map[uint]int{}
+	for _, item := range items {
+		reserved[item.ProductID] += item.Quantity
+	}
+	for _, product := range products {
+		auditProductChanges(ctx, s.auditLog, product.ID, []models.FieldChange{
+			{Field: "quantity", From: product.Quantity + reserved[product.ID], To: product.Quantity},
+		})
+	}
+
+	return products, nil
 }
 
 func (s *productService) Delete(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.auditLog.Log(ctx, audit.Event{
+		Event:   audit.EventProductDeleted,
+		Details: map[string]interface{}{"product_id": id},
+	})
+	return nil
+}
+
+func (s *productService) GetTrashed(ctx context.Context, limit, offset int, search string) ([]models.Product, int64, error) {
+	return s.repo.GetTrashed(ctx, limit, offset, search)
+}
+
+func (s *productService) Restore(ctx context.Context, id uint) (*models.Product, error) {
+	product, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditLog.Log(ctx, audit.Event{
+		Event:   audit.EventProductRestored,
+		Details: map[string]interface{}{"product_id": product.ID},
+	})
+	return product, nil
+}
+
+func (s *productService) Purge(ctx context.Context, id uint) (*models.Product, error) {
+	product, err := s.repo.Purge(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditLog.Log(ctx, audit.Event{
+		Event: audit.EventProductPurged,
+		Details: map[string]interface{}{
+			"product_id": product.ID,
+			"sku":        product.SKU,
+			"name":       product.Name,
+		},
+	})
+	return product, nil
+}
+
+// PurgeExpired permanently removes every product that has been in the trash
+// longer than retention and returns the purged rows so callers can clean up
+// their stored images.
+func (s *productService) PurgeExpired(ctx context.Context, retention time.Duration) ([]models.Product, error) {
+	expired, err := s.repo.GetTrashedBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return nil, err
+	}
+
+	purged := make([]models.Product, 0, len(expired))
+	for _, p := range expired {
+		product, err := s.Purge(ctx, p.ID)
+		if err != nil {
+			return purged, err
+		}
+		purged = append(purged, *product)
+	}
+
+	return purged, nil
 }
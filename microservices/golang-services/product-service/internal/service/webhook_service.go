@@ -0,0 +1,276 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/pkg/helpers"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	webhookMaxAttempts     = 8
+	webhookBaseBackoff     = 30 * time.Second
+	webhookMaxBackoff      = 6 * time.Hour
+	webhookClaimLease      = 2 * time.Minute
+	webhookBatchSize       = 50
+	webhookResponseBodyMax = 1024
+)
+
+type WebhookService interface {
+	GetAll(ctx context.Context, limit, offset int) ([]models.WebhookSubscription, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.WebhookSubscription, error)
+	Create(ctx context.Context, input *models.WebhookSubscriptionInput, actor string) (*models.WebhookSubscription, error)
+	Update(ctx context.Context, id uint, input *models.WebhookSubscriptionInput) (*models.WebhookSubscription, error)
+	Delete(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	Redeliver(ctx context.Context, deliveryID uint) (*models.WebhookDelivery, error)
+	Dispatch(ctx context.Context, event *models.OutboxEvent) (int, error)
+	DeliverDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type webhookService struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+}
+
+// NewWebhookService sends deliveries with client, which should carry a
+// timeout so a slow receiver cannot stall the delivery worker.
+func NewWebhookService(repo repository.WebhookRepository, client *http.Client) WebhookService {
+	return &webhookService{repo, client}
+}
+
+func (s *webhookService) GetAll(ctx context.Context, limit, offset int) ([]models.WebhookSubscription, int64, error) {
+	return s.repo.GetAll(ctx, limit, offset)
+}
+
+func (s *webhookService) GetByID(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create registers a subscription, generating a secret when none is given.
+func (s *webhookService) Create(ctx context.Context, input *models.WebhookSubscriptionInput, actor string) (*models.WebhookSubscription, error) {
+	secret := input.Secret
+	if secret == "" {
+		generated, err := helpers.GenerateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:       input.URL,
+		Secret:    secret,
+		Events:    input.Events,
+		Active:    input.Active == nil || *input.Active,
+		CreatedBy: actor,
+	}
+
+	if err := s.repo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// Update replaces the subscription settings. An empty secret keeps the
+// current one.
+func (s *webhookService) Update(ctx context.Context, id uint, input *models.WebhookSubscriptionInput) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.URL = input.URL
+	subscription.Events = input.Events
+	if input.Secret != "" {
+		subscription.Secret = input.Secret
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+
+	if err := s.repo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, id uint) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.repo.GetByID(ctx, subscriptionID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetDeliveries(ctx, subscriptionID, limit, offset)
+}
+
+// Redeliver queues a fresh copy of a delivery for immediate sending. The
+// original stays in the log untouched.
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID uint) (*models.WebhookDelivery, error) {
+	original, err := s.repo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		RedeliveryOf:   &original.ID,
+		NextAttemptAt:  &now,
+	}
+
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Dispatch fans event out to every active subscription whose filter matches
+// it and returns how many subscriptions matched.
+func (s *webhookService) Dispatch(ctx context.Context, event *models.OutboxEvent) (int, error) {
+	subscriptions, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deliveries := []*models.WebhookDelivery{}
+	for i := range subscriptions {
+		if subscriptions[i].Events.Matches(event.EventType) {
+			deliveries = append(deliveries, models.NewWebhookDelivery(&subscriptions[i], event))
+		}
+	}
+
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return 0, err
+	}
+
+	return len(deliveries), nil
+}
+
+// DeliverDue sends every pending delivery whose next attempt is due and
+// returns how many were attempted.
+func (s *webhookService) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, now, webhookClaimLease, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		if err := s.deliver(ctx, &deliveries[i]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+type webhookEnvelope struct {
+	ID         uint64          `json:"id"`
+	DeliveryID uint            `json:"delivery_id"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// deliver posts one delivery to its subscription and records the outcome.
+// Any 2xx response counts as success; everything else is retried with
+// exponential backoff until the attempts run out.
+func (s *webhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.Attempts++
+	delivery.ResponseCode = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	if delivery.Subscription == nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = "subscription not found"
+		delivery.NextAttemptAt = nil
+		return s.repo.SaveDelivery(ctx, delivery)
+	}
+
+	code, body, err := s.send(ctx, delivery)
+	delivery.ResponseCode = code
+	delivery.ResponseBody = body
+
+	now := time.Now()
+	switch {
+	case err == nil && code >= 200 && code < 300:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(webhookBackoff(delivery.Attempts))
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = &next
+	}
+
+	if err != nil {
+		delivery.Error = err.Error()
+	} else if delivery.Status != models.WebhookDeliverySucceeded {
+		delivery.Error = "unexpected response status " + strconv.Itoa(code)
+	}
+
+	return s.repo.SaveDelivery(ctx, delivery)
+}
+
+func (s *webhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, string, error) {
+	body, err := json.Marshal(webhookEnvelope{
+		ID:         delivery.EventID,
+		DeliveryID: delivery.ID,
+		Type:       delivery.EventType,
+		Data:       json.RawMessage(delivery.Payload),
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "product-service-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", helpers.SignWebhookPayload(delivery.Subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyMax))
+	// Response bodies land in a text column, which rejects NUL bytes and
+	// invalid UTF-8.
+	return resp.StatusCode, strings.ToValidUTF8(strings.ReplaceAll(string(respBody), "\x00", ""), ""), nil
+}
+
+func webhookBackoff(attempt int) time.Duration {
+	backoff := webhookBaseBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
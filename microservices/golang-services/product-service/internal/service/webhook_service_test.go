@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/pkg/helpers"
+
+	"gorm.io/gorm"
+)
+
+// memoryWebhookRepository keeps subscriptions and deliveries in memory and
+// claims deliveries the way the PostgreSQL repository does.
+type memoryWebhookRepository struct {
+	repository.WebhookRepository
+
+	mu            sync.Mutex
+	subscriptions map[uint]*models.WebhookSubscription
+	deliveries    map[uint]*models.WebhookDelivery
+	nextID        uint
+}
+
+func newMemoryWebhookRepository(subscriptions ...*models.WebhookSubscription) *memoryWebhookRepository {
+	r := &memoryWebhookRepository{
+		subscriptions: map[uint]*models.WebhookSubscription{},
+		deliveries:    map[uint]*models.WebhookDelivery{},
+	}
+	for _, s := range subscriptions {
+		r.subscriptions[s.ID] = s
+	}
+	return r
+}
+
+func (r *memoryWebhookRepository) GetDeliveryByID(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+func (r *memoryWebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	delivery.ID = r.nextID
+	copied := *delivery
+	r.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (r *memoryWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []models.WebhookDelivery
+	for id := uint(1); id <= r.nextID && len(claimed) < limit; id++ {
+		delivery, ok := r.deliveries[id]
+		if !ok || delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		leased := now.Add(lease)
+		delivery.NextAttemptAt = &leased
+
+		copied := *delivery
+		copied.Subscription = r.subscriptions[delivery.SubscriptionID]
+		claimed = append(claimed, copied)
+	}
+	return claimed, nil
+}
+
+func (r *memoryWebhookRepository) SaveDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *delivery
+	copied.Subscription = nil
+	r.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+// due makes delivery id's next attempt due now, as if its backoff had passed.
+func (r *memoryWebhookRepository) due(id uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.deliveries[id].NextAttemptAt = &now
+}
+
+// receivedWebhook is one request captured by a test receiver.
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a receiver answering every request with the next of
+// codes, repeating the last one.
+func newReceiver(t *testing.T, codes ...int) (*httptest.Server, func() []receivedWebhook) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var received []receivedWebhook
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		received = append(received, receivedWebhook{header: r.Header.Clone(), body: body})
+		code := codes[min(len(received), len(codes))-1]
+		mu.Unlock()
+
+		w.WriteHeader(code)
+		io.WriteString(w, http.StatusText(code))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedWebhook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedWebhook(nil), received...)
+	}
+}
+
+func queueDelivery(t *testing.T, repo *memoryWebhookRepository, subscription *models.WebhookSubscription) *models.WebhookDelivery {
+	t.Helper()
+	delivery := models.NewWebhookDelivery(subscription, &models.OutboxEvent{
+		ID:        42,
+		EventType: models.EventProductUpdated,
+		Payload:   `{"product_id":7}`,
+	})
+	if err := repo.CreateDelivery(context.Background(), delivery); err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestDeliverDueSignsPayload(t *testing.T) {
+	server, received := newReceiver(t, http.StatusNoContent)
+	subscription := &models.WebhookSubscription{ID: 1, URL: server.URL, Secret: "0123456789abcdef", Active: true}
+	repo := newMemoryWebhookRepository(subscription)
+	delivery := queueDelivery(t, repo, subscription)
+
+	svc := NewWebhookService(repo, server.Client())
+	sent, err := svc.DeliverDue(context.Background(), time.Now())
+	if err != nil || sent != 1 {
+		t.Fatalf("DeliverDue() = %d, %v; want 1, nil", sent, err)
+	}
+
+	requests := received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+
+	timestamp, err := strconv.ParseInt(req.header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("X-Webhook-Timestamp = %q: %v", req.header.Get("X-Webhook-Timestamp"), err)
+	}
+	if want := helpers.SignWebhookPayload(subscription.Secret, timestamp, req.body); req.header.Get("X-Webhook-Signature") != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", req.header.Get("X-Webhook-Signature"), want)
+	}
+	if got := req.header.Get("X-Webhook-Event"); got != models.EventProductUpdated {
+		t.Errorf("X-Webhook-Event = %q, want %q", got, models.EventProductUpdated)
+	}
+	if got := req.header.Get("X-Webhook-Delivery"); got != strconv.FormatUint(uint64(delivery.ID), 10) {
+		t.Errorf("X-Webhook-Delivery = %q, want %d", got, delivery.ID)
+	}
+
+	var envelope webhookEnvelope
+	if err := json.Unmarshal(req.body, &envelope); err != nil {
+		t.Fatalf("body is not an envelope: %v", err)
+	}
+	if envelope.ID != 42 || envelope.DeliveryID != delivery.ID || string(envelope.Data) != `{"product_id":7}` {
+		t.Errorf("envelope = %+v", envelope)
+	}
+
+	stored, _ := repo.GetDeliveryByID(context.Background(), delivery.ID)
+	if stored.Status != models.WebhookDeliverySucceeded || stored.ResponseCode != http.StatusNoContent {
+		t.Errorf("delivery status = %s, response code = %d; want succeeded, 204", stored.Status, stored.ResponseCode)
+	}
+	if stored.DeliveredAt == nil || stored.NextAttemptAt != nil {
+		t.Errorf("delivered_at = %v, next_attempt_at = %v; want set, nil", stored.DeliveredAt, stored.NextAttemptAt)
+	}
+}
+
+func TestDeliverDueRetriesServerErrorsWithBackoff(t *testing.T) {
+	server, received := newReceiver(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	subscription := &models.WebhookSubscription{ID: 1, URL: server.URL, Secret: "0123456789abcdef", Active: true}
+	repo := newMemoryWebhookRepository(subscription)
+	delivery := queueDelivery(t, repo, subscription)
+	svc := NewWebhookService(repo, server.Client())
+	ctx := context.Background()
+
+	for attempt, code := range []int{http.StatusServiceUnavailable, http.StatusBadGateway} {
+		before := time.Now()
+		if _, err := svc.DeliverDue(ctx, before); err != nil {
+			t.Fatal(err)
+		}
+
+		stored, _ := repo.GetDeliveryByID(ctx, delivery.ID)
+		if stored.Status != models.WebhookDeliveryPending || stored.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d: status = %s, attempts = %d", attempt+1, stored.Status, stored.Attempts)
+		}
+		if stored.ResponseCode != code || stored.ResponseBody != http.StatusText(code) {
+			t.Errorf("after attempt %d: response = %d %q, want %d %q", attempt+1, stored.ResponseCode, stored.ResponseBody, code, http.StatusText(code))
+		}
+		if want := "unexpected response status " + strconv.Itoa(code); stored.Error != want {
+			t.Errorf("after attempt %d: error = %q, want %q", attempt+1, stored.Error, want)
+		}
+
+		backoff := webhookBaseBackoff << attempt
+		if stored.NextAttemptAt == nil || stored.NextAttemptAt.Before(before.Add(backoff)) || stored.NextAttemptAt.After(time.Now().Add(backoff)) {
+			t.Errorf("after attempt %d: next attempt at %v, want %v from now", attempt+1, stored.NextAttemptAt, backoff)
+		}
+
+		// Nothing is sent again until the backoff has passed.
+		if sent, _ := svc.DeliverDue(ctx, time.Now()); sent != 0 {
+			t.Fatalf("after attempt %d: DeliverDue sent %d before the backoff passed", attempt+1, sent)
+		}
+		repo.due(delivery.ID)
+	}
+
+	if _, err := svc.DeliverDue(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := repo.GetDeliveryByID(ctx, delivery.ID)
+	if stored.Status != models.WebhookDeliverySucceeded || stored.Attempts != 3 || stored.ResponseCode != http.StatusOK || stored.Error != "" {
+		t.Errorf("final delivery = %s after %d attempts, response %d, error %q", stored.Status, stored.Attempts, stored.ResponseCode, stored.Error)
+	}
+	if got := len(received()); got != 3 {
+		t.Errorf("receiver got %d requests, want 3", got)
+	}
+}
+
+func TestDeliverDueGivesUpAfterMaxAttempts(t *testing.T) {
+	server, _ := newReceiver(t, http.StatusInternalServerError)
+	subscription := &models.WebhookSubscription{ID: 1, URL: server.URL, Secret: "0123456789abcdef", Active: true}
+	repo := newMemoryWebhookRepository(subscription)
+	delivery := queueDelivery(t, repo, subscription)
+	svc := NewWebhookService(repo, server.Client())
+	ctx := context.Background()
+
+	for i := 0; i < webhookMaxAttempts; i++ {
+		repo.due(delivery.ID)
+		if _, err := svc.DeliverDue(ctx, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stored, _ := repo.GetDeliveryByID(ctx, delivery.ID)
+	if stored.Status != models.WebhookDeliveryFailed || stored.Attempts != webhookMaxAttempts || stored.NextAttemptAt != nil {
+		t.Errorf("delivery = %s after %d attempts, next attempt %v; want failed after %d, nil", stored.Status, stored.Attempts, stored.NextAttemptAt, webhookMaxAttempts)
+	}
+	if stored.ResponseCode != http.StatusInternalServerError {
+		t.Errorf("response code = %d, want 500", stored.ResponseCode)
+	}
+}
+
+func TestRedeliverSendsCopyAndKeepsOriginal(t *testing.T) {
+	server, received := newReceiver(t, http.StatusInternalServerError, http.StatusOK)
+	subscription := &models.WebhookSubscription{ID: 1, URL: server.URL, Secret: "0123456789abcdef", Active: true}
+	repo := newMemoryWebhookRepository(subscription)
+	original := queueDelivery(t, repo, subscription)
+	svc := NewWebhookService(repo, server.Client())
+	ctx := context.Background()
+
+	if _, err := svc.DeliverDue(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	failed, _ := repo.GetDeliveryByID(ctx, original.ID)
+
+	redelivery, err := svc.Redeliver(ctx, original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivery.ID == original.ID || redelivery.RedeliveryOf == nil || *redelivery.RedeliveryOf != original.ID {
+		t.Fatalf("redelivery id = %d, redelivery_of = %v; want a new delivery of %d", redelivery.ID, redelivery.RedeliveryOf, original.ID)
+	}
+	if redelivery.Status != models.WebhookDeliveryPending || redelivery.Attempts != 0 || redelivery.EventID != original.EventID || redelivery.Payload != original.Payload {
+		t.Errorf("redelivery = %+v", redelivery)
+	}
+
+	// Only the copy is due; the original still waits for its backoff.
+	if sent, err := svc.DeliverDue(ctx, time.Now()); err != nil || sent != 1 {
+		t.Fatalf("DeliverDue() = %d, %v; want 1, nil", sent, err)
+	}
+
+	requests := received()
+	if len(requests) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(requests))
+	}
+	if got := requests[1].header.Get("X-Webhook-Delivery"); got != strconv.FormatUint(uint64(redelivery.ID), 10) {
+		t.Errorf("X-Webhook-Delivery = %q, want %d", got, redelivery.ID)
+	}
+
+	redelivered, _ := repo.GetDeliveryByID(ctx, redelivery.ID)
+	if redelivered.Status != models.WebhookDeliverySucceeded || redelivered.ResponseCode != http.StatusOK {
+		t.Errorf("redelivery = %s with response %d, want succeeded with 200", redelivered.Status, redelivered.ResponseCode)
+	}
+	if unchanged, _ := repo.GetDeliveryByID(ctx, original.ID); unchanged.Attempts != failed.Attempts || unchanged.ResponseCode != failed.ResponseCode {
+		t.Errorf("original changed from %+v to %+v", failed, unchanged)
+	}
+}
+
+func TestRedeliverUnknownDelivery(t *testing.T) {
+	svc := NewWebhookService(newMemoryWebhookRepository(), http.DefaultClient)
+	if _, err := svc.Redeliver(context.Background(), 99); err == nil || err.Error() != "record not found" {
+		t.Errorf("Redeliver() error = %v, want record not found", err)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, webhookMaxBackoff},
+		{80, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempt); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package testdb opens an in-memory SQLite database with the product schema,
+// standing in for PostgreSQL in tests of the repositories and the code built
+// on them. Only tests import it, so the driver never reaches the binary.
+//
+// SQLite ignores FOR UPDATE and has no ILIKE, so row locking and searches
+// are out of reach of these tests.
+package testdb
+
+import (
+	"context"
+	"testing"
+
+	"product-service/config"
+	"product-service/internal/migrator"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type db struct {
+	conn *gorm.DB
+}
+
+// Open returns a fresh, migrated database that is closed when t ends. It
+// serves reads and writes alike, as a deployment without replicas does.
+func Open(t testing.TB) config.GormPostgres {
+	t.Helper()
+
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to :memory: opens a database of its own.
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := conn.AutoMigrate(migrator.Models...); err != nil {
+		t.Fatal(err)
+	}
+
+	return &db{conn: conn}
+}
+
+func (d *db) GetConnection() *gorm.DB {
+	return d.conn
+}
+
+func (d *db) GetReadConnection(ctx context.Context) *gorm.DB {
+	return d.conn
+}
+
+func (d *db) Use(plugin gorm.Plugin) error {
+	return d.conn.Use(plugin)
+}
+
+func (d *db) MonitorReplicas(ctx context.Context) {}
+
+func (d *db) Close() error {
+	return nil
+}
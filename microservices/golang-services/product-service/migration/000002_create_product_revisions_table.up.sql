@@ -0,0 +1,15 @@
+CREATE TABLE product_revisions (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    price DECIMAL(10,2) NOT NULL,
+    quantity INT NOT NULL DEFAULT 0,
+    status SMALLINT NOT NULL DEFAULT 1,
+    image_url VARCHAR(255),
+    actor VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_revisions_product_id ON product_revisions (product_id);
+CREATE INDEX idx_product_revisions_created_at ON product_revisions (created_at);
@@ -0,0 +1,9 @@
+ALTER TABLE product_revisions ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE product_revisions ALTER COLUMN status TYPE SMALLINT
+    USING (CASE status WHEN 'active' THEN 1 ELSE 0 END);
+ALTER TABLE product_revisions ALTER COLUMN status SET DEFAULT 1;
+
+ALTER TABLE products ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE products ALTER COLUMN status TYPE SMALLINT
+    USING (CASE status WHEN 'active' THEN 1 ELSE 0 END);
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 1;
@@ -0,0 +1,9 @@
+ALTER TABLE products ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE products ALTER COLUMN status TYPE VARCHAR(20)
+    USING (CASE status WHEN 1 THEN 'active' ELSE 'inactive' END);
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE product_revisions ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE product_revisions ALTER COLUMN status TYPE VARCHAR(20)
+    USING (CASE status WHEN 1 THEN 'active' ELSE 'inactive' END);
+ALTER TABLE product_revisions ALTER COLUMN status SET DEFAULT 'draft';
@@ -0,0 +1,5 @@
+ALTER TABLE products ADD COLUMN publish_at TIMESTAMP;
+ALTER TABLE products ADD COLUMN unpublish_at TIMESTAMP;
+
+CREATE INDEX idx_products_publish_at ON products (publish_at);
+CREATE INDEX idx_products_unpublish_at ON products (unpublish_at);
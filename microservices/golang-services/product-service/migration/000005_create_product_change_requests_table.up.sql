@@ -0,0 +1,22 @@
+CREATE TABLE product_change_requests (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    price DECIMAL(10,2) NOT NULL,
+    quantity INT NOT NULL DEFAULT 0,
+    image_url VARCHAR(255),
+    publish_at TIMESTAMP,
+    unpublish_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by VARCHAR(255),
+    reviewed_by VARCHAR(255),
+    review_comment TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_change_requests_product_id ON product_change_requests (product_id);
+CREATE INDEX idx_product_change_requests_status ON product_change_requests (status);
+CREATE INDEX idx_product_change_requests_created_at ON product_change_requests (created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN sku VARCHAR(100);
+
+CREATE UNIQUE INDEX idx_products_sku ON products (sku) WHERE sku IS NOT NULL AND sku <> '';
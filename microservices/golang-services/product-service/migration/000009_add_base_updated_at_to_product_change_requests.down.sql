@@ -0,0 +1 @@
+ALTER TABLE product_change_requests DROP COLUMN IF EXISTS base_updated_at;
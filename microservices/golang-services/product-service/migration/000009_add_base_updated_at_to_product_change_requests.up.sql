@@ -0,0 +1 @@
+ALTER TABLE product_change_requests ADD COLUMN base_updated_at TIMESTAMP;
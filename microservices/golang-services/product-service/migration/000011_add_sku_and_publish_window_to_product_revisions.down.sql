@@ -0,0 +1,3 @@
+ALTER TABLE product_revisions DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE product_revisions DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE product_revisions DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,3 @@
+ALTER TABLE product_revisions ADD COLUMN sku VARCHAR(100);
+ALTER TABLE product_revisions ADD COLUMN publish_at TIMESTAMP;
+ALTER TABLE product_revisions ADD COLUMN unpublish_at TIMESTAMP;
@@ -0,0 +1,9 @@
+// Package migration embeds the SQL migrations so the service binary can
+// apply them without the migrate CLI. Files follow the golang-migrate naming
+// scheme: NNNNNN_name.up.sql and NNNNNN_name.down.sql.
+package migration
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
@@ -2,8 +2,11 @@ package helpers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -13,28 +16,43 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/tracing/smithyoteltracing"
+	"go.opentelemetry.io/otel"
 )
 
+// UploadObserver is told where each upload ended up: "s3" when it was stored
+// in the bucket, "fallback" when it was kept on local disk.
+type UploadObserver func(result string)
+
 type S3Uploader struct {
 	client     *s3.Client
 	bucketName string
 	folder     string
 	region     string
+	logger     *slog.Logger
+	onUpload   UploadObserver
 }
 
-func NewS3Uploader(bucketName, folder string) (*S3Uploader, error) {
+// NewS3Uploader returns an uploader for bucketName. onUpload may be nil when
+// the uploader is only used to delete files.
+func NewS3Uploader(bucketName, folder string, logger *slog.Logger, onUpload UploadObserver) (*S3Uploader, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return nil, err
 	}
 
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.TracerProvider = smithyoteltracing.Adapt(otel.GetTracerProvider())
+	})
 
 	return &S3Uploader{
 		client:     client,
 		bucketName: bucketName,
 		folder:     folder,
 		region:     cfg.Region,
+		logger:     logger,
+		onUpload:   onUpload,
 	}, nil
 }
 
@@ -80,18 +98,89 @@ func (u *S3Uploader) UploadWithFallback(ctx context.Context, fileHeader *multipa
 	})
 
 	if err != nil {
-		fmt.Printf("S3 upload failed, fallback to local file: %v\n", err)
+		u.observe("fallback")
+		u.logger.WarnContext(ctx, "S3 upload failed, falling back to local file", "file", fileName, "error", err)
 		return "/uploads/products/" + fileName, nil
 	}
 
+	u.observe("s3")
 	_ = os.Remove(localFilePath)
 
-	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", u.bucketName, u.region, key)
-	return url, nil
+	return u.objectURL(key), nil
+}
+
+// UploadLocalFile uploads an image UploadWithFallback kept in uploadDir to S3
+// and returns its S3 URL. The local copy is left for the caller to remove
+// once nothing refers to it.
+func (u *S3Uploader) UploadLocalFile(ctx context.Context, fileURL, uploadDir string) (string, error) {
+	fileName := GetFileNameFromURL(fileURL)
+	f, err := os.Open(filepath.Join(uploadDir, fileName))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	key := fmt.Sprintf("%s/%s", u.folder, fileName)
+	_, err = manager.NewUploader(u.client).Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucketName),
+		Key:         aws.String(key),
+		Body:        f,
+		ContentType: aws.String(mime.TypeByExtension(filepath.Ext(fileName))),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	u.observe("s3")
+	return u.objectURL(key), nil
+}
+
+// UploadObject streams body to name under the folder in the bucket and
+// returns the object key. The upload is sent in parts, so body is never held
+// in memory whole.
+func (u *S3Uploader) UploadObject(ctx context.Context, name string, body io.Reader, contentType string) (string, error) {
+	key := fmt.Sprintf("%s/%s", u.folder, name)
+	_, err := manager.NewUploader(u.client).Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucketName),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// OpenObject returns the content of the object stored under key and its
+// size. The caller closes it.
+func (u *S3Uploader) OpenObject(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	out, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, 0, fmt.Errorf("object not found")
+		}
+		return nil, 0, err
+	}
+	return out.Body, aws.ToInt64(out.ContentLength), nil
+}
+
+func (u *S3Uploader) observe(result string) {
+	if u.onUpload != nil {
+		u.onUpload(result)
+	}
+}
+
+func (u *S3Uploader) objectURL(key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", u.bucketName, u.region, key)
 }
 
 func (u *S3Uploader) DeleteFileFromS3(ctx context.Context, fileURL string) error {
-	prefix := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", u.bucketName, u.region)
+	prefix := u.objectURL("")
 	if !strings.HasPrefix(fileURL, prefix) {
 		return fmt.Errorf("file URL does not match S3 bucket format")
 	}
@@ -105,6 +194,22 @@ func (u *S3Uploader) DeleteFileFromS3(ctx context.Context, fileURL string) error
 	return err
 }
 
+func (u *S3Uploader) DeleteStoredFile(ctx context.Context, fileURL, uploadDir string) error {
+	if fileURL == "" {
+		return nil
+	}
+
+	if IsS3URL(fileURL) {
+		return u.DeleteFileFromS3(ctx, fileURL)
+	}
+
+	filePath := filepath.Join(uploadDir, GetFileNameFromURL(fileURL))
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func IsS3URL(url string) bool {
 	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
 }
@@ -1,5 +1,7 @@
 package helpers
 
+import "time"
+
 func Contains(slice []string, item string) bool {
 	for _, v := range slice {
 		if v == item {
@@ -8,3 +10,10 @@ func Contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+func NilIfZeroTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import "strings"
+
+// formulaPrefixes are the leading characters that make Excel, LibreOffice
+// and Google Sheets read a text cell as a formula. Tab and carriage return
+// are included because some of them skip those before looking.
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula prefixes text that a spreadsheet would evaluate with a
+// single quote, so an exported product name like =HYPERLINK(...) shows up
+// as text instead of running when the file is opened. Text that already
+// looks escaped gets a quote too, so UnescapeFormula restores it exactly.
+func EscapeFormula(text string) string {
+	if needsFormulaQuote(text) {
+		return "'" + text
+	}
+	return text
+}
+
+// UnescapeFormula reverses EscapeFormula, so exported files import back
+// unchanged.
+func UnescapeFormula(text string) string {
+	if strings.HasPrefix(text, "'") && needsFormulaQuote(text[1:]) {
+		return text[1:]
+	}
+	return text
+}
+
+// needsFormulaQuote reports whether text, after any leading quotes, starts
+// with a formula character.
+func needsFormulaQuote(text string) bool {
+	text = strings.TrimLeft(text, "'")
+	return text != "" && strings.ContainsRune(formulaPrefixes, rune(text[0]))
+}
@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin/binding"
@@ -13,6 +14,22 @@ func InitValidator() {
 	}
 }
 
+// ValidateStruct runs the binding rules of obj outside of a request, e.g. for
+// rows read from an import file.
+func ValidateStruct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// ValidateStructPartial runs the binding rules of the named fields of obj
+// only, leaving the others unchecked.
+func ValidateStructPartial(obj interface{}, fields ...string) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return binding.Validator.ValidateStruct(obj)
+	}
+	return v.StructPartial(obj, fields...)
+}
+
 func registerCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("not_blank", func(fl validator.FieldLevel) bool {
 		val := fl.Field().String()
@@ -42,9 +59,16 @@ func validationMessage(e validator.FieldError) string {
 	case "email":
 		return "The " + e.Field() + " format is invalid."
 	case "min":
+		if e.Kind() == reflect.Slice {
+			return "The " + e.Field() + " must contain at least " + e.Param() + " item(s)."
+		}
 		return "The " + e.Field() + " must be at least " + e.Param() + " characters."
 	case "max":
 		return "The " + e.Field() + " must be at most " + e.Param() + " characters."
+	case "url":
+		return "The " + e.Field() + " must be a valid URL."
+	case "oneof":
+		return "The " + e.Field() + " must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ") + "."
 	case "gte":
 		return "The " + e.Field() + " must be greater than or equal to " + e.Param() + "."
 	default:
@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string `xml:"r,attr"`
+			T      string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSXPart(t *testing.T, archive *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := archive.Open(name)
+	if err != nil {
+		t.Fatalf("workbook has no %s: %v", name, err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestXLSXStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewXLSXStreamWriter(&buf, "Products & more")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"id", "name", "price"},
+		{uint(7), "Lamp <brass>", 12.5},
+		{uint(8), "=HYPERLINK(\"http://evil\")", 3},
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels"} {
+		readXLSXPart(t, archive, name)
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readXLSXPart(t, archive, "xl/workbook.xml"), &workbook); err != nil {
+		t.Fatal(err)
+	}
+	if len(workbook.Sheets) != 1 || workbook.Sheets[0].Name != "Products & more" {
+		t.Errorf("sheets = %+v, want one named Products & more", workbook.Sheets)
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(readXLSXPart(t, archive, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("sheet has %d rows, want 3", len(sheet.Rows))
+	}
+
+	tests := []struct {
+		row, cell int
+		ref       string
+		inline    bool
+		want      string
+	}{
+		{0, 0, "A1", true, "id"},
+		{1, 0, "A2", false, "7"},
+		{1, 1, "B2", true, "Lamp <brass>"},
+		{1, 2, "C2", false, "12.5"},
+		{2, 1, "B3", true, "'=HYPERLINK(\"http://evil\")"},
+		{2, 2, "C3", false, "3"},
+	}
+	for _, tt := range tests {
+		cell := sheet.Rows[tt.row].Cells[tt.cell]
+		got := cell.Value
+		if tt.inline {
+			got = cell.Inline
+			if cell.T != "inlineStr" {
+				t.Errorf("%s has type %q, want inlineStr", tt.ref, cell.T)
+			}
+		}
+		if cell.R != tt.ref || got != tt.want {
+			t.Errorf("cell %s = %q, want %s = %q", cell.R, got, tt.ref, tt.want)
+		}
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := xlsxColumnName(index); got != want {
+			t.Errorf("xlsxColumnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"Lamp", "Lamp"},
+		{"=1+2", "'=1+2"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"Lamp=1", "Lamp=1"},
+		{"'quoted", "'quoted"},
+		{"'=1", "''=1"},
+	}
+	for _, tt := range tests {
+		got := EscapeFormula(tt.text)
+		if got != tt.want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if back := UnescapeFormula(got); back != tt.text {
+			t.Errorf("UnescapeFormula(%q) = %q, want %q", got, back, tt.text)
+		}
+	}
+}
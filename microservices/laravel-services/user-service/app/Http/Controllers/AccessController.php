@@ -28,6 +28,7 @@ public function getPermissions(): JsonResponse
 
         return response()->json([
             'user' => [
+                'id' => (string) $user->id,
                 'email' => $user->email,
                 'role' => $role,
             ],
@@ -22,6 +22,12 @@ public function run(): void
             'create_products',
             'update_products',
             'delete_products',
+            'purge_products',
+            'approve_products',
+            'export_products',
+            'manage_jobs',
+            'manage_webhooks',
+            'reserve_stock',
         ];
 
         $permissionIds = [];